@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -41,45 +42,58 @@ func main() {
 	useHTTPS := flag.Bool("https", false, "Enable HTTPS (TLS) for development")
 	flag.Parse()
 
-	appConfig := config.LoadConfig()
+	appConfig, err := config.Load()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
 
 	appLogger := logger.New(&appConfig.Logging)
 
+	// A cross-site frontend only keeps the session cookie when it is Secure
+	// with SameSite=None, which plain-HTTP cookie settings would break
+	devHTTPS := *useHTTPS && appConfig.Server.Environment == "dev"
+	if devHTTPS {
+		if appConfig.Cookie.UseHTTPS() {
+			appLogger.Warn("Overriding cookie settings for HTTPS development",
+				"cookie_secure", appConfig.Cookie.Secure,
+				"cookie_samesite", appConfig.Cookie.SameSite,
+			)
+		}
+	} else if appConfig.Server.Environment == "dev" && appConfig.Cookie.Secure {
+		appLogger.Warn("Serving plain HTTP with Secure session cookies; browsers only keep them on localhost. " +
+			"Run with -https, or set COOKIE_SECURE=false and COOKIE_SAMESITE=Lax for a same-site frontend")
+	}
+
 	appLogger.Info("Starting application",
 		"environment", appConfig.Server.Environment,
 		"cookie_secure", appConfig.Cookie.Secure,
 		"cookie_samesite", appConfig.Cookie.SameSite,
 	)
 
-	ctx := context.Background()
-	appContainer, err := container.New(ctx, appConfig, appLogger)
-	if err != nil {
-		appLogger.Error("Failed to initialize application container", "error", err)
-		os.Exit(1)
-	}
-
-	defer func() {
-		if err := appContainer.Close(); err != nil {
-			appLogger.Error("Failed to close application container", "error", err)
-		}
-	}()
-
-	engine := appContainer.Router.Setup(appConfig)
-
+	// Serve health probes while dependencies are being waited for; the
+	// router takes over once the container is ready
+	startup := &startupHandler{}
 	server := &http.Server{
 		Addr:         ":" + appConfig.Server.Port,
-		Handler:      engine,
+		Handler:      startup,
 		ReadTimeout:  time.Duration(appConfig.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(appConfig.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(appConfig.Server.IdleTimeout) * time.Second,
+		ErrorLog:     slog.NewLogLogger(appLogger.Handler(), slog.LevelError),
 	}
 
+	// Validated on load, so building it cannot fail here
+	tlsConfig, _ := appConfig.TLS.ServerConfig()
+	server.TLSConfig = tlsConfig
+
 	go func() {
 		appLogger.Info("Starting HTTP server", "port", appConfig.Server.Port)
-		if *useHTTPS && appConfig.Server.Environment == "dev" {
+		if devHTTPS {
 			appLogger.Info("HTTPS enabled for development",
 				"cert_path", appConfig.TLS.CertPath,
 				"key_path", appConfig.TLS.KeyPath,
+				"min_version", appConfig.TLS.MinVersion,
 			)
 			if err := server.ListenAndServeTLS(appConfig.TLS.CertPath, appConfig.TLS.KeyPath); err != nil && !errors.Is(err, http.ErrServerClosed) {
 				appLogger.Error("HTTPS server error", "error", err)
@@ -94,6 +108,24 @@ func main() {
 		}
 	}()
 
+	// A shutdown signal while waiting for dependencies aborts startup
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	appContainer, err := container.New(ctx, appConfig, appLogger)
+	stop()
+	if err != nil {
+		appLogger.Error("Failed to initialize application container", "error", err)
+		os.Exit(1)
+	}
+
+	defer func() {
+		if err := appContainer.Close(); err != nil {
+			appLogger.Error("Failed to close application container", "error", err)
+		}
+	}()
+
+	startup.ready(appContainer.Router.Setup(appConfig))
+	appLogger.Info("Service ready")
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
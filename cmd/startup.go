@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/pkg/version"
+)
+
+// startupHandler answers requests while the container is still waiting for
+// its dependencies, then hands every request to the router. Liveness stays
+// healthy during the wait so the pod is not restarted, while everything
+// else, readiness included, reports 503 so no traffic is routed to it.
+type startupHandler struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+// ready switches the handler over to the fully initialized router
+func (h *startupHandler) ready(handler http.Handler) {
+	h.handler.Store(&handler)
+}
+
+func (h *startupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if handler := h.handler.Load(); handler != nil {
+		(*handler).ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.URL.Path == "/api/v1/health" {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response.SuccessResponse{
+			Data: response.HealthResponse{
+				Status:  "healthy",
+				Service: "auth-service",
+				Version: version.Get().Version,
+			},
+		})
+		return
+	}
+
+	w.Header().Set("Retry-After", "5")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(response.ErrorResponse{
+		ErrorType: "service_unavailable",
+		Message:   "Service is starting",
+		Details: map[string]interface{}{
+			"status": "not_ready",
+		},
+	})
+}
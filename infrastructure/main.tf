@@ -169,3 +169,26 @@ resource "google_cloud_run_v2_service_iam_member" "public_access" {
   role     = "roles/run.invoker"
   member   = "allUsers"
 }
+
+# ---------------------------------
+# FIRESTORE TTL POLICIES
+# ---------------------------------
+# Short-lived documents carry expire_at; these policies let Firestore delete
+# them once it has passed instead of the collections growing forever.
+resource "google_firestore_field" "user_invalidations_ttl" {
+  project    = local.project_id
+  database   = "(default)"
+  collection = "user_invalidations"
+  field      = "expire_at"
+
+  ttl_config {}
+}
+
+resource "google_firestore_field" "rate_limits_ttl" {
+  project    = local.project_id
+  database   = "(default)"
+  collection = "rate_limits"
+  field      = "expire_at"
+
+  ttl_config {}
+}
@@ -0,0 +1,54 @@
+// Package cookie manages the session cookie shared by handlers, middleware
+// and the main service proxy
+package cookie
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/pkg/config"
+)
+
+// SetSession writes the session cookie so it expires with the session
+func SetSession(c *gin.Context, cfg *config.CookieConfig, sessionID string, expiresAt time.Time) {
+	write(c, cfg, sessionID, int(time.Until(expiresAt).Seconds()))
+}
+
+// ClearSession instructs the browser to delete the session cookie. It
+// carries the same attributes as the cookie it deletes, since browsers keep
+// partitioned and unpartitioned cookies apart.
+func ClearSession(c *gin.Context, cfg *config.CookieConfig) {
+	write(c, cfg, "", -1) // Delete immediately
+}
+
+// write builds the Set-Cookie header itself because gin's SetCookie cannot
+// set the Partitioned attribute
+func write(c *gin.Context, cfg *config.CookieConfig, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:        cfg.Name,
+		Value:       url.QueryEscape(value),
+		MaxAge:      maxAge,
+		Path:        "/",
+		Domain:      cfg.Domain,
+		Secure:      cfg.Secure,   // HTTPS only
+		HttpOnly:    cfg.HTTPOnly, // no script access
+		SameSite:    SameSiteMode(cfg.SameSite),
+		Partitioned: cfg.Partitioned,
+	})
+}
+
+// SameSiteMode converts a configured SameSite value, defaulting to Lax
+func SameSiteMode(mode string) http.SameSite {
+	switch mode {
+	case "Strict":
+		return http.SameSiteStrictMode
+	case "None":
+		return http.SameSiteNoneMode
+	case "Lax":
+		fallthrough
+	default:
+		return http.SameSiteLaxMode
+	}
+}
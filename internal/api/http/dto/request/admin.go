@@ -18,3 +18,38 @@ func (r *ListUsersRequest) ApplyDefaults() {
 func (r *ListUsersRequest) GetAllowedSortFields() []string {
 	return []string{"created_at", "updated_at", "email", "display_name"}
 }
+
+type ListPendingUsersRequest struct {
+	PaginationRequest
+	// Overdue restricts the list to users pending longer than the approval TTL
+	Overdue bool `form:"overdue" example:"true"`
+}
+
+// GetUserByEmailRequest looks up a user by exact, case-insensitive email
+type GetUserByEmailRequest struct {
+	Email string `form:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ApproveUserRequest optionally picks the role an approval assigns
+type ApproveUserRequest struct {
+	// Role defaults to the user's current role, or user when unassigned
+	Role string `json:"role" example:"viewer"`
+}
+
+// UpdateUserRequest holds an admin's edits to a user; omitted fields are
+// left unchanged
+type UpdateUserRequest struct {
+	DisplayName *string `json:"display_name" example:"Jane Doe"`
+	Role        *string `json:"role" example:"viewer"`
+}
+
+// TransferOwnershipRequest names the user who takes over another user's data
+type TransferOwnershipRequest struct {
+	TargetUserID string `json:"target_user_id" binding:"required" example:"user-456"`
+}
+
+// ImportUsersRequest holds the query parameters of a user import
+type ImportUsersRequest struct {
+	// DryRun validates the rows without creating any users
+	DryRun bool `form:"dry_run" example:"true"`
+}
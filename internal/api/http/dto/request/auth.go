@@ -16,3 +16,19 @@ type VerifyTokenRequest struct {
 type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8" example:"NewStrongP@ss123"`
 }
+
+// EmailAvailabilityRequest represents an email availability check
+type EmailAvailabilityRequest struct {
+	Email string `form:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// VerificationEmailRequest represents a request to resend the verification email
+type VerificationEmailRequest struct {
+	ContinueURL string `json:"continue_url" binding:"omitempty,url" example:"https://histopathai.com/welcome"`
+}
+
+// PasswordResetRequest represents a request for a password reset email
+type PasswordResetRequest struct {
+	Email       string `json:"email" binding:"required,email" example:"user@example.com"`
+	ContinueURL string `json:"continue_url" binding:"omitempty,url" example:"https://histopathai.com/login"`
+}
@@ -3,6 +3,9 @@ package request
 // CreateSessionRequest represents session creation request
 type CreateSessionRequest struct {
 	Token string `json:"token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Scope string `json:"scope,omitempty" binding:"omitempty,max=64" example:"image-serve"`
+	// RememberMe keeps a default-scope session alive for longer
+	RememberMe bool `json:"remember_me,omitempty" example:"false"`
 }
 
 // ExtendSessionRequest represents session extension request (optional, can use path param only)
@@ -14,3 +17,14 @@ type ExtendSessionRequest struct {
 type RevokeSessionRequest struct {
 	SessionID string `json:"session_id" binding:"required" example:"abc123def456"`
 }
+
+// UpdateSessionMetadataRequest represents a metadata merge from an internal service
+type UpdateSessionMetadataRequest struct {
+	UserID   string                 `json:"user_id" binding:"required" example:"user-123"`
+	Metadata map[string]interface{} `json:"metadata" binding:"required"`
+}
+
+// ListSessionsRequest pages through a user's sessions, newest first
+type ListSessionsRequest struct {
+	PaginationRequest
+}
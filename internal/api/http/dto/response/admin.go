@@ -13,8 +13,37 @@ type UserDetailResponse struct {
 	FailedAttempts int     `json:"failed_attempts,omitempty" example:"0"`
 }
 
+// UserPermissionsResponse represents what a user may do and why
+type UserPermissionsResponse struct {
+	UserID          string   `json:"user_id" example:"user-123"`
+	Role            string   `json:"role" example:"viewer"`
+	Status          string   `json:"status" example:"suspended"`
+	RolePermissions []string `json:"role_permissions" example:"profile:read,sessions:manage,proxy:access"`
+	Permissions     []string `json:"permissions" example:""`
+	Reason          string   `json:"reason,omitempty" example:"Your account has been suspended, please contact support"`
+}
+
 // UserActionResponse represents response after user action (approve, suspend, etc.)
 type UserActionResponse struct {
 	Message string       `json:"message" example:"User approved successfully"`
 	User    UserResponse `json:"user"`
 }
+
+// UserImportRowResponse reports the outcome of one import row
+type UserImportRowResponse struct {
+	Line    int    `json:"line" example:"2"`
+	Email   string `json:"email" example:"user@example.com"`
+	Outcome string `json:"outcome" example:"created" enums:"created,valid,skipped,failed"`
+	UserID  string `json:"user_id,omitempty" example:"user-123"`
+	Error   string `json:"error,omitempty" example:"email is already registered"`
+}
+
+// UserImportResponse represents the result of a user import
+type UserImportResponse struct {
+	DryRun  bool                    `json:"dry_run" example:"false"`
+	Created int                     `json:"created" example:"8"`
+	Valid   int                     `json:"valid" example:"0"`
+	Skipped int                     `json:"skipped" example:"1"`
+	Failed  int                     `json:"failed" example:"1"`
+	Results []UserImportRowResponse `json:"results"`
+}
@@ -1,18 +1,36 @@
 package response
 
+import "time"
+
 // ConfirmRegisterResponse represents user registration confirmation response
 type ConfirmRegisterResponse struct {
-	User    UserResponse `json:"user"`
-	Message string       `json:"message" example:"Registration successful. Please wait for admin approval."`
+	User UserResponse `json:"user"`
+	// NextStep tells the client where to route the user after registering
+	NextStep string `json:"next_step" example:"await_approval" enums:"verify_email,await_approval,ready"`
+	Message  string `json:"message" example:"Registration successful. Please wait for admin approval."`
 }
 
 // VerifyTokenResponse represents token verification response
 type VerifyTokenResponse struct {
 	Valid bool         `json:"valid" example:"true"`
 	User  UserResponse `json:"user"`
+	// EmailVerificationDueAt is set while the user may still sign in
+	// without having verified their email
+	EmailVerificationDueAt *time.Time `json:"email_verification_due_at,omitempty" example:"2024-01-04T00:00:00Z"`
 }
 
 // ProfileResponse represents user profile response (same as UserResponse but can be extended)
 type ProfileResponse struct {
 	User UserResponse `json:"user"`
 }
+
+// EmailAvailabilityResponse represents email availability check response
+type EmailAvailabilityResponse struct {
+	Available bool `json:"available" example:"true"`
+}
+
+// ActionEmailResponse represents the response to a verification or
+// password reset email request
+type ActionEmailResponse struct {
+	Message string `json:"message" example:"Verification email sent"`
+}
@@ -44,4 +44,38 @@ type UserResponse struct {
 	ApprovalDate  *time.Time `json:"approval_date,omitempty" example:"2023-10-01T12:00:00Z"`
 	CreatedAt     time.Time  `json:"created_at" example:"2023-09-01T12:00:00Z"`
 	UpdatedAt     time.Time  `json:"updated_at" example:"2023-09-15T12:00:00Z"`
+
+	PendingPromotionBy string `json:"pending_promotion_by,omitempty" example:"admin-123"`
+
+	OwnershipTransferStatus string `json:"ownership_transfer_status,omitempty" example:"pending"`
+	OwnershipTransferTo     string `json:"ownership_transfer_to,omitempty" example:"user-456"`
+}
+
+// VersionResponse represents build information of the running service
+type VersionResponse struct {
+	Version   string `json:"version" example:"v1.4.0"`
+	Commit    string `json:"commit" example:"3f2c1e9"`
+	BuildTime string `json:"build_time" example:"2024-05-01T12:00:00Z"`
+	GoVersion string `json:"go_version" example:"go1.24.0"`
+}
+
+// UserStatsResponse represents aggregate user counts for the admin dashboard
+type UserStatsResponse struct {
+	TotalUsers      int64            `json:"total_users" example:"120"`
+	ByStatus        map[string]int64 `json:"by_status"`
+	ByRole          map[string]int64 `json:"by_role"`
+	PendingApproval int64            `json:"pending_approval" example:"4"`
+	NewThisWeek     int64            `json:"new_this_week" example:"7"`
+	GeneratedAt     time.Time        `json:"generated_at" example:"2023-10-15T14:30:00Z"`
+}
+
+// FeatureFlagResponse represents the current state of a feature flag
+type FeatureFlagResponse struct {
+	Name string `json:"name" example:"require_email_verification"`
+	// Configured is false when the flag is undefined and Default applies to everyone
+	Configured bool     `json:"configured" example:"true"`
+	Default    bool     `json:"default" example:"false"`
+	Enabled    bool     `json:"enabled" example:"false"`
+	Percentage int      `json:"percentage" example:"25"`
+	Users      []string `json:"users,omitempty"`
 }
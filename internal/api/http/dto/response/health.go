@@ -0,0 +1,27 @@
+package response
+
+// HealthResponse reports that the process is alive
+type HealthResponse struct {
+	Status  string `json:"status" example:"healthy"`
+	Service string `json:"service" example:"auth-service"`
+	Version string `json:"version" example:"v1.4.0"`
+	// UptimeSeconds is omitted while the service is still starting
+	UptimeSeconds int64 `json:"uptime_seconds,omitempty" example:"86400"`
+}
+
+// ReadinessResponse reports whether the service can serve requests, with
+// the result of each dependency check
+type ReadinessResponse struct {
+	Status        string                      `json:"status" example:"ready"`
+	Service       string                      `json:"service" example:"auth-service"`
+	Version       string                      `json:"version" example:"v1.4.0"`
+	UptimeSeconds int64                       `json:"uptime_seconds" example:"86400"`
+	Dependencies  map[string]DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus is the outcome of checking one dependency
+type DependencyStatus struct {
+	Status    string `json:"status" example:"up"`
+	LatencyMs int64  `json:"latency_ms" example:"12"`
+	Error     string `json:"error,omitempty" example:"context deadline exceeded"`
+}
@@ -6,11 +6,15 @@ import "time"
 type SessionResponse struct {
 	SessionID    string                 `json:"session_id" example:"abc123def456"`
 	UserID       string                 `json:"user_id" example:"user-123"`
+	Scope        string                 `json:"scope" example:"default"`
 	CreatedAt    time.Time              `json:"created_at" example:"2023-10-15T14:30:00Z"`
 	ExpiresAt    time.Time              `json:"expires_at" example:"2023-10-15T15:00:00Z"`
 	LastUsedAt   time.Time              `json:"last_used_at" example:"2023-10-15T14:45:00Z"`
 	RequestCount int64                  `json:"request_count" example:"42"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	// Location is where the session was created from, approximated from its
+	// IP address; set in listings when geolocation is configured
+	Location string `json:"location,omitempty" example:"Istanbul, TR"`
 }
 
 // CreateSessionResponse represents session creation response
@@ -26,6 +30,25 @@ type SessionListResponse struct {
 	Sessions       []SessionResponse `json:"sessions"`
 }
 
+// ScopeSessionsResponse represents a user's sessions in one scope and the
+// scope's lifetime policy
+type ScopeSessionsResponse struct {
+	Scope              string            `json:"scope" example:"image-serve"`
+	ExpirationSeconds  int64             `json:"expiration_seconds" example:"600"`
+	MaxSessionsPerUser int               `json:"max_sessions_per_user" example:"5"`
+	ActiveSessions     int               `json:"active_sessions" example:"2"`
+	Sessions           []SessionResponse `json:"sessions"`
+}
+
+// AllSessionsResponse represents a user's sessions grouped by scope
+type AllSessionsResponse struct {
+	ActiveSessions int `json:"active_sessions" example:"4"`
+	// MaxSessions caps active sessions across all scopes; omitted when
+	// only the per-scope limits apply
+	MaxSessions int                     `json:"max_sessions,omitempty" example:"6"`
+	Scopes      []ScopeSessionsResponse `json:"scopes"`
+}
+
 // SessionStatsResponse represents session statistics
 type SessionStatsResponse struct {
 	ActiveSessions int                    `json:"active_sessions" example:"3"`
@@ -37,6 +60,7 @@ type SessionStatsResponse struct {
 // SessionDetailedStats represents detailed statistics for a session
 type SessionDetailedStats struct {
 	SessionID    string                 `json:"session_id" example:"abc123def456"`
+	Scope        string                 `json:"scope" example:"default"`
 	CreatedAt    time.Time              `json:"created_at" example:"2023-10-15T14:30:00Z"`
 	ExpiresAt    time.Time              `json:"expires_at" example:"2023-10-15T15:00:00Z"`
 	LastUsedAt   time.Time              `json:"last_used_at" example:"2023-10-15T14:45:00Z"`
@@ -48,12 +72,25 @@ type SessionDetailedStats struct {
 // RevokeSessionResponse represents session revocation response
 type RevokeSessionResponse struct {
 	Message string `json:"message" example:"Session revoked successfully"`
+	// UserID is the owner of the revoked session, set for admin revocations
+	UserID string `json:"user_id,omitempty" example:"user-123"`
 }
 
 // RevokeAllSessionsResponse represents bulk session revocation response
 type RevokeAllSessionsResponse struct {
 	Message         string `json:"message" example:"All sessions revoked successfully"`
 	RevokedSessions int    `json:"revoked_sessions" example:"3"`
+	FailedCount     int    `json:"failed_count" example:"0"`
+	// FailedSessions lists sessions that could not be revoked and should be retried
+	FailedSessions []string `json:"failed_sessions,omitempty"`
+	// Failures gives the reason each failed session could not be revoked
+	Failures []SessionRevocationFailureResponse `json:"failures,omitempty"`
+}
+
+// SessionRevocationFailureResponse describes a session that could not be revoked
+type SessionRevocationFailureResponse struct {
+	SessionID string `json:"session_id" example:"a1b2c3d4"`
+	Reason    string `json:"reason" example:"timed out"`
 }
 
 // ExtendSessionResponse represents session extension response
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	dtoRequest "github.com/histopathai/auth-service/internal/api/http/dto/request"
 	dtoResponse "github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/service"
 	"github.com/histopathai/auth-service/internal/shared/errors"
 	"github.com/histopathai/auth-service/internal/shared/query"
@@ -36,9 +40,10 @@ func NewAdminHandler(authService service.AuthService, logger *slog.Logger) *Admi
 // @Param offset query int false "Items to skip" default(0) minimum(0)
 // @Param sort_by query string false "Sort field" default(created_at) Enums(created_at, updated_at, email, display_name)
 // @Param sort_order query string false "Sort direction" default(desc) Enums(asc, desc)
-// @Param status query string false "Filter by status" Enums(pending, active, suspended)
+// @Param status query string false "Filter by status" Enums(pending, active, suspended, rejected)
 // @Param role query string false "Filter by role" Enums(user, admin)
 // @Param search query string false "Search in email and display name"
+// @Param fields query string false "Comma-separated response fields to return, e.g. user_id,email,status"
 // @Success 200 {object} response.UserListResponse "Users retrieved successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
@@ -48,18 +53,18 @@ func NewAdminHandler(authService service.AuthService, logger *slog.Logger) *Admi
 func (h *AdminHandler) ListUsers(c *gin.Context) {
 	var req dtoRequest.ListUsersRequest
 
-	if err := c.ShouldBindQuery(&req); err != nil {
-		details := make(map[string]interface{})
-		if _, ok := err.(validator.ValidationErrors); ok {
-			details["errors"] = err.Error()
-			h.handleError(c, errors.NewValidationError("Invalid query parameters", details))
-			return
-		}
+	if details := bindQuery(c, &req); details != nil {
 		h.handleError(c, errors.NewValidationError("Invalid query parameters", details))
 		return
 	}
 	req.ApplyDefaults()
 
+	fields, err := parseFields(c, dtoResponse.UserResponse{})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
 	pagination := &query.Pagination{
 		Limit:     req.Limit,
 		Offset:    req.Offset,
@@ -87,7 +92,139 @@ func (h *AdminHandler) ListUsers(c *gin.Context) {
 		},
 	}
 
-	h.response.SuccessList(c, response.Data, &response.Pagination)
+	data, err := fields.project(response.Data)
+	if err != nil {
+		h.handleError(c, errors.NewInternalError("failed to select response fields", err))
+		return
+	}
+
+	h.response.SuccessList(c, data, response.Pagination)
+}
+
+// ListPendingUsers
+// @Summary List Pending Users
+// @Description Get users awaiting approval, oldest first. With overdue=true only users past the approval TTL are returned (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Param offset query int false "Items to skip" default(0) minimum(0)
+// @Param overdue query bool false "Only users pending longer than the approval TTL"
+// @Param fields query string false "Comma-separated response fields to return, e.g. user_id,email,status"
+// @Success 200 {object} response.UserListResponse "Pending users retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request or approval TTL not configured"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/pending [get]
+func (h *AdminHandler) ListPendingUsers(c *gin.Context) {
+	var req dtoRequest.ListPendingUsersRequest
+	if details := bindQuery(c, &req); details != nil {
+		h.handleError(c, errors.NewValidationError("Invalid query parameters", details))
+		return
+	}
+	req.ApplyDefaults()
+
+	fields, err := parseFields(c, dtoResponse.UserResponse{})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	pagination := &query.Pagination{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	result, err := h.authService.ListPendingUsers(c.Request.Context(), req.Overdue, pagination)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	users := make([]dtoResponse.UserResponse, len(result.Data))
+	for i, user := range result.Data {
+		users[i] = mapToUserResponse(user)
+	}
+
+	data, err := fields.project(users)
+	if err != nil {
+		h.handleError(c, errors.NewInternalError("failed to select response fields", err))
+		return
+	}
+
+	h.response.SuccessList(c, data, dtoResponse.PaginationResponse{
+		Limit:   result.Limit,
+		Offset:  result.Offset,
+		HasMore: result.HasMore,
+	})
+}
+
+// GetUserStats
+// @Summary Get User Statistics
+// @Description Get aggregate user counts by status and role, pending approvals and registrations in the last seven days. Results are cached briefly (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.SuccessResponse{data=response.UserStatsResponse} "User statistics retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/stats/users [get]
+func (h *AdminHandler) GetUserStats(c *gin.Context) {
+	stats, err := h.authService.GetUserStats(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserStatsResponse{
+		TotalUsers:      stats.Total,
+		ByStatus:        make(map[string]int64, len(stats.ByStatus)),
+		ByRole:          make(map[string]int64, len(stats.ByRole)),
+		PendingApproval: stats.ByStatus[model.StatusPending],
+		NewThisWeek:     stats.NewThisWeek,
+		GeneratedAt:     stats.GeneratedAt,
+	}
+	for status, count := range stats.ByStatus {
+		response.ByStatus[string(status)] = count
+	}
+	for role, count := range stats.ByRole {
+		response.ByRole[string(role)] = count
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
+// ListFeatureFlags
+// @Summary List Feature Flags
+// @Description Get the current rollout rules of feature flags. Undefined flags report the default that applies to everyone (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.SuccessResponse{data=[]response.FeatureFlagResponse} "Feature flags retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Router /admin/feature-flags [get]
+func (h *AdminHandler) ListFeatureFlags(c *gin.Context) {
+	flags := h.authService.FeatureFlags()
+
+	response := make([]dtoResponse.FeatureFlagResponse, 0, len(flags))
+	for _, flag := range flags {
+		response = append(response, dtoResponse.FeatureFlagResponse{
+			Name:       flag.Name,
+			Configured: flag.Configured,
+			Default:    flag.Default,
+			Enabled:    flag.Rule.Enabled,
+			Percentage: flag.Rule.Percentage,
+			Users:      flag.Rule.Users,
+		})
+	}
+
+	h.response.Success(c, http.StatusOK, response)
 }
 
 // GetUser
@@ -98,7 +235,9 @@ func (h *AdminHandler) ListUsers(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.UserDetailResponse "User retrieved successfully"
+// @Param fields query string false "Comma-separated response fields to return, e.g. user_id,email,status"
+// @Success 200 {object} response.SuccessResponse{data=response.UserDetailResponse} "User retrieved successfully"
+// @Header 200 {string} ETag "Version of the user, for If-Match on updates"
 // @Failure 400 {object} response.ErrorResponse "Invalid UserID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
@@ -112,6 +251,12 @@ func (h *AdminHandler) GetUser(c *gin.Context) {
 		return
 	}
 
+	fields, err := parseFields(c, dtoResponse.UserDetailResponse{})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
 	user, err := h.authService.GetUserByUserID(c.Request.Context(), userID)
 	if err != nil {
 		h.handleError(c, err)
@@ -121,20 +266,182 @@ func (h *AdminHandler) GetUser(c *gin.Context) {
 	response := dtoResponse.UserDetailResponse{
 		UserResponse: mapToUserResponse(user),
 	}
+	data, err := fields.project(response)
+	if err != nil {
+		h.handleError(c, errors.NewInternalError("failed to select response fields", err))
+		return
+	}
 
-	h.response.Success(c, http.StatusOK, response)
+	c.Header("ETag", respond.VersionETag(user.UpdatedAt))
+	h.response.Success(c, http.StatusOK, data)
+}
+
+// GetUserPermissions
+// @Summary Get User Permissions
+// @Description Get the permissions a user has from their role and account status (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Success 200 {object} response.SuccessResponse{data=response.UserPermissionsResponse} "Permissions retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/{user_id}/permissions [get]
+func (h *AdminHandler) GetUserPermissions(c *gin.Context) {
+	permissions, err := h.authService.GetUserPermissions(c.Request.Context(), c.Param("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.response.Success(c, http.StatusOK, dtoResponse.UserPermissionsResponse{
+		UserID:          permissions.UserID,
+		Role:            string(permissions.Role),
+		Status:          string(permissions.Status),
+		RolePermissions: permissionNames(permissions.RolePermissions),
+		Permissions:     permissionNames(permissions.Permissions),
+		Reason:          permissions.Reason,
+	})
+}
+
+func permissionNames(permissions []model.Permission) []string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return names
+}
+
+// UpdateUser
+// @Summary Update User
+// @Description Update a user's display name or role (Admin only). Send the ETag from GET /admin/users/{user_id} as If-Match to fail with 409 instead of overwriting changes made since it was read. Admin is granted only through make-admin.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Param If-Match header string false "ETag of the user as last read"
+// @Param request body request.UpdateUserRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse{data=response.UserDetailResponse} "User updated successfully"
+// @Header 200 {string} ETag "Version of the updated user"
+// @Failure 400 {object} response.ErrorResponse "Invalid request or If-Match header"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "User was modified since it was read"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/{user_id} [patch]
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		h.handleError(c, errors.NewValidationError("Missing UserID", nil))
+		return
+	}
+
+	var req dtoRequest.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+		return
+	}
+
+	// An absent If-Match or "*" updates unconditionally
+	var updatedAt time.Time
+	if ifMatch := strings.TrimSpace(c.GetHeader("If-Match")); ifMatch != "" && ifMatch != "*" {
+		var ok bool
+		updatedAt, ok = respond.ParseVersionETag(ifMatch)
+		if !ok {
+			h.handleError(c, errors.NewValidationError("Invalid If-Match header", map[string]interface{}{
+				"If-Match": "must be an ETag returned by GET /admin/users/{user_id}",
+			}))
+			return
+		}
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	edit := &model.UserEdit{DisplayName: req.DisplayName}
+	if req.Role != nil {
+		role := model.UserRole(*req.Role)
+		edit.Role = &role
+	}
+
+	user, err := h.authService.EditUser(c.Request.Context(), userID, edit, updatedAt, adminID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("ETag", respond.VersionETag(user.UpdatedAt))
+	h.response.Success(c, http.StatusOK, dtoResponse.UserDetailResponse{
+		UserResponse: mapToUserResponse(user),
+	})
+}
+
+// GetUserByEmail
+// @Summary Get User by Email
+// @Description Get detailed user information by email address. Matching ignores case (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param email query string true "User email"
+// @Param fields query string false "Comma-separated response fields to return, e.g. user_id,email,status"
+// @Success 200 {object} response.SuccessResponse{data=response.UserDetailResponse} "User retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid email"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/by-email [get]
+func (h *AdminHandler) GetUserByEmail(c *gin.Context) {
+	var req dtoRequest.GetUserByEmailRequest
+	if details := bindQuery(c, &req); details != nil {
+		h.handleError(c, errors.NewValidationError("Invalid email", details))
+		return
+	}
+
+	fields, err := parseFields(c, dtoResponse.UserDetailResponse{})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	user, err := h.authService.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserDetailResponse{
+		UserResponse: mapToUserResponse(user),
+	}
+	data, err := fields.project(response)
+	if err != nil {
+		h.handleError(c, errors.NewInternalError("failed to select response fields", err))
+		return
+	}
+
+	h.response.Success(c, http.StatusOK, data)
 }
 
 // ApproveUser
 // @Summary Approve User
-// @Description Approve pending user account (Admin only)
+// @Description Approve pending user account (Admin only). The role defaults to the user's current role; only the configured approval roles can be assigned, never admin.
 // @Tags Admin
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.UserDetailResponse "User approved successfully"
-// @Failure 400 {object} response.ErrorResponse "Invalid UserID"
+// @Param request body request.ApproveUserRequest false "Role to assign"
+// @Success 200 {object} response.SuccessResponse{data=response.UserDetailResponse} "User approved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid UserID or role not assignable by approval"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 404 {object} response.ErrorResponse "User not found"
@@ -147,7 +454,15 @@ func (h *AdminHandler) ApproveUser(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.ApproveUser(c.Request.Context(), userID)
+	var req dtoRequest.ApproveUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+			return
+		}
+	}
+
+	err := h.authService.ApproveUser(c.Request.Context(), userID, model.UserRole(req.Role))
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -173,7 +488,7 @@ func (h *AdminHandler) ApproveUser(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.UserActionResponse "User suspended successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "User suspended successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid UserID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
@@ -207,19 +522,157 @@ func (h *AdminHandler) SuspendUser(c *gin.Context) {
 
 }
 
+// VerifyUserEmail
+// @Summary Verify User Email
+// @Description Mark a user's email as verified without the verification email, e.g. when the user cannot receive it (Admin only). The user sees the change once their ID token is refreshed.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "Email marked as verified"
+// @Failure 400 {object} response.ErrorResponse "Invalid UserID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "Email already verified"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/{user_id}/verify-email [post]
+func (h *AdminHandler) VerifyUserEmail(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		h.handleError(c, errors.NewValidationError("Missing UserID", nil))
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	if err := h.authService.VerifyUserEmail(c.Request.Context(), userID, adminID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	user, err := h.authService.GetUserByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserActionResponse{
+		Message: "Email marked as verified",
+		User:    mapToUserResponse(user),
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
+// ImportUsers
+// @Summary Import Users
+// @Description Create users from a CSV file with a header row of email, display_name, role and status, e.g. when migrating from another system (Admin only). The file is sent as the multipart field "file" or as a text/csv body. Invalid rows fail and rows whose email is already registered are skipped without stopping the import. Rows with the admin role fail; admins are made through promotion. Active rows must use a role an approval may assign. Imported users set their password through a password reset.
+// @Tags Admin
+// @Accept multipart/form-data,text/csv
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file false "CSV file"
+// @Param dry_run query bool false "Validate the rows without creating users"
+// @Success 200 {object} response.SuccessResponse{data=response.UserImportResponse} "Per-row import results"
+// @Failure 400 {object} response.ErrorResponse "Invalid or unreadable CSV"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/import [post]
+func (h *AdminHandler) ImportUsers(c *gin.Context) {
+	var req dtoRequest.ImportUsersRequest
+	if details := bindQuery(c, &req); details != nil {
+		h.handleError(c, errors.NewValidationError("Invalid query parameters", details))
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileBytes)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			h.handleError(c, errors.NewValidationError("Missing import file", map[string]interface{}{
+				"file": err.Error(),
+			}))
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			h.handleError(c, errors.NewInternalError("failed to open import file", err))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	rows, err := parseUserImportCSV(body)
+	if err != nil {
+		h.handleError(c, errors.NewValidationError("Invalid import file", map[string]interface{}{
+			"file": err.Error(),
+		}))
+		return
+	}
+
+	results, err := h.authService.ImportUsers(c.Request.Context(), rows, req.DryRun, adminID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserImportResponse{
+		DryRun:  req.DryRun,
+		Results: make([]dtoResponse.UserImportRowResponse, len(results)),
+	}
+	for i, result := range results {
+		switch result.Outcome {
+		case model.UserImportCreated:
+			response.Created++
+		case model.UserImportValid:
+			response.Valid++
+		case model.UserImportSkipped:
+			response.Skipped++
+		case model.UserImportFailed:
+			response.Failed++
+		}
+		response.Results[i] = dtoResponse.UserImportRowResponse{
+			Line:    result.Line,
+			Email:   result.Email,
+			Outcome: string(result.Outcome),
+			UserID:  result.UserID,
+			Error:   result.Error,
+		}
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
 // MakeAdmin
 // @Summary Make User Admin
-// @Description Grant admin role to a user (Admin only)
+// @Description Grant admin role to a user (Admin only). When dual control is enabled the promotion stays pending until another admin confirms it.
 // @Tags Admin
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.UserActionResponse "User granted admin role successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "User granted admin role successfully"
+// @Success 202 {object} response.SuccessResponse{data=response.UserActionResponse} "Admin promotion pending confirmation"
 // @Failure 400 {object} response.ErrorResponse "Invalid UserID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "Promotion already pending"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /admin/users/{user_id}/make-admin [post]
 func (h *AdminHandler) MakeAdmin(c *gin.Context) {
@@ -229,7 +682,13 @@ func (h *AdminHandler) MakeAdmin(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.PromoteUserToAdmin(c.Request.Context(), userID)
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	pending, err := h.authService.PromoteUserToAdmin(c.Request.Context(), userID, adminID.(string))
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -240,6 +699,15 @@ func (h *AdminHandler) MakeAdmin(c *gin.Context) {
 		return
 	}
 
+	if pending {
+		response := dtoResponse.UserActionResponse{
+			Message: "Admin promotion pending confirmation by another admin",
+			User:    mapToUserResponse(user),
+		}
+		h.response.Success(c, http.StatusAccepted, response)
+		return
+	}
+
 	response := dtoResponse.UserActionResponse{
 		Message: "User granted admin role successfully",
 		User:    mapToUserResponse(user),
@@ -248,6 +716,201 @@ func (h *AdminHandler) MakeAdmin(c *gin.Context) {
 	h.response.Success(c, http.StatusOK, response)
 }
 
+// ConfirmPromotion
+// @Summary Confirm Admin Promotion
+// @Description Confirm a pending admin promotion requested by a different admin (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "Admin promotion confirmed successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid UserID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Cannot confirm own promotion request"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "No pending promotion"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/{user_id}/confirm-promotion [post]
+func (h *AdminHandler) ConfirmPromotion(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		h.handleError(c, errors.NewValidationError("Missing UserID", nil))
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	if err := h.authService.ConfirmAdminPromotion(c.Request.Context(), userID, adminID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	user, err := h.authService.GetUserByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserActionResponse{
+		Message: "Admin promotion confirmed successfully",
+		User:    mapToUserResponse(user),
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
+// TransferOwnership
+// @Summary Transfer Ownership
+// @Description Ask other services to reassign all data owned by a user to another user. The user cannot be deleted until the transfer is acknowledged (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Param payload body request.TransferOwnershipRequest true "User receiving ownership"
+// @Success 202 {object} response.SuccessResponse{data=response.UserActionResponse} "Ownership transfer requested"
+// @Failure 400 {object} response.ErrorResponse "Invalid request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "Transfer already pending, target not active, or transfers not configured"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/{user_id}/transfer-ownership [post]
+func (h *AdminHandler) TransferOwnership(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		h.handleError(c, errors.NewValidationError("Missing UserID", nil))
+		return
+	}
+
+	var req dtoRequest.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	if err := h.authService.RequestOwnershipTransfer(c.Request.Context(), userID, req.TargetUserID, adminID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	user, err := h.authService.GetUserByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserActionResponse{
+		Message: "Ownership transfer requested; awaiting acknowledgement",
+		User:    mapToUserResponse(user),
+	}
+
+	h.response.Success(c, http.StatusAccepted, response)
+}
+
+// CancelOwnershipTransfer
+// @Summary Cancel Ownership Transfer
+// @Description Abandon a user's pending ownership transfer so the user can be deleted or the transfer requested again (admin only)
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "Ownership transfer cancelled"
+// @Failure 400 {object} response.ErrorResponse "Invalid request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "No pending transfer"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /admin/users/{user_id}/transfer-ownership [delete]
+func (h *AdminHandler) CancelOwnershipTransfer(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		h.handleError(c, errors.NewValidationError("Missing UserID", nil))
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	if err := h.authService.CancelOwnershipTransfer(c.Request.Context(), userID, adminID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	user, err := h.authService.GetUserByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserActionResponse{
+		Message: "Ownership transfer cancelled",
+		User:    mapToUserResponse(user),
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
+// AcknowledgeOwnershipTransfer
+// @Summary Acknowledge Ownership Transfer
+// @Description Confirm that a user's data was reassigned, allowing the user to be deleted (internal services only)
+// @Tags Internal
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id path string true "User UserID"
+// @Param payload body request.TransferOwnershipRequest true "User that received ownership"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "Ownership transfer acknowledged"
+// @Failure 400 {object} response.ErrorResponse "Invalid request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Service not allowed"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "No matching pending transfer"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /internal/users/{user_id}/transfer-ownership/ack [post]
+func (h *AdminHandler) AcknowledgeOwnershipTransfer(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		h.handleError(c, errors.NewValidationError("Missing UserID", nil))
+		return
+	}
+
+	var req dtoRequest.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+		return
+	}
+
+	if err := h.authService.AcknowledgeOwnershipTransfer(c.Request.Context(), userID, req.TargetUserID, c.GetString("service_account")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	user, err := h.authService.GetUserByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.UserActionResponse{
+		Message: "Ownership transfer acknowledged",
+		User:    mapToUserResponse(user),
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
 // DeleteUser
 // @Summary Delete User
 // @Description Delete a user account (Admin only)
@@ -256,11 +919,12 @@ func (h *AdminHandler) MakeAdmin(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.UserActionResponse "User deleted successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.UserActionResponse} "User deleted successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid UserID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "Ownership transfer not yet acknowledged"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /admin/users/{user_id}/delete [put]
 func (h *AdminHandler) DeleteUser(c *gin.Context) {
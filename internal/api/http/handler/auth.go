@@ -1,25 +1,32 @@
 package handler
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	dtoRequest "github.com/histopathai/auth-service/internal/api/http/dto/request"
 	dtoResponse "github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/service"
 	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/pkg/config"
 )
 
 type AuthHandler struct {
 	authService service.AuthService
+	config      *config.Config
 	BaseHandler
 }
 
-func NewAuthHandler(authService service.AuthService, logger *slog.Logger) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, config *config.Config, logger *slog.Logger) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		config:      config,
 		BaseHandler: BaseHandler{logger: logger, response: &ResponseHelper{}},
 	}
 }
@@ -31,9 +38,11 @@ func NewAuthHandler(authService service.AuthService, logger *slog.Logger) *AuthH
 // @Accept json
 // @Produce json
 // @Param payload body request.ConfirmRegisterRequest true "Registration details"
-// @Success 201 {object} response.ConfirmRegisterResponse "User registered successfully"
+// @Success 201 {object} response.SuccessResponse{data=response.ConfirmRegisterResponse} "User registered successfully"
+// @Header 201 {string} Location "URL of the registered user's profile"
 // @Failure 400 {object} response.ErrorResponse "Invalid request"
 // @Failure 409 {object} response.ErrorResponse "Email already exists"
+// @Failure 429 {object} response.ErrorResponse "Too many registrations from this IP"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -43,7 +52,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.RegisterUser(c.Request.Context(), &model.ConfirmRegisterUser{
+	result, err := h.authService.RegisterUser(c.Request.Context(), &model.ConfirmRegisterUser{
 		Email:       req.Email,
 		DisplayName: req.DisplayName,
 		Token:       req.Token,
@@ -54,11 +63,61 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	response := dtoResponse.ConfirmRegisterResponse{
-		User:    mapToUserResponse(user),
-		Message: "User registered successfully",
+		User:     mapToUserResponse(result.User),
+		NextStep: string(result.NextStep),
+		Message:  registrationMessage(result.NextStep),
 	}
 
-	h.response.Success(c, http.StatusCreated, response)
+	h.response.Created(c, response, "/api/v1/users/"+url.PathEscape(result.User.UserID))
+}
+
+// CheckEmailAvailability
+// @Summary Check Email Availability
+// @Description Check whether an email address is free to register. Responses are rate limited per IP and padded to a constant duration to resist enumeration.
+// @Tags Auth
+// @Produce json
+// @Param email query string true "Email address to check"
+// @Success 200 {object} response.SuccessResponse{data=response.EmailAvailabilityResponse} "Availability result"
+// @Failure 400 {object} response.ErrorResponse "Invalid email"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/email-available [get]
+func (h *AuthHandler) CheckEmailAvailability(c *gin.Context) {
+	// Every outcome waits until the same deadline before responding
+	minDuration := time.Duration(h.config.Registration.EmailAvailabilityMinResponse) * time.Millisecond
+	deadline := time.Now().Add(minDuration)
+
+	var req dtoRequest.EmailAvailabilityRequest
+	if details := bindQuery(c, &req); details != nil {
+		waitUntil(c.Request.Context(), deadline)
+		h.handleError(c, errors.NewValidationError("A valid email query parameter is required", details))
+		return
+	}
+
+	available, err := h.authService.IsEmailAvailable(c.Request.Context(), req.Email)
+	waitUntil(c.Request.Context(), deadline)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.EmailAvailabilityResponse{
+		Available: available,
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
+// waitUntil blocks until deadline, or until ctx is done so a client that
+// went away does not hold the request open
+func waitUntil(ctx context.Context, deadline time.Time) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
 }
 
 // VerifyToken
@@ -68,9 +127,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param payload body request.VerifyTokenRequest true "Token to verify"
-// @Success 200 {object} response.VerifyTokenResponse "Token is valid"
+// @Success 200 {object} response.SuccessResponse{data=response.VerifyTokenResponse} "Token is valid"
 // @Failure 400 {object} response.ErrorResponse "Invalid request"
 // @Failure 401 {object} response.ErrorResponse "Invalid or expired token"
+// @Failure 403 {object} response.ErrorResponse "Email verification grace period has passed"
 // @Router /auth/verify [post]
 func (h *AuthHandler) VerifyToken(c *gin.Context) {
 	var req dtoRequest.VerifyTokenRequest
@@ -79,7 +139,7 @@ func (h *AuthHandler) VerifyToken(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.VerifyToken(c.Request.Context(), req.Token)
+	user, authInfo, err := h.authService.VerifyTokenWithClaims(c.Request.Context(), req.Token)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -89,6 +149,9 @@ func (h *AuthHandler) VerifyToken(c *gin.Context) {
 		Valid: true,
 		User:  mapToUserResponse(user),
 	}
+	if deadline, ok := h.authService.EmailVerificationDeadline(user, authInfo.EmailVerified); ok {
+		response.EmailVerificationDueAt = &deadline
+	}
 
 	h.response.Success(c, http.StatusOK, response)
 }
@@ -128,6 +191,74 @@ func (h *AuthHandler) ChangePasswordSelf(c *gin.Context) {
 	h.response.NoContent(c)
 }
 
+// SendVerificationEmail
+// @Summary Send Verification Email
+// @Description Email the authenticated user a link to verify their email address. continue_url must belong to an allowed origin.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body request.VerificationEmailRequest false "Where to continue after verifying"
+// @Success 202 {object} response.SuccessResponse{data=response.ActionEmailResponse} "Verification email sent"
+// @Failure 400 {object} response.ErrorResponse "Invalid request or continue URL not allowed"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 409 {object} response.ErrorResponse "Email already verified"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/verification-email [post]
+func (h *AuthHandler) SendVerificationEmail(c *gin.Context) {
+	var req dtoRequest.VerificationEmailRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+			return
+		}
+	}
+
+	userID, exist := c.Get("user_id")
+	if !exist {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	if err := h.authService.SendVerificationEmail(c.Request.Context(), userID.(string), req.ContinueURL); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.response.Success(c, http.StatusAccepted, dtoResponse.ActionEmailResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// RequestPasswordReset
+// @Summary Request Password Reset
+// @Description Email a password reset link. The response is the same whether or not an account uses the email. continue_url must belong to an allowed origin.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param payload body request.PasswordResetRequest true "Account email"
+// @Success 202 {object} response.SuccessResponse{data=response.ActionEmailResponse} "Reset email sent if the account exists"
+// @Failure 400 {object} response.ErrorResponse "Invalid request or continue URL not allowed"
+// @Failure 429 {object} response.ErrorResponse "Too many requests"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/password-reset [post]
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var req dtoRequest.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+		return
+	}
+
+	if err := h.authService.SendPasswordResetEmail(c.Request.Context(), req.Email, req.ContinueURL); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.response.Success(c, http.StatusAccepted, dtoResponse.ActionEmailResponse{
+		Message: "If an account uses this email, a password reset link has been sent",
+	})
+}
+
 // DeleteAccount
 // @Summary Delete Own Account
 // @Description Endpoint for a user to delete their own account.
@@ -164,7 +295,12 @@ func (h *AuthHandler) DeleteAccount(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} response.ProfileResponse "Profile retrieved successfully"
+// @Param If-None-Match header string false "ETag of a previously fetched profile"
+// @Param If-Modified-Since header string false "Last-Modified of a previously fetched profile"
+// @Success 200 {object} response.SuccessResponse{data=response.ProfileResponse} "Profile retrieved successfully"
+// @Success 304 "Profile not modified"
+// @Header 200 {string} ETag "Hash of the profile"
+// @Header 200 {string} Last-Modified "Time of the last profile change"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /user/profile [get]
@@ -185,6 +321,11 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		User: mapToUserResponse(user),
 	}
 
+	// The frontend polls the profile, so let it revalidate cheaply
+	if etag, err := respond.ETag(response); err == nil && respond.NotModified(c, etag, user.UpdatedAt) {
+		return
+	}
+
 	h.response.Success(c, http.StatusOK, response)
 }
 
@@ -195,7 +336,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User ID"
-// @Success 200 {object} response.PublicUserResponse "User public info"
+// @Success 200 {object} response.SuccessResponse{data=response.PublicUserResponse} "User public info"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 404 {object} response.ErrorResponse "User not found"
 // @Router /users/{user_id} [get]
@@ -227,5 +368,21 @@ func mapToUserResponse(user *model.User) dtoResponse.UserResponse {
 		ApprovalDate:  &user.ApprovalDate,
 		CreatedAt:     user.CreatedAt,
 		UpdatedAt:     user.UpdatedAt,
+
+		PendingPromotionBy: user.PendingPromotionBy,
+
+		OwnershipTransferStatus: string(user.OwnershipTransferStatus),
+		OwnershipTransferTo:     user.OwnershipTransferTo,
+	}
+}
+
+func registrationMessage(step model.RegistrationNextStep) string {
+	switch step {
+	case model.NextStepVerifyEmail:
+		return "Registration successful. Please verify your email address."
+	case model.NextStepAwaitApproval:
+		return "Registration successful. Please wait for admin approval."
+	default:
+		return "Registration successful. You can now sign in."
 	}
 }
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/service"
+	"github.com/histopathai/auth-service/pkg/config"
+)
+
+func TestCheckEmailAvailabilityStopsWaitingWhenClientLeaves(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Registration: config.RegistrationConfig{EmailAvailabilityMinResponse: 10_000}}
+	h := NewAuthHandler(service.AuthService{}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/email-available?email=not-an-email", nil).WithContext(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.CheckEmailAvailability(c)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("CheckEmailAvailability() kept padding the response after the client went away")
+	}
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCheckEmailAvailabilityPadsResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Registration: config.RegistrationConfig{EmailAvailabilityMinResponse: 50}}
+	h := NewAuthHandler(service.AuthService{}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/email-available?email=not-an-email", nil)
+
+	start := time.Now()
+	h.CheckEmailAvailability(c)
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("response took %v, want it padded to 50ms", elapsed)
+	}
+}
@@ -1,30 +1,34 @@
 package handler
 
 import (
+	"context"
 	stderr "errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	response "github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
 	"github.com/histopathai/auth-service/internal/shared/errors"
 )
 
 type ResponseHelper struct{}
 
 func (rh *ResponseHelper) Success(c *gin.Context, statusCode int, data interface{}) {
-	c.JSON(statusCode, data)
+	respond.Success(c, statusCode, data)
 }
 
 func (rh *ResponseHelper) Error(c *gin.Context, statusCode int, errType string, message string, details map[string]interface{}) {
-	c.JSON(statusCode, response.ErrorResponse{
-		ErrorType: errType,
-		Message:   message,
-		Details:   details,
-	})
+	respond.Error(c, statusCode, errType, message, details)
 }
 
-func (rh *ResponseHelper) Created(c *gin.Context, data interface{}) {
+// Created writes a 201 response. An optional location sets the Location
+// header to the URL of the created resource.
+func (rh *ResponseHelper) Created(c *gin.Context, data interface{}, location ...string) {
+	if len(location) > 0 && location[0] != "" {
+		c.Header("Location", location[0])
+	}
 	rh.Success(c, http.StatusCreated, data)
 }
 
@@ -32,14 +36,8 @@ func (rh *ResponseHelper) NoContent(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-func (rh *ResponseHelper) SuccessList(c *gin.Context, data interface{}, pagination *response.PaginationResponse) {
-	response := gin.H{
-		"data": data,
-	}
-	if pagination != nil {
-		response["pagination"] = pagination
-	}
-	c.JSON(http.StatusOK, response)
+func (rh *ResponseHelper) SuccessList(c *gin.Context, data interface{}, pagination response.PaginationResponse) {
+	respond.List(c, data, pagination)
 }
 
 type BaseHandler struct {
@@ -55,32 +53,37 @@ func NewBaseHandler(logger *slog.Logger) *BaseHandler {
 }
 
 func (bh *BaseHandler) handleError(c *gin.Context, err error) {
-	requestID, _ := c.Get("request_id")
+	requestID := c.GetString("request_id")
 	var customErr *errors.Err
 
+	// Whatever error the deadline surfaced as, the request timed out
+	if stderr.Is(c.Request.Context().Err(), context.DeadlineExceeded) && !errors.IsType(err, errors.ErrorTypeTimeout) {
+		err = errors.NewTimeoutError("The request timed out", err)
+	}
+
 	if stderr.As(err, &customErr) {
 		statusCode, errResponse := bh.mapCustomError(customErr)
 
 		bh.logger.Error("Request failed",
-			slog.String("request_id", requestID.(string)),
+			slog.String("request_id", requestID),
 			slog.String("error_type", string(customErr.Type)),
 			slog.String("message", customErr.Message),
 			slog.String("path", c.Request.URL.Path),
 		)
-		c.JSON(statusCode, errResponse)
+		if seconds, ok := customErr.Details["retry_after_seconds"].(int); ok && customErr.Type == errors.ErrorTypeRateLimited {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+		respond.Error(c, statusCode, errResponse.ErrorType, errResponse.Message, errResponse.Details)
 		return
 	}
 
 	bh.logger.Error("Request failed",
-		slog.String("request_id", requestID.(string)),
+		slog.String("request_id", requestID),
 		slog.String("error_type", "unknown"),
 		slog.String("message", err.Error()),
 		slog.String("path", c.Request.URL.Path),
 	)
-	c.JSON(http.StatusInternalServerError, response.ErrorResponse{
-		ErrorType: "unknown",
-		Message:   "An unexpected error occurred",
-	})
+	respond.Error(c, http.StatusInternalServerError, "unknown", "An unexpected error occurred", nil)
 }
 
 func (bh *BaseHandler) mapCustomError(err *errors.Err) (int, response.ErrorResponse) {
@@ -88,9 +91,18 @@ func (bh *BaseHandler) mapCustomError(err *errors.Err) (int, response.ErrorRespo
 		errors.ErrorTypeValidation:   http.StatusBadRequest,
 		errors.ErrorTypeNotFound:     http.StatusNotFound,
 		errors.ErrorTypeConflict:     http.StatusConflict,
+		errors.ErrorTypeOutOfSync:    http.StatusServiceUnavailable,
+		errors.ErrorTypeTimeout:      http.StatusGatewayTimeout,
+		errors.ErrorTypeRateLimited:  http.StatusTooManyRequests,
 		errors.ErrorTypeUnauthorized: http.StatusUnauthorized,
 		errors.ErrorTypeForbidden:    http.StatusForbidden,
-		errors.ErrorTypeInternal:     http.StatusInternalServerError,
+
+		errors.ErrorTypeAccountPendingApproval: http.StatusForbidden,
+		errors.ErrorTypeAccountSuspended:       http.StatusForbidden,
+		errors.ErrorTypeAccountRejected:        http.StatusForbidden,
+		errors.ErrorTypeUserProfileNotFound:    http.StatusNotFound,
+		errors.ErrorTypeEmailNotVerified:       http.StatusForbidden,
+		errors.ErrorTypeInternal:               http.StatusInternalServerError,
 	}
 
 	statusCode, exists := statusMap[err.Type]
@@ -98,9 +110,13 @@ func (bh *BaseHandler) mapCustomError(err *errors.Err) (int, response.ErrorRespo
 		statusCode = http.StatusInternalServerError
 	}
 
-	return statusCode, response.ErrorResponse{
+	errResponse := response.ErrorResponse{
 		ErrorType: string(err.Type),
 		Message:   err.Message,
-		Details:   err.Details,
 	}
+	// A nil map would still be encoded as "details": null
+	if len(err.Details) > 0 {
+		errResponse.Details = err.Details
+	}
+	return statusCode, errResponse
 }
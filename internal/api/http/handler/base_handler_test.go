@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	response "github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// envelopeKeys returns the top-level keys of a JSON response body, sorted
+func envelopeKeys(t *testing.T, body []byte) []string {
+	t.Helper()
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("response is not a JSON object: %s", body)
+	}
+	keys := make([]string, 0, len(envelope))
+	for key := range envelope {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestResponsesUseStandardEnvelope(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	base := NewBaseHandler(logger)
+	health := NewHealthHandler(nil, time.Now(), logger)
+
+	tests := []struct {
+		name     string
+		write    gin.HandlerFunc
+		wantKeys []string
+	}{
+		{name: "success", write: func(c *gin.Context) { base.response.Success(c, http.StatusOK, gin.H{"id": "1"}) }, wantKeys: []string{"data"}},
+		{name: "created", write: func(c *gin.Context) { base.response.Created(c, gin.H{"id": "1"}) }, wantKeys: []string{"data"}},
+		{name: "list", write: func(c *gin.Context) {
+			base.response.SuccessList(c, []string{"a"}, response.PaginationResponse{Limit: 1})
+		}, wantKeys: []string{"data", "pagination"}},
+		{name: "service error", write: func(c *gin.Context) { base.handleError(c, errors.NewNotFoundError("user not found")) }, wantKeys: []string{"error", "message"}},
+		{name: "unexpected error", write: func(c *gin.Context) { base.handleError(c, io.ErrUnexpectedEOF) }, wantKeys: []string{"error", "message"}},
+		{name: "GET /health", write: health.Health, wantKeys: []string{"data"}},
+		{name: "GET /health/ready", write: health.Ready, wantKeys: []string{"data"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			tt.write(c)
+
+			if got := envelopeKeys(t, recorder.Body.Bytes()); !slices.Equal(got, tt.wantKeys) {
+				t.Errorf("response keys = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestHandleErrorRateLimited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	base := NewBaseHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", nil)
+
+	base.handleError(c, errors.NewRateLimitedError("Firebase Auth is temporarily rate limiting requests", 30*time.Second, nil))
+
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusTooManyRequests)
+	}
+	if got := recorder.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	stderr "errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// bindQuery binds query parameters into req. On failure it returns a message
+// per offending parameter, keyed by its query name, e.g.
+// {"limit": "must be an integer between 1 and 100"}.
+func bindQuery(c *gin.Context, req interface{}) map[string]interface{} {
+	err := c.ShouldBindQuery(req)
+	if err == nil {
+		return nil
+	}
+
+	fields := queryFields(reflect.TypeOf(req))
+	details := make(map[string]interface{})
+
+	var validationErrs validator.ValidationErrors
+	if stderr.As(err, &validationErrs) {
+		for _, fe := range validationErrs {
+			if field, ok := fields[fe.StructField()]; ok {
+				details[field.name] = field.describe(fe.Tag())
+			}
+		}
+	} else {
+		// Conversion errors from gin do not name the parameter, so find the
+		// values that cannot be parsed into their field
+		for _, field := range fields {
+			value, ok := c.GetQuery(field.name)
+			if ok && !field.parses(value) {
+				details[field.name] = field.describe("")
+			}
+		}
+	}
+
+	if len(details) == 0 {
+		details["query"] = err.Error()
+	}
+	return details
+}
+
+// queryField describes a struct field bound from a query parameter
+type queryField struct {
+	name    string
+	kind    reflect.Kind
+	binding map[string]string
+}
+
+// queryFields collects the form-tagged fields of a request struct, including
+// those of embedded structs, keyed by Go field name
+func queryFields(t reflect.Type) map[string]queryField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := make(map[string]queryField)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous {
+			for name, field := range queryFields(sf.Type) {
+				fields[name] = field
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		kind := sf.Type.Kind()
+		if kind == reflect.Pointer {
+			kind = sf.Type.Elem().Kind()
+		}
+
+		binding := make(map[string]string)
+		for _, rule := range strings.Split(sf.Tag.Get("binding"), ",") {
+			tag, param, _ := strings.Cut(rule, "=")
+			binding[tag] = param
+		}
+
+		fields[sf.Name] = queryField{name: name, kind: kind, binding: binding}
+	}
+	return fields
+}
+
+// parses reports whether value converts to the field's type. Empty values
+// bind to the zero value.
+func (f queryField) parses(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	var err error
+	switch f.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(value, 10, 64)
+	case reflect.Bool:
+		_, err = strconv.ParseBool(value)
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	return err == nil
+}
+
+// describe explains what the field accepts. Numeric and boolean fields are
+// described by their whole rule set; string fields by the failed tag.
+func (f queryField) describe(failedTag string) string {
+	min, hasMin := f.binding["min"]
+	max, hasMax := f.binding["max"]
+
+	switch f.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch {
+		case hasMin && hasMax:
+			return fmt.Sprintf("must be an integer between %s and %s", min, max)
+		case hasMin:
+			return fmt.Sprintf("must be an integer of at least %s", min)
+		case hasMax:
+			return fmt.Sprintf("must be an integer of at most %s", max)
+		}
+		return "must be an integer"
+	case reflect.Bool:
+		return "must be true or false"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	}
+
+	switch failedTag {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(f.binding["oneof"]), ", ")
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", min)
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", max)
+	}
+	return "is invalid"
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/internal/shared/jsonutil"
+)
+
+// fieldSet holds the response fields selected with ?fields=, e.g.
+// ?fields=user_id,email,status. A nil set selects every field.
+type fieldSet map[string]bool
+
+// parseFields reads the fields selector, accepting only the JSON field names
+// of the response type of model
+func parseFields(c *gin.Context, model interface{}) (fieldSet, error) {
+	selector := strings.TrimSpace(c.Query("fields"))
+	if selector == "" {
+		return nil, nil
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(model))
+	fields := make(fieldSet)
+	var unknown []string
+	for _, name := range strings.Split(selector, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		fields[name] = true
+	}
+
+	if len(unknown) > 0 || len(fields) == 0 {
+		allowed := make([]string, 0, len(known))
+		for name := range known {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+
+		details := map[string]interface{}{"allowed": allowed}
+		if len(unknown) > 0 {
+			details["unknown"] = unknown
+		}
+		return nil, errors.NewValidationError("Invalid fields selector", details)
+	}
+
+	return fields, nil
+}
+
+// jsonFieldNames collects the JSON names of a struct's fields, including
+// those of embedded structs
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && name == "" {
+			for embedded := range jsonFieldNames(sf.Type) {
+				names[embedded] = true
+			}
+			continue
+		}
+		if name == "-" || !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// project keeps only the selected fields of data. It filters the serialized
+// form, so it applies alike to a single object and to a list of objects;
+// numbers are kept as json.Number so int64 fields keep their precision.
+func (f fieldSet) project(data interface{}) (interface{}, error) {
+	if f == nil {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := jsonutil.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch v := decoded.(type) {
+	case map[string]interface{}:
+		f.filter(v)
+	case []interface{}:
+		for _, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				f.filter(obj)
+			}
+		}
+	}
+	return decoded, nil
+}
+
+func (f fieldSet) filter(obj map[string]interface{}) {
+	for name := range obj {
+		if !f[name] {
+			delete(obj, name)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	response "github.com/histopathai/auth-service/internal/api/http/dto/response"
+)
+
+func TestProjectKeepsLargeRequestCounts(t *testing.T) {
+	const requestCount = int64(1)<<53 + 1
+	fields := fieldSet{"session_id": true, "request_count": true}
+
+	projected, err := fields.project([]response.SessionResponse{{SessionID: "session-1", RequestCount: requestCount}})
+	if err != nil {
+		t.Fatalf("project() error = %v", err)
+	}
+	body, err := json.Marshal(projected)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var sessions []response.SessionResponse
+	if err := json.Unmarshal(body, &sessions); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].RequestCount != requestCount {
+		t.Errorf("projected sessions = %s, want request_count %d", body, requestCount)
+	}
+}
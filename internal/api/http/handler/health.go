@@ -1,20 +1,37 @@
 package handler
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/pkg/version"
 )
 
+// DependencyCheck reports whether a dependency is reachable
+type DependencyCheck func(ctx context.Context) error
+
+// dependencyCheckTimeout bounds each readiness dependency check
+const dependencyCheckTimeout = 2 * time.Second
+
 // HealthHandler handles health check requests
 type HealthHandler struct {
+	// checks are run by the readiness probe, keyed by dependency name
+	checks    map[string]DependencyCheck
+	startedAt time.Time
 	BaseHandler
 }
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler(logger *slog.Logger) *HealthHandler {
+// NewHealthHandler creates a new health handler. startedAt is when the
+// service started, for reporting uptime.
+func NewHealthHandler(checks map[string]DependencyCheck, startedAt time.Time, logger *slog.Logger) *HealthHandler {
 	return &HealthHandler{
+		checks:      checks,
+		startedAt:   startedAt,
 		BaseHandler: BaseHandler{logger: logger, response: &ResponseHelper{}},
 	}
 }
@@ -24,31 +41,105 @@ func NewHealthHandler(logger *slog.Logger) *HealthHandler {
 // @Description Returns the overall health status of the service
 // @Tags Health
 // @Produce json
-// @Success 200 {object} object{status=string,service=string} "Service is healthy"
+// @Success 200 {object} response.SuccessResponse{data=response.HealthResponse} "Service is healthy"
 // @Router /health [get]
 // Health returns the health status of the service
 func (h *HealthHandler) Health(c *gin.Context) {
-
-	message := gin.H{
-		"status":  "healthy",
-		"service": "auth-service",
-	}
-	h.response.Success(c, http.StatusOK, message)
+	h.response.Success(c, http.StatusOK, response.HealthResponse{
+		Status:        "healthy",
+		Service:       "auth-service",
+		Version:       version.Get().Version,
+		UptimeSeconds: h.uptimeSeconds(),
+	})
 }
 
 // Ready
 // @Summary Service Readiness Check
-// @Description Returns whether the service is ready to accept requests (e.g., database connectivity)
+// @Description Checks every dependency and returns whether the service is ready to accept requests
 // @Tags Health
 // @Produce json
-// @Success 200 {object} object{status=string,service=string} "Service is ready"
+// @Success 200 {object} response.SuccessResponse{data=response.ReadinessResponse} "Service is ready"
+// @Failure 503 {object} response.SuccessResponse{data=response.ReadinessResponse} "A dependency is unavailable"
 // @Router /health/ready [get]
 // Ready returns the readiness status of the service
 func (h *HealthHandler) Ready(c *gin.Context) {
+	dependencies := make(map[string]response.DependencyStatus, len(h.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := h.checkDependency(c.Request.Context(), name, check)
+			mu.Lock()
+			dependencies[name] = status
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	result := response.ReadinessResponse{
+		Status:        "ready",
+		Service:       "auth-service",
+		Version:       version.Get().Version,
+		UptimeSeconds: h.uptimeSeconds(),
+		Dependencies:  dependencies,
+	}
+	statusCode := http.StatusOK
+	for _, dependency := range dependencies {
+		if dependency.Status != "up" {
+			result.Status = "not_ready"
+			statusCode = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	h.response.Success(c, statusCode, result)
+}
+
+// checkDependency runs check with a timeout. Failure causes are logged
+// rather than returned, since the probe is public.
+func (h *HealthHandler) checkDependency(ctx context.Context, name string, check DependencyCheck) response.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
 
-	message := gin.H{
-		"status":  "ready",
-		"service": "auth-service",
+	start := time.Now()
+	err := check(ctx)
+	status := response.DependencyStatus{
+		Status:    "up",
+		LatencyMs: time.Since(start).Milliseconds(),
 	}
-	h.response.Success(c, http.StatusOK, message)
+	if err == nil {
+		return status
+	}
+
+	h.logger.Warn("Readiness dependency check failed", "dependency", name, "error", err)
+	status.Status = "down"
+	status.Error = "unavailable"
+	if ctx.Err() == context.DeadlineExceeded {
+		status.Error = "timed out"
+	}
+	return status
+}
+
+func (h *HealthHandler) uptimeSeconds() int64 {
+	return int64(time.Since(h.startedAt).Seconds())
+}
+
+// Version
+// @Summary Build Version
+// @Description Returns the version, commit and build time of the running service
+// @Tags Health
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=response.VersionResponse} "Build information"
+// @Router /version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	info := version.Get()
+
+	h.response.Success(c, http.StatusOK, response.VersionResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+	})
 }
@@ -3,84 +3,63 @@ package handler
 import (
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/cookie"
 	dtoRequest "github.com/histopathai/auth-service/internal/api/http/dto/request"
 	dtoResponse "github.com/histopathai/auth-service/internal/api/http/dto/response"
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/service"
 	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/internal/shared/query"
 	"github.com/histopathai/auth-service/pkg/config"
 )
 
 func (h *SessionHandler) setSessionCookie(c *gin.Context, sessionID string, expiresAt time.Time) {
-	cookieCfg := h.config.Cookie
-	maxAge := int(time.Until(expiresAt).Seconds())
-
-	c.SetSameSite(h.getSameSiteMode(cookieCfg.SameSite))
-	c.SetCookie(
-		cookieCfg.Name,     // name
-		sessionID,          // value
-		maxAge,             // maxAge
-		"/",                // path
-		cookieCfg.Domain,   // domain
-		cookieCfg.Secure,   // secure (HTTPS only)
-		cookieCfg.HTTPOnly, // httpOnly
-	)
+	cookie.SetSession(c, &h.config.Cookie, sessionID, expiresAt)
 
 	h.logger.Debug("Session cookie set",
 		"environment", h.config.Server.Environment,
-		"secure", cookieCfg.Secure,
-		"sameSite", cookieCfg.SameSite,
-		"domain", cookieCfg.Domain,
+		"secure", h.config.Cookie.Secure,
+		"sameSite", h.config.Cookie.SameSite,
+		"domain", h.config.Cookie.Domain,
+		"partitioned", h.config.Cookie.Partitioned,
 	)
 }
 
 func (h *SessionHandler) clearSessionCookie(c *gin.Context) {
-	cookieCfg := h.config.Cookie
-
-	c.SetSameSite(h.getSameSiteMode(cookieCfg.SameSite))
-	c.SetCookie(
-		cookieCfg.Name,
-		"",
-		-1, // Delete immediately
-		"/",
-		cookieCfg.Domain,
-		cookieCfg.Secure,
-		cookieCfg.HTTPOnly,
-	)
+	cookie.ClearSession(c, &h.config.Cookie)
 }
 
-func (h *SessionHandler) getSameSiteMode(mode string) http.SameSite {
-	switch mode {
-	case "Strict":
-		return http.SameSiteStrictMode
-	case "None":
-		return http.SameSiteNoneMode
-	case "Lax":
-		fallthrough
-	default:
-		return http.SameSiteLaxMode
-	}
-}
+// SessionExpiresAtHeader tells the client when a cookie session expires
+const SessionExpiresAtHeader = "X-Session-Expires-At"
+
+// EmailVerificationDueHeader tells the client when a user who has not
+// verified their email will stop being able to sign in
+const EmailVerificationDueHeader = "X-Email-Verification-Due"
 
 type SessionHandler struct {
-	sessionService *service.SessionService
+	sessionService service.SessionService
 	authService    *service.AuthService
-	config         *config.Config
+	// locator adds locations to session listings; nil leaves them out
+	locator *service.SessionLocator
+	config  *config.Config
 	BaseHandler
 }
 
 func NewSessionHandler(
-	sessionService *service.SessionService,
+	sessionService service.SessionService,
 	authService *service.AuthService,
+	locator *service.SessionLocator,
 	config *config.Config,
 	logger *slog.Logger,
 ) *SessionHandler {
 	return &SessionHandler{
 		sessionService: sessionService,
 		authService:    authService,
+		locator:        locator,
 		config:         config,
 		BaseHandler:    BaseHandler{logger: logger, response: &ResponseHelper{}},
 	}
@@ -88,14 +67,19 @@ func NewSessionHandler(
 
 // CreateSession
 // @Summary Create Session
-// @Description Create a new session with authentication token
+// @Description Create a new session with authentication token. Default-scope sessions are returned as a cookie; other scopes (e.g. image-serve, admin-ops) are returned in the body for explicit use.
 // @Tags Session
 // @Accept json
 // @Produce json
-// @Param payload body request.CreateSessionRequest true "Authentication token"
+// @Param payload body request.CreateSessionRequest true "Authentication token and optional scope"
+// @Success 201 {object} response.SuccessResponse{data=response.CreateSessionResponse} "Scoped session created successfully"
+// @Header 201 {string} Location "URL of the created session"
 // @Success 204 "Session created successfully"
-// @Failure 400 {object} response.ErrorResponse "Invalid request"
+// @Header 204 {string} X-Session-Expires-At "Expiry of the session cookie (RFC 3339)"
+// @Failure 400 {object} response.ErrorResponse "Invalid request or unknown scope"
 // @Failure 401 {object} response.ErrorResponse "Invalid token"
+// @Failure 403 {object} response.ErrorResponse "Account not active, or role or token not allowed for scope"
+// @Failure 404 {object} response.ErrorResponse "No user profile for the token's account"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /sessions [put]
 func (h *SessionHandler) CreateSession(c *gin.Context) {
@@ -105,8 +89,8 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		return
 	}
 
-	// Verify token and get user
-	user, err := h.authService.VerifyToken(c.Request.Context(), req.Token)
+	// Verify token and check the account may sign in
+	user, authInfo, err := h.authService.Login(c.Request.Context(), req.Token)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -116,6 +100,7 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		"user_id", user.UserID,
 		"email", user.Email,
 		"display_name", user.DisplayName,
+		"scope", req.Scope,
 	)
 
 	if user.UserID == "" {
@@ -126,22 +111,38 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		return
 	}
 
+	if deadline, ok := h.authService.EmailVerificationDeadline(user, authInfo.EmailVerified); ok {
+		c.Header(EmailVerificationDueHeader, deadline.UTC().Format(time.RFC3339))
+	}
+
 	// Create session
-	sessionID, err := h.sessionService.CreateSession(c.Request.Context(), user.UserID)
+	session, err := h.sessionService.CreateSession(c.Request.Context(), user, service.CreateSessionOptions{
+		Scope:       req.Scope,
+		UserAgent:   c.Request.UserAgent(),
+		IPAddress:   c.ClientIP(),
+		TokenClaims: authInfo.Claims,
+		RememberMe:  req.RememberMe,
+	})
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	// Get created session details
-	session, err := h.sessionService.ValidateSession(c.Request.Context(), sessionID)
-	if err != nil {
-		h.handleError(c, err)
+	// Scoped sessions are handed to the client explicitly so they do not
+	// replace the browser's default session cookie
+	if session.ScopeOrDefault() != service.ScopeDefault {
+		response := dtoResponse.CreateSessionResponse{
+			ExpiresAt: session.ExpiresAt,
+			Message:   "Session created successfully",
+			Session:   mapToSessionResponse(session),
+		}
+		h.response.Created(c, response, "/api/v1/sessions/"+url.PathEscape(session.SessionID))
 		return
 	}
 
 	// Set cookie with environment-aware configuration
-	h.setSessionCookie(c, sessionID, session.ExpiresAt)
+	h.setSessionCookie(c, session.SessionID, session.ExpiresAt)
+	c.Header(SessionExpiresAtHeader, session.ExpiresAt.UTC().Format(time.RFC3339))
 
 	h.response.NoContent(c)
 }
@@ -159,7 +160,7 @@ func (h *SessionHandler) Logout(c *gin.Context) {
 	sessionID, err := c.Cookie(h.config.Cookie.Name)
 	if err == nil && sessionID != "" {
 		// 2. Try to revoke session (ignore errors)
-		_ = h.sessionService.RevokeSession(c.Request.Context(), sessionID)
+		_, _ = h.sessionService.RevokeSession(c.Request.Context(), sessionID)
 	}
 
 	// 3. Always clear cookie
@@ -176,7 +177,7 @@ func (h *SessionHandler) Logout(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} response.SessionResponse "Current session retrieved successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.SessionResponse} "Current session retrieved successfully"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /sessions/current [get]
@@ -202,6 +203,37 @@ func (h *SessionHandler) GetCurrentSession(c *gin.Context) {
 	h.response.Success(c, http.StatusOK, response)
 }
 
+// RefreshProfile
+// @Summary Refresh Session Profile
+// @Description Reload the authenticated user's profile on every replica so that a recent role or status change applies to the current session without signing in again. Allowed for inactive accounts so that a reactivation can be picked up.
+// @Tags Session
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.SuccessResponse{data=response.ProfileResponse} "Profile refreshed successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /sessions/refresh-profile [post]
+func (h *SessionHandler) RefreshProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	user, err := h.authService.RefreshUser(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dtoResponse.ProfileResponse{
+		User: mapToUserResponse(user),
+	}
+
+	h.response.Success(c, http.StatusOK, response)
+}
+
 // ListMySessions
 // @Summary List My Sessions
 // @Description Get list of authenticated user's active sessions
@@ -209,7 +241,10 @@ func (h *SessionHandler) GetCurrentSession(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} response.SessionListResponse "Sessions retrieved successfully"
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Param offset query int false "Items to skip" default(0) minimum(0)
+// @Success 200 {object} response.ListResponse{data=response.SessionListResponse} "Sessions retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /sessions [get]
@@ -220,29 +255,53 @@ func (h *SessionHandler) ListMySessions(c *gin.Context) {
 		return
 	}
 
-	sessions, err := h.sessionService.GetUserSessionStats(c.Request.Context(), userID.(string))
+	h.listSessions(c, userID.(string))
+}
+
+// ListAllMySessions
+// @Summary List My Sessions By Scope
+// @Description Get the authenticated user's sessions grouped by scope, with each scope's expiry policy
+// @Tags Session
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.SuccessResponse{data=response.AllSessionsResponse} "Sessions retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /sessions/all [get]
+func (h *SessionHandler) ListAllMySessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	stats, err := h.sessionService.GetUserSessionsByScope(c.Request.Context(), userID.(string))
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	// Convert to response format
-	sessionList := sessions["sessions"].([]map[string]interface{})
-	responseSessions := make([]dtoResponse.SessionResponse, 0, len(sessionList))
-
-	for _, s := range sessionList {
-		responseSessions = append(responseSessions, dtoResponse.SessionResponse{
-			SessionID:    s["session_id"].(string),
-			CreatedAt:    s["created_at"].(time.Time),
-			ExpiresAt:    s["expires_at"].(time.Time),
-			LastUsedAt:   s["last_used"].(time.Time),
-			RequestCount: s["request_count"].(int64),
+	scopes := make([]dtoResponse.ScopeSessionsResponse, 0, len(stats.Scopes))
+	for _, scope := range stats.Scopes {
+		list := mapToSessionListResponse(&model.SessionStats{
+			ActiveSessions: scope.ActiveSessions,
+			Sessions:       scope.Sessions,
+		})
+		h.addLocations(c, list.Sessions)
+		scopes = append(scopes, dtoResponse.ScopeSessionsResponse{
+			Scope:              scope.Scope,
+			ExpirationSeconds:  int64(scope.Expiration / time.Second),
+			MaxSessionsPerUser: scope.MaxSessionsPerUser,
+			ActiveSessions:     scope.ActiveSessions,
+			Sessions:           list.Sessions,
 		})
 	}
 
-	response := dtoResponse.SessionListResponse{
-		ActiveSessions: sessions["active_sessions"].(int),
-		Sessions:       responseSessions,
+	response := dtoResponse.AllSessionsResponse{
+		ActiveSessions: stats.ActiveSessions,
+		MaxSessions:    stats.MaxSessions,
+		Scopes:         scopes,
 	}
 
 	h.response.Success(c, http.StatusOK, response)
@@ -255,7 +314,7 @@ func (h *SessionHandler) ListMySessions(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} response.SessionStatsResponse "Session statistics retrieved successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.SessionStatsResponse} "Session statistics retrieved successfully"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /sessions/stats [get]
@@ -273,32 +332,37 @@ func (h *SessionHandler) GetMySessionStats(c *gin.Context) {
 	}
 
 	// Convert to detailed response format
-	sessionList := stats["sessions"].([]map[string]interface{})
-	detailedSessions := make([]dtoResponse.SessionDetailedStats, 0, len(sessionList))
+	detailedSessions := make([]dtoResponse.SessionDetailedStats, 0, len(stats.Sessions))
 	var totalRequests int64
 
-	for _, s := range sessionList {
-		expiresAt := s["expires_at"].(time.Time)
-		timeLeft := time.Until(expiresAt)
-		requestCount := s["request_count"].(int64)
-		totalRequests += requestCount
+	for _, s := range stats.Sessions {
+		totalRequests += s.RequestCount
 
 		detailedSessions = append(detailedSessions, dtoResponse.SessionDetailedStats{
-			SessionID:    s["session_id"].(string),
-			CreatedAt:    s["created_at"].(time.Time),
-			ExpiresAt:    expiresAt,
-			LastUsedAt:   s["last_used"].(time.Time),
-			RequestCount: requestCount,
-			TimeLeft:     timeLeft.Round(time.Second).String(),
+			SessionID:    s.SessionID,
+			Scope:        s.Scope,
+			CreatedAt:    s.CreatedAt,
+			ExpiresAt:    s.ExpiresAt,
+			LastUsedAt:   s.LastUsedAt,
+			RequestCount: s.RequestCount,
+			TimeLeft:     time.Until(s.ExpiresAt).Round(time.Second).String(),
+			Metadata:     s.Metadata,
 		})
 	}
 
+	// A user without sessions averages zero; dividing would yield NaN, which
+	// cannot be encoded as JSON
+	var averageRequests float64
+	if len(detailedSessions) > 0 {
+		averageRequests = float64(totalRequests) / float64(len(detailedSessions))
+	}
+
 	response := dtoResponse.SessionStatsResponse{
-		ActiveSessions: stats["active_sessions"].(int),
+		ActiveSessions: stats.ActiveSessions,
 		TotalRequests:  totalRequests,
 		Sessions:       detailedSessions,
 		Summary: map[string]interface{}{
-			"average_requests_per_session": float64(totalRequests) / float64(len(detailedSessions)),
+			"average_requests_per_session": averageRequests,
 		},
 	}
 
@@ -359,7 +423,7 @@ func (h *SessionHandler) ExtendSession(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {object} response.RevokeSessionResponse "Session revoked successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.RevokeSessionResponse} "Session revoked successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid session ID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 404 {object} response.ErrorResponse "Session not found"
@@ -389,7 +453,7 @@ func (h *SessionHandler) RevokeSession(c *gin.Context) {
 		return
 	}
 
-	if err := h.sessionService.RevokeSession(c.Request.Context(), sessionID); err != nil {
+	if _, err := h.sessionService.RevokeSession(c.Request.Context(), sessionID); err != nil {
 		h.handleError(c, err)
 		return
 	}
@@ -411,7 +475,7 @@ func (h *SessionHandler) RevokeSession(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Success 204 "All sessions revoked successfully"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
-// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Failure 500 {object} response.ErrorResponse "Internal server error or some sessions could not be revoked"
 // @Router /sessions/revoke-all [put]
 func (h *SessionHandler) RevokeAllMySessions(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -420,15 +484,17 @@ func (h *SessionHandler) RevokeAllMySessions(c *gin.Context) {
 		return
 	}
 
-	// Get session count before revoking
-	_, err := h.sessionService.GetActiveSessionCount(c.Request.Context(), userID.(string))
+	result, err := h.sessionService.RevokeAllUserSessions(c.Request.Context(), userID.(string))
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
-
-	// Revoke all sessions
-	if err := h.sessionService.RevokeAllUserSessions(c.Request.Context(), userID.(string)); err != nil {
+	if len(result.Failed) > 0 {
+		err := errors.NewInternalError("some sessions could not be revoked", nil)
+		err.Details = map[string]interface{}{
+			"revoked_sessions": result.Revoked,
+			"failed_count":     len(result.Failed),
+		}
 		h.handleError(c, err)
 		return
 	}
@@ -446,8 +512,10 @@ func (h *SessionHandler) RevokeAllMySessions(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.SessionListResponse "Sessions retrieved successfully"
-// @Failure 400 {object} response.ErrorResponse "Invalid UserID"
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Param offset query int false "Items to skip" default(0) minimum(0)
+// @Success 200 {object} response.ListResponse{data=response.SessionListResponse} "Sessions retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid UserID or query parameters"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
@@ -459,32 +527,34 @@ func (h *SessionHandler) ListUserSessions(c *gin.Context) {
 		return
 	}
 
-	sessions, err := h.sessionService.GetUserSessionStats(c.Request.Context(), userID)
-	if err != nil {
-		h.handleError(c, err)
-		return
-	}
-
-	// Convert to response format
-	sessionList := sessions["sessions"].([]map[string]interface{})
-	responseSessions := make([]dtoResponse.SessionResponse, 0, len(sessionList))
+	h.listSessions(c, userID)
+}
 
-	for _, s := range sessionList {
-		responseSessions = append(responseSessions, dtoResponse.SessionResponse{
-			SessionID:    s["session_id"].(string),
-			CreatedAt:    s["created_at"].(time.Time),
-			ExpiresAt:    s["expires_at"].(time.Time),
-			LastUsedAt:   s["last_used"].(time.Time),
-			RequestCount: s["request_count"].(int64),
-		})
+// listSessions writes one page of a user's sessions
+func (h *SessionHandler) listSessions(c *gin.Context, userID string) {
+	var req dtoRequest.ListSessionsRequest
+	if details := bindQuery(c, &req); details != nil {
+		h.handleError(c, errors.NewValidationError("Invalid query parameters", details))
+		return
 	}
+	req.ApplyDefaults()
 
-	response := dtoResponse.SessionListResponse{
-		ActiveSessions: sessions["active_sessions"].(int),
-		Sessions:       responseSessions,
+	stats, err := h.sessionService.ListUserSessions(c.Request.Context(), userID, &query.Pagination{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
 	}
 
-	h.response.Success(c, http.StatusOK, response)
+	list := mapToSessionListResponse(stats)
+	h.addLocations(c, list.Sessions)
+	h.response.SuccessList(c, list, dtoResponse.PaginationResponse{
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+		HasMore: req.Offset+len(stats.Sessions) < stats.ActiveSessions,
+	})
 }
 
 // RevokeUserSession (Admin)
@@ -495,7 +565,7 @@ func (h *SessionHandler) ListUserSessions(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param session_id path string true "Session ID"
-// @Success 200 {object} response.RevokeSessionResponse "Session revoked successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.RevokeSessionResponse} "Session revoked successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid session ID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
@@ -509,14 +579,28 @@ func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
 		return
 	}
 
-	// Admin can revoke any session without validation
-	if err := h.sessionService.RevokeSession(c.Request.Context(), sessionID); err != nil {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		h.handleError(c, errors.NewUnauthorizedError("User not authenticated"))
+		return
+	}
+
+	// Admin can revoke any user's session, including expired ones
+	session, err := h.sessionService.RevokeSession(c.Request.Context(), sessionID)
+	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
+	h.logger.Info("Session revoked by admin",
+		"admin_id", adminID,
+		"session_id", sessionID[:min(8, len(sessionID))],
+		"user_id", session.UserID,
+	)
+
 	response := dtoResponse.RevokeSessionResponse{
 		Message: "Session revoked successfully",
+		UserID:  session.UserID,
 	}
 
 	h.response.Success(c, http.StatusOK, response)
@@ -524,13 +608,13 @@ func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
 
 // RevokeAllUserSessions (Admin)
 // @Summary Revoke All User Sessions (Admin)
-// @Description Revoke all sessions of a specific user (Admin only)
+// @Description Revoke all sessions of a specific user (Admin only). Sessions that could not be revoked are listed with a reason and should be retried.
 // @Tags Admin - Sessions
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param user_id path string true "User UserID"
-// @Success 200 {object} response.RevokeAllSessionsResponse "All user sessions revoked successfully"
+// @Success 200 {object} response.SuccessResponse{data=response.RevokeAllSessionsResponse} "All user sessions revoked successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid UserID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
@@ -543,28 +627,117 @@ func (h *SessionHandler) RevokeAllUserSessions(c *gin.Context) {
 		return
 	}
 
-	// Get session count before revoking
-	count, _ := h.sessionService.GetActiveSessionCount(c.Request.Context(), userID)
-
-	// Revoke all user sessions
-	if err := h.sessionService.RevokeAllUserSessions(c.Request.Context(), userID); err != nil {
+	result, err := h.sessionService.RevokeAllUserSessions(c.Request.Context(), userID)
+	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
+	message := "All user sessions revoked successfully"
+	if len(result.Failed) > 0 {
+		message = "Some user sessions could not be revoked"
+		h.logger.Warn("Admin revoke-all left sessions in place",
+			"user_id", userID,
+			"admin_id", c.GetString("user_id"),
+			"revoked", result.Revoked,
+			"failed", len(result.Failed),
+		)
+	}
+
 	response := dtoResponse.RevokeAllSessionsResponse{
-		Message:         "All user sessions revoked successfully",
-		RevokedSessions: count,
+		Message:         message,
+		RevokedSessions: result.Revoked,
+		FailedCount:     len(result.Failed),
+	}
+	if len(result.Failed) > 0 {
+		response.FailedSessions = result.FailedIDs()
+		for _, failure := range result.Failed {
+			response.Failures = append(response.Failures, dtoResponse.SessionRevocationFailureResponse{
+				SessionID: failure.SessionID,
+				Reason:    failure.Reason,
+			})
+		}
 	}
 
 	h.response.Success(c, http.StatusOK, response)
 }
 
+// UpdateSessionMetadata
+// @Summary Update Session Metadata
+// @Description Merge key/values into a session's metadata. Null values remove keys; reserved keys cannot be set (internal services only)
+// @Tags Internal
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param session_id path string true "Session ID"
+// @Param payload body request.UpdateSessionMetadataRequest true "Owning user and metadata to merge"
+// @Success 200 {object} response.SuccessResponse{data=response.SessionResponse} "Session metadata updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid, reserved or oversized metadata"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Session belongs to another user or service not allowed"
+// @Failure 404 {object} response.ErrorResponse "Session not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /internal/sessions/{session_id}/metadata [patch]
+func (h *SessionHandler) UpdateSessionMetadata(c *gin.Context) {
+	sessionID := c.Param("session_id")
+	if sessionID == "" {
+		h.handleError(c, errors.NewValidationError("Session ID is required", nil))
+		return
+	}
+
+	var req dtoRequest.UpdateSessionMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleError(c, errors.NewValidationError("Invalid request payload", nil))
+		return
+	}
+
+	session, err := h.sessionService.MergeSessionMetadata(c.Request.Context(), sessionID, req.UserID, req.Metadata)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.response.Success(c, http.StatusOK, mapToSessionResponse(session))
+}
+
+// addLocations fills in the approximate location of listed sessions when
+// geolocation is configured
+func (h *SessionHandler) addLocations(c *gin.Context, sessions []dtoResponse.SessionResponse) {
+	if h.locator == nil {
+		return
+	}
+	for i := range sessions {
+		sessions[i].Location = h.locator.Locate(c.Request.Context(), sessions[i].Metadata)
+	}
+}
+
+// Helper function to map session statistics to a list response
+func mapToSessionListResponse(stats *model.SessionStats) dtoResponse.SessionListResponse {
+	sessions := make([]dtoResponse.SessionResponse, 0, len(stats.Sessions))
+	for _, s := range stats.Sessions {
+		sessions = append(sessions, dtoResponse.SessionResponse{
+			SessionID:    s.SessionID,
+			Scope:        s.Scope,
+			CreatedAt:    s.CreatedAt,
+			ExpiresAt:    s.ExpiresAt,
+			LastUsedAt:   s.LastUsedAt,
+			RequestCount: s.RequestCount,
+			Metadata:     s.Metadata,
+		})
+	}
+
+	return dtoResponse.SessionListResponse{
+		ActiveSessions: stats.ActiveSessions,
+		Sessions:       sessions,
+	}
+}
+
 // Helper function to map session model to response
 func mapToSessionResponse(session *model.Session) dtoResponse.SessionResponse {
 	return dtoResponse.SessionResponse{
 		SessionID:    session.SessionID,
 		UserID:       session.UserID,
+		Scope:        session.ScopeOrDefault(),
 		CreatedAt:    session.CreatedAt,
 		ExpiresAt:    session.ExpiresAt,
 		LastUsedAt:   session.LastUsedAt,
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/csv"
+	stderr "errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// maxImportFileBytes caps the size of an uploaded import file
+const maxImportFileBytes = 5 << 20
+
+// requiredImportColumns must appear in an import's header; display_name is
+// optional
+var requiredImportColumns = []string{"email", "role", "status"}
+
+// parseUserImportCSV reads import rows from CSV with a header row naming the
+// columns in any order. Values are trimmed; role and status are lowercased.
+func parseUserImportCSV(r io.Reader) ([]*model.UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if stderr.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		index[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("header is missing the %q column", name)
+		}
+	}
+
+	var rows []*model.UserImportRow
+	for {
+		record, err := reader.Read()
+		if stderr.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		rows = append(rows, &model.UserImportRow{
+			Line:        line,
+			Email:       field("email"),
+			DisplayName: field("display_name"),
+			Role:        model.UserRole(strings.ToLower(field("role"))),
+			Status:      model.UserStatus(strings.ToLower(field("status"))),
+		})
+	}
+
+	return rows, nil
+}
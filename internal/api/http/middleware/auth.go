@@ -1,26 +1,36 @@
 package middleware
 
 import (
+	stderrors "errors"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/cookie"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/service"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
 	"github.com/histopathai/auth-service/pkg/config"
 )
 
+var errNoSessionCookie = stderrors.New("no session cookie")
+
+// SessionExpiringHeader signals that the session is inside its expiry grace
+// period and the client should re-authenticate.
+const SessionExpiringHeader = "X-Session-Expiring"
+
 type AuthMiddleware struct {
 	authService    service.AuthService
-	sessionService *service.SessionService
+	sessionService service.SessionService
 	config         *config.Config
 	logger         *slog.Logger
 }
 
 func NewAuthMiddleware(
 	authService service.AuthService,
-	sessionService *service.SessionService,
+	sessionService service.SessionService,
 	config *config.Config,
 	logger *slog.Logger,
 ) *AuthMiddleware {
@@ -41,33 +51,16 @@ func (m *AuthMiddleware) setUserContext(c *gin.Context, user *model.User, authMe
 
 // respondUnauthorized sends a standardized unauthorized response
 func respondUnauthorized(c *gin.Context, errorCode, message string, details map[string]interface{}) {
-	response := gin.H{
-		"error":   errorCode,
-		"message": message,
-	}
+	var responseDetails interface{}
 	if details != nil {
-		response["details"] = details
+		responseDetails = details
 	}
-	c.JSON(http.StatusUnauthorized, response)
-	c.Abort()
+	respond.AbortWithError(c, http.StatusUnauthorized, errorCode, message, responseDetails)
 }
 
 // respondForbidden sends a standardized forbidden response
 func respondForbidden(c *gin.Context, errorCode, message string) {
-	c.JSON(http.StatusForbidden, gin.H{
-		"error":   errorCode,
-		"message": message,
-	})
-	c.Abort()
-}
-
-// respondInternalError sends a standardized internal error response
-func respondInternalError(c *gin.Context, errorCode, message string) {
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error":   errorCode,
-		"message": message,
-	})
-	c.Abort()
+	respond.AbortWithError(c, http.StatusForbidden, errorCode, message, nil)
 }
 
 // getUserFromContext retrieves and validates user from context
@@ -95,15 +88,34 @@ func (m *AuthMiddleware) authenticateWithBearer(c *gin.Context, authHeader strin
 	return m.authService.VerifyToken(c.Request.Context(), tokenParts[1])
 }
 
-// authenticateWithSession attempts to authenticate using session cookie
+// bearerUser returns the user of the request's bearer token, if it carries
+// a valid one
+func (m *AuthMiddleware) bearerUser(c *gin.Context) (*model.User, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, false
+	}
+	user, err := m.authenticateWithBearer(c, authHeader)
+	if err != nil || user == nil {
+		return nil, false
+	}
+	return user, true
+}
+
+// authenticateWithSession attempts to authenticate using session cookie.
+// A cookie naming an unknown or expired session is cleared so the browser
+// stops resending it; a missing cookie returns errNoSessionCookie.
 func (m *AuthMiddleware) authenticateWithSession(c *gin.Context) (*model.User, string, error) {
 	sessionID, err := c.Cookie(m.config.Cookie.Name)
 	if err != nil || sessionID == "" {
-		return nil, "", err
+		return nil, "", errNoSessionCookie
 	}
 
 	session, err := m.sessionService.ValidateSession(c.Request.Context(), sessionID)
 	if err != nil {
+		if sharedErrors.IsType(err, sharedErrors.ErrorTypeNotFound) {
+			cookie.ClearSession(c, &m.config.Cookie)
+		}
 		return nil, "", err
 	}
 
@@ -112,6 +124,10 @@ func (m *AuthMiddleware) authenticateWithSession(c *gin.Context) (*model.User, s
 		return nil, "", err
 	}
 
+	if m.sessionService.IsInGracePeriod(session) {
+		c.Header(SessionExpiringHeader, "true")
+	}
+
 	return user, sessionID, nil
 }
 
@@ -126,7 +142,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 
 		user, err := m.authenticateWithBearer(c, authHeader)
 		if err != nil {
-			respondUnauthorized(c, "invalid_token", "Token verification failed", nil)
+			RejectAuthFailure(c, err, "invalid_token", "Token verification failed", nil)
 			return
 		}
 
@@ -145,7 +161,7 @@ func (m *AuthMiddleware) RequireSession() gin.HandlerFunc {
 				"path", c.Request.URL.Path,
 				"ip", c.ClientIP(),
 			)
-			respondUnauthorized(c, "invalid_session", "Session is invalid or expired", nil)
+			RejectAuthFailure(c, err, "invalid_session", "Session is invalid or expired", nil)
 			return
 		}
 
@@ -159,15 +175,25 @@ func (m *AuthMiddleware) RequireSession() gin.HandlerFunc {
 func (m *AuthMiddleware) RequireAuthOrSession() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		details := make(map[string]interface{})
+		// An account problem found with either credential outranks the other
+		// credential being absent or invalid
+		var identifiedErr error
 
 		// Try session authentication first
 		if user, sessionID, err := m.authenticateWithSession(c); err == nil {
+			if bearerUser, ok := m.bearerUser(c); ok && bearerUser.UserID != user.UserID {
+				RejectConflictingCredentials(c, m.logger, user.UserID, bearerUser.UserID)
+				return
+			}
 			m.setUserContext(c, user, "session")
 			c.Set("session_id", sessionID)
 			c.Next()
 			return
 		} else if err != nil {
 			details["session_error"] = err.Error()
+			if IdentifiedError(err) != nil {
+				identifiedErr = err
+			}
 		}
 
 		// Try bearer token authentication
@@ -178,12 +204,15 @@ func (m *AuthMiddleware) RequireAuthOrSession() gin.HandlerFunc {
 				return
 			} else if err != nil {
 				details["bearer_error"] = err.Error()
+				if IdentifiedError(err) != nil {
+					identifiedErr = err
+				}
 			}
 		} else {
 			details["bearer_error"] = "Authorization header not provided"
 		}
 
-		respondUnauthorized(c, "unauthorized", "Valid session cookie or Bearer token required", details)
+		RejectAuthFailure(c, identifiedErr, "unauthorized", "Valid session cookie or Bearer token required", details)
 	}
 }
 
@@ -210,7 +239,9 @@ func (m *AuthMiddleware) RequireRole(roles ...model.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, ok := getUserFromContext(c)
 		if !ok {
-			respondInternalError(c, "invalid_user_context", "User context is invalid")
+			// Without an authenticated user there is no role or status to
+			// check; the caller must authenticate first
+			respondUnauthorized(c, "authentication_required", "Authentication is required", nil)
 			return
 		}
 
@@ -230,7 +261,9 @@ func (m *AuthMiddleware) RequireStatus(statuses ...model.UserStatus) gin.Handler
 	return func(c *gin.Context) {
 		user, ok := getUserFromContext(c)
 		if !ok {
-			respondInternalError(c, "invalid_user_context", "User context is invalid")
+			// Without an authenticated user there is no role or status to
+			// check; the caller must authenticate first
+			respondUnauthorized(c, "authentication_required", "Authentication is required", nil)
 			return
 		}
 
@@ -241,6 +274,10 @@ func (m *AuthMiddleware) RequireStatus(statuses ...model.UserStatus) gin.Handler
 			}
 		}
 
+		if statusErr := m.authService.AccountStatusError(user.Status); statusErr != nil {
+			respond.AbortWithError(c, http.StatusForbidden, string(statusErr.Type), statusErr.Message, statusErr.Details)
+			return
+		}
 		respondForbidden(c, "account_status_invalid", "Your account status doesn't allow this operation")
 	}
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// identifiedErrorTypes are failures raised after the caller's identity was
+// established: the credentials are valid, but the account may not be used.
+var identifiedErrorTypes = map[sharedErrors.ErrorType]bool{
+	sharedErrors.ErrorTypeForbidden:              true,
+	sharedErrors.ErrorTypeAccountPendingApproval: true,
+	sharedErrors.ErrorTypeAccountSuspended:       true,
+	sharedErrors.ErrorTypeAccountRejected:        true,
+	sharedErrors.ErrorTypeUserProfileNotFound:    true,
+	sharedErrors.ErrorTypeEmailNotVerified:       true,
+}
+
+// IdentifiedError returns the error of a caller whose identity is known but
+// who may not proceed, or nil when err means the caller is not
+// authenticated at all
+func IdentifiedError(err error) *sharedErrors.Err {
+	var customErr *sharedErrors.Err
+	if stderrors.As(err, &customErr) && identifiedErrorTypes[customErr.Type] {
+		return customErr
+	}
+	return nil
+}
+
+// RejectAuthFailure aborts a request whose authentication failed with err.
+// Responses follow one policy across middleware and proxies: 401 when the
+// caller could not be authenticated (missing, malformed, invalid or expired
+// credentials), 403 when the caller is known but their role or account
+// status does not allow the request. errorCode and message describe the 401.
+func RejectAuthFailure(c *gin.Context, err error, errorCode, message string, details map[string]interface{}) {
+	if identified := IdentifiedError(err); identified != nil {
+		respond.AbortWithError(c, http.StatusForbidden, string(identified.Type), identified.Message, identified.Details)
+		return
+	}
+	respondUnauthorized(c, errorCode, message, details)
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/domain/repository"
+	"github.com/histopathai/auth-service/internal/service"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/pkg/config"
+)
+
+// fakeAuthRepo verifies ID tokens from a fixed map. Methods the middleware
+// does not reach panic through the nil embedded interface.
+type fakeAuthRepo struct {
+	repository.AuthRepository
+	tokens map[string]string
+}
+
+func (r *fakeAuthRepo) VerifyIDToken(ctx context.Context, idToken string) (*model.UserAuthInfo, error) {
+	userID, ok := r.tokens[idToken]
+	if !ok {
+		return nil, sharedErrors.NewUnauthorizedError("invalid ID token")
+	}
+	return &model.UserAuthInfo{UserID: userID, EmailVerified: true}, nil
+}
+
+// fakeUserRepo serves user profiles from a fixed map
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[string]*model.User
+}
+
+func (r *fakeUserRepo) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, sharedErrors.NewNotFoundError("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// fakeSessionService serves sessions from a fixed map
+type fakeSessionService struct {
+	service.SessionService
+	sessions map[string]string
+}
+
+func (f *fakeSessionService) ValidateSession(ctx context.Context, sessionID string) (*model.Session, error) {
+	userID, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, sharedErrors.NewNotFoundError("session_not_found")
+	}
+	return &model.Session{SessionID: sessionID, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (f *fakeSessionService) IsInGracePeriod(session *model.Session) bool {
+	return false
+}
+
+func newTestAuthMiddleware() *AuthMiddleware {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	users := map[string]*model.User{
+		"user":      {UserID: "user", Role: model.RoleUser, Status: model.StatusActive},
+		"admin":     {UserID: "admin", Role: model.RoleAdmin, Status: model.StatusActive},
+		"suspended": {UserID: "suspended", Role: model.RoleUser, Status: model.StatusSuspended},
+	}
+	authRepo := &fakeAuthRepo{tokens: map[string]string{
+		"user-token":       "user",
+		"admin-token":      "admin",
+		"suspended-token":  "suspended",
+		"no-profile-token": "no-profile",
+	}}
+	sessions := &fakeSessionService{sessions: map[string]string{
+		"user-session":      "user",
+		"suspended-session": "suspended",
+	}}
+
+	authService := service.NewAuthService(authRepo, &fakeUserRepo{users: users}, nil, nil, nil, nil, nil, service.AuthConfig{}, logger)
+	cfg := &config.Config{Cookie: config.CookieConfig{Name: "session"}}
+	return NewAuthMiddleware(*authService, sessions, cfg, logger)
+}
+
+func TestAuthMiddlewareStatusCodes(t *testing.T) {
+	m := newTestAuthMiddleware()
+	active := []model.UserStatus{model.StatusActive}
+
+	tests := []struct {
+		name       string
+		chain      []gin.HandlerFunc
+		cookie     string
+		bearer     string
+		wantStatus int
+		wantError  string
+	}{
+		// 401: the caller could not be authenticated
+		{name: "RequireAuth without a token", chain: []gin.HandlerFunc{m.RequireAuth()}, wantStatus: http.StatusUnauthorized, wantError: "missing_authorization_header"},
+		{name: "RequireAuth with a malformed header", chain: []gin.HandlerFunc{m.RequireAuth()}, bearer: "Basic dXNlcg==", wantStatus: http.StatusUnauthorized, wantError: "invalid_token"},
+		{name: "RequireAuth with an invalid token", chain: []gin.HandlerFunc{m.RequireAuth()}, bearer: "Bearer forged-token", wantStatus: http.StatusUnauthorized, wantError: "invalid_token"},
+		{name: "RequireSession without a cookie", chain: []gin.HandlerFunc{m.RequireSession()}, wantStatus: http.StatusUnauthorized, wantError: "invalid_session"},
+		{name: "RequireSession with an unknown session", chain: []gin.HandlerFunc{m.RequireSession()}, cookie: "forged-session", wantStatus: http.StatusUnauthorized, wantError: "invalid_session"},
+		{name: "RequireAuthOrSession without credentials", chain: []gin.HandlerFunc{m.RequireAuthOrSession()}, wantStatus: http.StatusUnauthorized, wantError: "unauthorized"},
+		{name: "RequireAuthOrSession with invalid credentials", chain: []gin.HandlerFunc{m.RequireAuthOrSession()}, cookie: "forged-session", bearer: "Bearer forged-token", wantStatus: http.StatusUnauthorized, wantError: "unauthorized"},
+		{name: "RequireRole before authentication", chain: []gin.HandlerFunc{m.RequireRole(model.RoleAdmin)}, wantStatus: http.StatusUnauthorized, wantError: "authentication_required"},
+		{name: "RequireStatus before authentication", chain: []gin.HandlerFunc{m.RequireStatus(active...)}, wantStatus: http.StatusUnauthorized, wantError: "authentication_required"},
+
+		// 403: the caller is known but may not proceed
+		{name: "RequireAuth with a token but no profile", chain: []gin.HandlerFunc{m.RequireAuth()}, bearer: "Bearer no-profile-token", wantStatus: http.StatusForbidden, wantError: string(sharedErrors.ErrorTypeUserProfileNotFound)},
+		{name: "RequireAuthOrSession with a token but no profile", chain: []gin.HandlerFunc{m.RequireAuthOrSession()}, cookie: "forged-session", bearer: "Bearer no-profile-token", wantStatus: http.StatusForbidden, wantError: string(sharedErrors.ErrorTypeUserProfileNotFound)},
+		{name: "RequireRole with another role", chain: []gin.HandlerFunc{m.RequireAuth(), m.RequireRole(model.RoleAdmin)}, bearer: "Bearer user-token", wantStatus: http.StatusForbidden, wantError: "insufficient_permissions"},
+		{name: "RequireStatus with a suspended token", chain: []gin.HandlerFunc{m.RequireAuth(), m.RequireStatus(active...)}, bearer: "Bearer suspended-token", wantStatus: http.StatusForbidden, wantError: string(sharedErrors.ErrorTypeAccountSuspended)},
+		{name: "RequireStatus with a suspended session", chain: []gin.HandlerFunc{m.RequireSession(), m.RequireStatus(active...)}, cookie: "suspended-session", wantStatus: http.StatusForbidden, wantError: string(sharedErrors.ErrorTypeAccountSuspended)},
+		{name: "RequireStatus with either suspended credential", chain: []gin.HandlerFunc{m.RequireAuthOrSession(), m.RequireStatus(active...)}, bearer: "Bearer suspended-token", wantStatus: http.StatusForbidden, wantError: string(sharedErrors.ErrorTypeAccountSuspended)},
+
+		{name: "RequireRole with the role", chain: []gin.HandlerFunc{m.RequireAuth(), m.RequireRole(model.RoleAdmin)}, bearer: "Bearer admin-token", wantStatus: http.StatusOK},
+		{name: "RequireStatus with an active session", chain: []gin.HandlerFunc{m.RequireSession(), m.RequireStatus(active...)}, cookie: "user-session", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			handlers := append(tt.chain, func(c *gin.Context) { c.Status(http.StatusOK) })
+			engine.GET("/", handlers...)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", tt.bearer)
+			}
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tt.wantError == "" {
+				return
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %s", recorder.Body)
+			}
+			if body.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", body.Error, tt.wantError)
+			}
+		})
+	}
+}
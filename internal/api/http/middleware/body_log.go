@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/shared/jsonutil"
+	"github.com/histopathai/auth-service/pkg/config"
+)
+
+// redactedBodyKeys mark JSON fields whose values are masked in logged
+// bodies; a field is masked when its lowercased name contains any of them
+var redactedBodyKeys = []string{"password", "token", "secret", "session", "authorization", "cookie"}
+
+// BodyLogging logs the request and response bodies of a sample of requests
+// under the configured path prefixes at debug level, for diagnosing
+// integration issues. Only JSON bodies are logged, with credential fields
+// masked; other bodies and bodies over the size cap are logged by size only.
+func BodyLogging(cfg *config.LoggingConfig, logger *slog.Logger) gin.HandlerFunc {
+	sampleRate := uint64(max(cfg.BodyLogSampleRate, 1))
+	maxBytes := cfg.BodyLogMaxBytes
+	var matched atomic.Uint64
+
+	return func(c *gin.Context) {
+		if !hasAnyPrefix(c.Request.URL.Path, cfg.BodyLogPaths) || matched.Add(1)%sampleRate != 0 {
+			c.Next()
+			return
+		}
+
+		var requestBody []byte
+		if c.Request.Body != nil {
+			// Read one byte past the cap to tell a body at the cap from a
+			// longer one, then hand the handler the whole body again
+			requestBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBytes)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, limit: maxBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		logger.Debug("HTTP Body",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status_code", writer.Status(),
+			"request_id", c.GetString("request_id"),
+			"request_body", describeBody(requestBody, c.ContentType(), maxBytes),
+			"response_body", describeBody(writer.body.Bytes(), writer.Header().Get("Content-Type"), maxBytes),
+		)
+	}
+}
+
+// bodyCaptureWriter keeps the first bytes of the response body for logging
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyCaptureWriter) capture(b []byte) {
+	// Keep one byte past the limit so oversized bodies can be recognized
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	}
+}
+
+// describeBody returns a body as logged: redacted JSON, or only its size
+// when it is not JSON or exceeds maxBytes
+func describeBody(body []byte, contentType string, maxBytes int) any {
+	if len(body) == 0 {
+		return ""
+	}
+	if len(body) > maxBytes {
+		return fmt.Sprintf("<more than %d bytes omitted>", maxBytes)
+	}
+
+	var decoded any
+	if !strings.Contains(contentType, "json") || jsonutil.Unmarshal(body, &decoded) != nil {
+		return fmt.Sprintf("<%d bytes of %q omitted>", len(body), contentType)
+	}
+	return redactBody(decoded)
+}
+
+// redactBody masks credential fields at any depth of a decoded JSON value
+func redactBody(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isRedactedBodyKey(key) {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redactBody(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactBody(item)
+		}
+	}
+	return value
+}
+
+func isRedactedBodyKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, redacted := range redactedBodyKeys {
+		if strings.Contains(key, redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+)
+
+// RequireJSONContentType rejects write requests with a body that is not
+// declared as JSON with 415, rather than letting them fail to bind with a
+// less precise error. Bodiless requests pass, as do the route templates in
+// exemptRoutes, such as routes accepting uploads or passing bodies through.
+func RequireJSONContentType(exemptRoutes ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptRoutes))
+	for _, route := range exemptRoutes {
+		exempt[route] = true
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		// ContentLength is -1 when a body of unknown length is sent
+		if c.Request.ContentLength == 0 || exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		contentType := c.GetHeader("Content-Type")
+		if isJSONMediaType(contentType) {
+			c.Next()
+			return
+		}
+
+		respond.AbortWithError(c, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Request body must be JSON with Content-Type: application/json", map[string]interface{}{
+				"content_type": contentType,
+			})
+	}
+}
+
+// isJSONMediaType accepts application/json and structured +json types
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")
+}
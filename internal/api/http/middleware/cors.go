@@ -1,16 +1,33 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/histopathai/auth-service/pkg/config"
 )
 
+// CORSMiddleware applies the configured CORS policy to every route except the
+// main service proxy, which handles CORS itself. Preflight requests from
+// allowed origins are answered here with 204, so routes never need their own
+// OPTIONS handlers. Preflights from other origins get no CORS headers and
+// fall through, which browsers treat as a rejection.
 func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, proxyPathPrefix) {
+			c.Next()
+			return
+		}
+
+		// Responses vary by origin whether or not this one is allowed
+		c.Writer.Header().Add("Vary", "Origin")
+
 		origin := c.Request.Header.Get("Origin")
 		allowed := false
 
-		for _, o := range cfg.AllowedOrigins {
+		for _, o := range cfg.CORS.AllowedOrigins {
 			if origin == o {
 				allowed = true
 				break
@@ -22,18 +39,60 @@ func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Cookie")
-		c.Writer.Header().Set("Access-Control-Max-Age", "3600")
+		SetCORSHeaders(c.Writer.Header(), origin, &cfg.CORS)
 
 		// Preflight request handling
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if c.Request.Method == http.MethodOptions && c.Request.Header.Get("Access-Control-Request-Method") != "" {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// SetCORSHeaders writes the configured CORS headers for an allowed origin.
+// It is shared by the global middleware and the main service proxy so both
+// paths advertise identical policies.
+func SetCORSHeaders(header http.Header, origin string, cfg *config.CORSConfig) {
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Credentials", "true")
+	header.Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+	header.Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+	if len(cfg.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+	}
+	header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+}
+
+// SetPublicCORSHeaders writes the CORS headers for an uncredentialed request
+// to a public path. Without Allow-Credentials the response cannot expose a
+// user's data, and a "*" policy makes it identical for every origin so
+// shared caches can serve it to all of them.
+func SetPublicCORSHeaders(header http.Header, origin string, cfg *config.CORSConfig) {
+	allowOrigin := origin
+	for _, allowed := range cfg.PublicOrigins {
+		if allowed == "*" {
+			allowOrigin = "*"
+			break
+		}
+	}
+	if allowOrigin != "*" {
+		header.Add("Vary", "Origin")
+	}
+
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+	header.Del("Access-Control-Allow-Credentials")
+	header.Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+	header.Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+	if len(cfg.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+	}
+	header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+}
+
+// HasCredentials reports whether a request carries a cookie or an
+// Authorization header, the credentials a browser sends in credentialed mode
+func HasCredentials(r *http.Request) bool {
+	return r.Header.Get("Cookie") != "" || r.Header.Get("Authorization") != ""
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/pkg/config"
+)
+
+func newCORSTestEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{CORS: config.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         600,
+	}}
+
+	engine := gin.New()
+	engine.Use(CORSMiddleware(cfg))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/api/v1/sessions", ok)
+	engine.DELETE("/api/v1/sessions/:session_id", ok)
+	engine.Any("/api/v1/proxy/*proxyPath", ok)
+	return engine
+}
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		origin          string
+		wantStatus      int
+		wantAllowOrigin string
+	}{
+		{name: "credentialed preflight for session listing", path: "/api/v1/sessions", origin: "https://app.example.com", wantStatus: http.StatusNoContent, wantAllowOrigin: "https://app.example.com"},
+		{name: "credentialed preflight for session revocation", path: "/api/v1/sessions/session-1", origin: "https://app.example.com", wantStatus: http.StatusNoContent, wantAllowOrigin: "https://app.example.com"},
+		{name: "origin outside the allowlist", path: "/api/v1/sessions", origin: "https://evil.example.com", wantStatus: http.StatusNotFound},
+		// The proxy answers its own preflights
+		{name: "proxy path", path: "/api/v1/proxy/slides", origin: "https://app.example.com", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+			req.Header.Set("Access-Control-Request-Headers", "content-type")
+			recorder := httptest.NewRecorder()
+
+			newCORSTestEngine().ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			header := recorder.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllowOrigin)
+			}
+			if tt.wantAllowOrigin == "" {
+				return
+			}
+			if got := header.Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want true", got)
+			}
+			if got := header.Get("Access-Control-Allow-Methods"); !strings.Contains(got, http.MethodDelete) {
+				t.Errorf("Access-Control-Allow-Methods = %q, want it to include DELETE", got)
+			}
+			if got := header.Get("Access-Control-Allow-Headers"); !strings.Contains(got, "Content-Type") {
+				t.Errorf("Access-Control-Allow-Headers = %q, want it to include Content-Type", got)
+			}
+			if got := header.Get("Vary"); got != "Origin" {
+				t.Errorf("Vary = %q, want Origin", got)
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareCredentialedRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "session-1"})
+	recorder := httptest.NewRecorder()
+
+	newCORSTestEngine().ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+}
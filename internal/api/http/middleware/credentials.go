@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+)
+
+// ErrorCodeConflictingCredentials rejects requests whose session cookie and
+// bearer token belong to different users. Which credential wins would
+// otherwise depend on the order they are checked in.
+const ErrorCodeConflictingCredentials = "CONFLICTING_CREDENTIALS"
+
+// RejectConflictingCredentials aborts a request whose session and bearer
+// token resolve to different users. A legitimate client never sends both
+// for different accounts, so it is logged as a possible attack, e.g. a
+// token injected into a browser holding someone else's session.
+func RejectConflictingCredentials(c *gin.Context, logger *slog.Logger, sessionUserID string, bearerUserID string) {
+	logger.Warn("Rejected request with conflicting credentials",
+		"session_user_id", sessionUserID,
+		"bearer_user_id", bearerUserID,
+		"client_ip", c.ClientIP(),
+		"path", c.Request.URL.Path,
+		"user_agent", c.Request.UserAgent(),
+	)
+	respond.AbortWithError(c, http.StatusUnauthorized, ErrorCodeConflictingCredentials,
+		"Session cookie and bearer token belong to different users; send only one", nil)
+}
@@ -2,16 +2,40 @@ package middleware
 
 import (
 	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/shared/metrics"
+	"github.com/histopathai/auth-service/pkg/config"
+)
+
+const proxyPathPrefix = "/api/v1/proxy/"
+
+// Access log formats
+const (
+	AccessLogStructured = "structured"
+	AccessLogCombined   = "combined"
+	AccessLogBoth       = "both"
 )
 
 // LoggingMddileware Logs HTTP requests
-func LoggingMiddleware() gin.HandlerFunc {
+func LoggingMiddleware(cfg *config.LoggingConfig, logger *slog.Logger) gin.HandlerFunc {
+	sampleRate := uint64(max(cfg.ProxySampleRate, 1))
+	slowThreshold := time.Duration(cfg.SlowRequestThreshold) * time.Millisecond
+	var proxyRequests atomic.Uint64
+
+	structured := cfg.AccessLogFormat != AccessLogCombined
+	combined := cfg.AccessLogFormat == AccessLogCombined || cfg.AccessLogFormat == AccessLogBoth
+	accessLog := &combinedLogWriter{out: os.Stdout}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
+		// The proxy rewrites the URL, so keep what the client asked for
+		requestURI := c.Request.RequestURI
 		method := c.Request.Method
 		clientIP := c.ClientIP()
 		userAgent := c.Request.UserAgent()
@@ -22,13 +46,32 @@ func LoggingMiddleware() gin.HandlerFunc {
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
 
+		// Metrics are recorded for every request, including sampled-out ones
+		metrics.RecordHTTPRequest(method, c.FullPath(), statusCode, latency)
+
+		if combined {
+			accessLog.write(c, start, clientIP, method, requestURI, statusCode)
+		}
+		if !structured {
+			return
+		}
+
+		// High-volume proxy traffic (e.g. image tiles) is sampled unless the
+		// request failed or was slow
+		isProxy := strings.HasPrefix(path, proxyPathPrefix)
+		if isProxy && statusCode < 400 && (slowThreshold <= 0 || latency < slowThreshold) {
+			if proxyRequests.Add(1)%sampleRate != 0 {
+				return
+			}
+		}
+
 		// Get user info if available
 		var userID string
 		if user_id, exists := c.Get("user_id"); exists {
 			userID = user_id.(string)
 		}
 
-		slog.Info("HTTP Request",
+		attrs := []any{
 			"method", method,
 			"path", path,
 			"client_ip", clientIP,
@@ -36,7 +79,13 @@ func LoggingMiddleware() gin.HandlerFunc {
 			"status_code", statusCode,
 			"latency", latency,
 			"user_id", userID,
-		)
+			"request_id", c.GetString("request_id"),
+		}
+		if isProxy && sampleRate > 1 {
+			attrs = append(attrs, "sample_rate", sampleRate)
+		}
+
+		logger.Info("HTTP Request", attrs...)
 	}
 
 }
@@ -1,43 +1,96 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+	"github.com/histopathai/auth-service/internal/shared/lru"
+	"github.com/histopathai/auth-service/internal/shared/metrics"
 )
 
+// DefaultVisitorIdleTimeout is how long a client may stay idle before it is
+// forgotten when no timeout is configured
+const DefaultVisitorIdleTimeout = 10 * time.Minute
+
+// VisitorLimits bound the memory a rate limiter spends tracking clients
+type VisitorLimits struct {
+	// IdleTimeout forgets clients idle for longer, swept at the same
+	// interval; zero uses DefaultVisitorIdleTimeout. It is never shorter
+	// than the refill period, so a forgotten client cannot regain a full
+	// burst early.
+	IdleTimeout time.Duration
+	// MaxVisitors evicts the least recently seen client once more are
+	// tracked, so a spike of unique clients cannot grow the map between
+	// sweeps; zero leaves it unbounded. An evicted client starts over with
+	// a full burst.
+	MaxVisitors int
+}
+
 // RateLimiter implements a simple in-memory rate limiter
 type RateLimiter struct {
-	visitors map[string]*visitor
-	mu       sync.RWMutex
+	// name identifies the limiter in metrics
+	name string
+	// visitors tracks clients by IP, forgetting those idle for longer than
+	// cleanup and evicting the least recently seen beyond MaxVisitors
+	visitors *lru.Cache[string, *visitor]
 	rate     int
 	burst    int
+	period   time.Duration
 	cleanup  time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// softLimit is the number of tokens left at or below which requests are
+	// still served but flagged with X-RateLimit-Warning; zero disables it
+	softLimit int
+	logger    *slog.Logger
 }
 
 type visitor struct {
-	limiter  *tokenBucket
-	lastSeen time.Time
+	limiter *tokenBucket
 }
 
 type tokenBucket struct {
 	tokens     int
 	capacity   int
 	rate       int
+	period     time.Duration
 	lastRefill time.Time
 	mu         sync.Mutex
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(rate, burst int) *RateLimiter {
+// NewRateLimiter creates a new rate limiter refilling rate tokens per second
+func NewRateLimiter(name string, rate, burst int, limits VisitorLimits) *RateLimiter {
+	return NewRateLimiterWithPeriod(name, rate, burst, time.Second, limits)
+}
+
+// NewRateLimiterWithPeriod creates a rate limiter refilling rate tokens per
+// period, for limits slower than one request per second
+func NewRateLimiterWithPeriod(name string, rate, burst int, period time.Duration, limits VisitorLimits) *RateLimiter {
+	cleanup := limits.IdleTimeout
+	if cleanup <= 0 {
+		cleanup = DefaultVisitorIdleTimeout
+	}
+	if period > cleanup {
+		cleanup = period
+	}
+
 	rl := &RateLimiter{
-		visitors: make(map[string]*visitor),
+		name:     name,
+		visitors: lru.NewSliding[string, *visitor](cleanup, limits.MaxVisitors),
 		rate:     rate,
 		burst:    burst,
-		cleanup:  time.Minute * 10,
+		period:   period,
+		cleanup:  cleanup,
+		stop:     make(chan struct{}),
 	}
+	rl.visitors.OnRemove(func(string, *visitor) {
+		metrics.AddRateLimitVisitors(name, -1)
+	})
 
 	// Start cleanup goroutine
 	go rl.cleanupVisitors()
@@ -50,63 +103,78 @@ func (rl *RateLimiter) cleanupVisitors() {
 
 	for {
 		select {
+		case <-rl.stop:
+			return
 		case <-ticker.C:
-			rl.mu.Lock()
-			for ip, v := range rl.visitors {
-				if time.Since(v.lastSeen) > rl.cleanup {
-					delete(rl.visitors, ip)
-				}
-			}
-			rl.mu.Unlock()
+			rl.visitors.RemoveExpired()
 		}
 	}
 }
 
-func (rl *RateLimiter) getVisitor(ip string) *visitor {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+// SetSoftLimit warns clients once they have used threshold of their burst,
+// given as a fraction between 0 and 1, so they can slow down before being
+// rejected. A threshold outside (0, 1) disables the warning.
+func (rl *RateLimiter) SetSoftLimit(threshold float64, logger *slog.Logger) {
+	if threshold <= 0 || threshold >= 1 {
+		rl.softLimit = 0
+		return
+	}
 
-	v, exists := rl.visitors[ip]
-	if !exists {
-		v = &visitor{
+	rl.softLimit = rl.burst - int(float64(rl.burst)*threshold)
+	if rl.softLimit < 1 {
+		rl.softLimit = 1
+	}
+	rl.logger = logger
+}
+
+// Stop shuts down the cleanup goroutine. It is safe to call more than once.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() {
+		close(rl.stop)
+	})
+}
+
+func (rl *RateLimiter) getVisitor(ip string) *visitor {
+	v, _ := rl.visitors.GetOrAdd(ip, func() *visitor {
+		metrics.AddRateLimitVisitors(rl.name, 1)
+		return &visitor{
 			limiter: &tokenBucket{
 				tokens:     rl.burst,
 				capacity:   rl.burst,
 				rate:       rl.rate,
+				period:     rl.period,
 				lastRefill: time.Now(),
 			},
-			lastSeen: time.Now(),
 		}
-		rl.visitors[ip] = v
-	}
-
-	v.lastSeen = time.Now()
+	})
 	return v
 }
 
-func (tb *tokenBucket) allow() bool {
+// allow takes a token if one is available and reports the tokens left
+func (tb *tokenBucket) allow() (bool, int) {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
 	now := time.Now()
 	elapsed := now.Sub(tb.lastRefill)
 
-	// Add tokens based on elapsed time
-	tokensToAdd := int(elapsed.Seconds()) * tb.rate
+	// Add tokens based on elapsed periods
+	periods := int(elapsed / tb.period)
+	tokensToAdd := periods * tb.rate
 	if tokensToAdd > 0 {
 		tb.tokens += tokensToAdd
 		if tb.tokens > tb.capacity {
 			tb.tokens = tb.capacity
 		}
-		tb.lastRefill = now
+		tb.lastRefill = tb.lastRefill.Add(time.Duration(periods) * tb.period)
 	}
 
 	if tb.tokens > 0 {
 		tb.tokens--
-		return true
+		return true, tb.tokens
 	}
 
-	return false
+	return false, 0
 }
 
 // RateLimit middleware
@@ -115,15 +183,25 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 		ip := c.ClientIP()
 		visitor := rl.getVisitor(ip)
 
-		if !visitor.limiter.allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "rate_limit_exceeded",
-				"message": "Too many requests, please try again later",
-			})
-			c.Abort()
+		allowed, remaining := visitor.limiter.allow()
+		if !allowed {
+			respond.AbortWithError(c, http.StatusTooManyRequests, "rate_limit_exceeded", "Too many requests, please try again later", nil)
 			return
 		}
 
+		if rl.softLimit > 0 && remaining <= rl.softLimit {
+			c.Header("X-RateLimit-Warning", "true")
+			// Log once when the client crosses the threshold, not on every request
+			if remaining == rl.softLimit && rl.logger != nil {
+				rl.logger.Warn("Client approaching rate limit",
+					"client_ip", ip,
+					"path", c.Request.URL.Path,
+					"remaining", remaining,
+					"burst", rl.burst,
+				)
+			}
+		}
+
 		c.Next()
 	}
 }
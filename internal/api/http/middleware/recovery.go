@@ -5,24 +5,21 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
 )
 
 // RecoveryMiddleware recovers from panics and returns a 500 error
-func RecoveryMiddleware() gin.HandlerFunc {
+func RecoveryMiddleware(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				slog.Error("Panic recovered",
+				logger.Error("Panic recovered",
 					"error", err,
 					"path", c.Request.URL.Path,
 					"method", c.Request.Method,
 				)
 
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "internal_server_error",
-					"message": "An internal error occurred",
-				})
-				c.Abort()
+				respond.AbortWithError(c, http.StatusInternalServerError, "internal_server_error", "An internal error occurred", nil)
 			}
 		}()
 		c.Next()
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/histopathai/auth-service/internal/shared/requestid"
+)
+
+// validRequestID bounds the IDs accepted from clients so they are safe to
+// log and forward
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID assigns every request an ID, reusing a well-formed one sent by
+// the client. The ID is stored as "request_id" in the gin context, carried
+// by the request context, echoed in the response and forwarded upstream.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !validRequestID.MatchString(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set("request_id", id)
+		c.Request.Header.Set(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
 	"github.com/histopathai/auth-service/internal/domain/model"
 )
 
@@ -12,19 +13,13 @@ func SelfOrAdminOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":    "user_not_found",
-				"meessage": "User not found in context",
-			})
+			respond.AbortWithError(c, http.StatusUnauthorized, "user_not_found", "User not found in context", nil)
+			return
 		}
 
 		u, ok := user.(*model.User)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "invalid_user_context",
-				"message": "User context is invalid",
-			})
-			c.Abort()
+			respond.AbortWithError(c, http.StatusInternalServerError, "invalid_user_context", "User context is invalid", nil)
 			return
 		}
 
@@ -37,11 +32,7 @@ func SelfOrAdminOnly() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "access_denied",
-			"message": "You can only access your own resources",
-		})
-		c.Abort()
+		respond.AbortWithError(c, http.StatusForbidden, "access_denied", "You can only access your own resources", nil)
 	}
 
 }
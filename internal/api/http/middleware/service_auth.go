@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+	"github.com/histopathai/auth-service/pkg/config"
+	"google.golang.org/api/idtoken"
+)
+
+// ServiceAuthMiddleware authenticates calls from other internal services
+// using Google-signed ID tokens issued to allowed service accounts
+type ServiceAuthMiddleware struct {
+	audience        string
+	serviceAccounts map[string]bool
+	logger          *slog.Logger
+}
+
+func NewServiceAuthMiddleware(cfg *config.InternalAPIConfig, logger *slog.Logger) *ServiceAuthMiddleware {
+	serviceAccounts := make(map[string]bool, len(cfg.AllowedServiceAccounts))
+	for _, account := range cfg.AllowedServiceAccounts {
+		serviceAccounts[account] = true
+	}
+
+	return &ServiceAuthMiddleware{
+		audience:        cfg.Audience,
+		serviceAccounts: serviceAccounts,
+		logger:          logger,
+	}
+}
+
+// RequireServiceAccount rejects requests without a valid ID token from an
+// allowed service account
+func (m *ServiceAuthMiddleware) RequireServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(authHeader, "Bearer ")
+		if !found || token == "" {
+			respond.AbortWithError(c, http.StatusUnauthorized, "service_auth_required", "Service identity token required", nil)
+			return
+		}
+
+		payload, err := idtoken.Validate(c.Request.Context(), token, m.audience)
+		if err != nil {
+			m.logger.Warn("Invalid service identity token", "error", err, "path", c.Request.URL.Path)
+			respond.AbortWithError(c, http.StatusUnauthorized, "invalid_service_token", "Invalid service identity token", nil)
+			return
+		}
+
+		email, _ := payload.Claims["email"].(string)
+		verified, _ := payload.Claims["email_verified"].(bool)
+		if !verified || !m.serviceAccounts[email] {
+			m.logger.Warn("Service account not allowed", "email", email, "path", c.Request.URL.Path)
+			respond.AbortWithError(c, http.StatusForbidden, "service_not_allowed", "Service account is not allowed", nil)
+			return
+		}
+
+		c.Set("service_account", email)
+		c.Next()
+	}
+}
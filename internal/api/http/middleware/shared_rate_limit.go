@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+	"github.com/histopathai/auth-service/internal/domain/repository"
+)
+
+// SharedRateLimit limits requests per client IP to limit per window using a
+// store shared by all replicas, for endpoints where per-instance limits are
+// too loose. If the store is unavailable requests are let through.
+func SharedRateLimit(store repository.RateLimitStore, scope string, limit int, window time.Duration, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		allowed, retryAfter, err := store.Allow(c.Request.Context(), scope+":"+ip, limit, window)
+		if err != nil {
+			logger.Error("Shared rate limit check failed; allowing request",
+				"scope", scope,
+				"client_ip", ip,
+				"error", err,
+			)
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			respond.AbortWithError(c, http.StatusTooManyRequests, "rate_limit_exceeded", "Too many requests, please try again later", map[string]interface{}{
+				"retry_after_seconds": seconds,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
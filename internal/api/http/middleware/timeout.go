@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// Timeout puts a deadline on the request context so Firestore and Firebase
+// calls made with it are cancelled once it passes. If the handler has not
+// written a response by then, a 504 is sent. A zero timeout adds no deadline.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			respond.AbortWithError(c, http.StatusGatewayTimeout, string(sharedErrors.ErrorTypeTimeout), "The request timed out", nil)
+		}
+	}
+}
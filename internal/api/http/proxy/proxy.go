@@ -4,37 +4,64 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/cookie"
+	"github.com/histopathai/auth-service/internal/api/http/dto/response"
+	"github.com/histopathai/auth-service/internal/api/http/middleware"
+	"github.com/histopathai/auth-service/internal/api/http/respond"
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/service"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/internal/shared/jsonutil"
+	"github.com/histopathai/auth-service/internal/shared/metrics"
 	"github.com/histopathai/auth-service/pkg/config"
+	"github.com/histopathai/auth-service/pkg/signing"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/idtoken"
 )
 
+// Authenticator is the part of the auth service the proxy needs to resolve
+// the user behind a request
+type Authenticator interface {
+	VerifyToken(ctx context.Context, idToken string) (*model.User, error)
+	GetUserByUserID(ctx context.Context, userID string) (*model.User, error)
+	// AccountStatusError explains why a non-active user is turned away
+	AccountStatusError(status model.UserStatus) *sharedErrors.Err
+}
+
 type MainServiceProxy struct {
 	targetURL      *url.URL
 	proxy          *httputil.ReverseProxy
-	authService    *service.AuthService
-	sessionService *service.SessionService
+	authService    Authenticator
+	sessionService service.SessionService
 	logger         *slog.Logger
 	config         *config.Config
 	tokenSource    oauth2.TokenSource
+
+	// consecutiveFailures counts upstream transport errors since the last
+	// response and drives the Retry-After backoff
+	consecutiveFailures atomic.Int64
 }
 
 func NewMainServiceProxy(
 	targetBaseURL string,
-	authService *service.AuthService,
-	sessionService *service.SessionService,
+	authService Authenticator,
+	sessionService service.SessionService,
 	config *config.Config,
 	logger *slog.Logger,
 ) (*MainServiceProxy, error) {
@@ -50,7 +77,12 @@ func NewMainServiceProxy(
 
 	ts, err := idtoken.NewTokenSource(context.Background(), targetBaseURL)
 	if err != nil {
-		// Local development'ta hata vermemesi için loglayıp geçebilirsiniz veya mocklayabilirsiniz
+		// Outside local development an upstream not authenticated by request
+		// signing requires ID tokens, so proxying without them would only
+		// fail later on every request
+		if config.Server.Environment != "dev" && config.Proxy.SigningSecret == "" {
+			return nil, fmt.Errorf("failed to create ID token source for the main service proxy: %w", err)
+		}
 		logger.Warn("Failed to create ID token source (ignore if local)", "error", err)
 	}
 	msp := &MainServiceProxy{
@@ -66,6 +98,7 @@ func NewMainServiceProxy(
 		Director:       msp.director,
 		ModifyResponse: msp.modifyResponse,
 		ErrorHandler:   msp.errorHandler,
+		ErrorLog:       slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
 	logger.Info("Main Service Proxy initialized",
@@ -85,10 +118,7 @@ func (msp *MainServiceProxy) director(req *http.Request) {
 		"query", req.URL.RawQuery,
 	)
 
-	trimmed := strings.TrimPrefix(originalPath, "/api/v1/proxy")
-	if trimmed == "" {
-		trimmed = "/"
-	}
+	trimmed := normalizeProxyPath(strings.TrimPrefix(originalPath, "/api/v1/proxy"))
 
 	var newPath string
 
@@ -108,10 +138,18 @@ func (msp *MainServiceProxy) director(req *http.Request) {
 		"new_path", newPath,
 	)
 
+	// Tell the upstream where the client connected so it can build absolute
+	// URLs pointing back at this service. Client-supplied values were
+	// stripped with the other spoofable headers.
+	req.Header.Set("X-Forwarded-Host", req.Host)
+	req.Header.Set("X-Forwarded-Proto", msp.forwardedProto(req))
+
 	req.URL.Scheme = msp.targetURL.Scheme
 	req.URL.Host = msp.targetURL.Host
 	req.URL.Path = newPath
-	req.Host = msp.targetURL.Host
+	if !msp.config.Proxy.PreserveHost {
+		req.Host = msp.targetURL.Host
+	}
 
 	if msp.tokenSource != nil {
 		token, err := msp.tokenSource.Token()
@@ -133,6 +171,8 @@ func (msp *MainServiceProxy) director(req *http.Request) {
 		)
 	}
 
+	msp.signRequest(req)
+
 	msp.logger.Debug("Request proxied",
 		"target_url", fmt.Sprintf("%s://%s%s", req.URL.Scheme, req.URL.Host, req.URL.Path),
 		"user_id", req.Header.Get("X-User-ID"),
@@ -140,6 +180,57 @@ func (msp *MainServiceProxy) director(req *http.Request) {
 	)
 }
 
+// forwardedProto returns the scheme the client used. Behind a TLS
+// terminating front end the request arrives over plain HTTP, so the scheme
+// of the public base URL is used instead.
+func (msp *MainServiceProxy) forwardedProto(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+	if base, err := url.Parse(msp.config.Server.BaseURL); err == nil && base.Scheme == "https" {
+		return "https"
+	}
+	return "http"
+}
+
+// signRequest attaches HMAC signature headers when request signing is
+// enabled. The body was already buffered by bufferSignedBody, so reading it
+// here cannot fail or grow past the configured limit.
+func (msp *MainServiceProxy) signRequest(req *http.Request) {
+	if msp.config.Proxy.SigningSecret == "" {
+		req.Header.Del(signing.HeaderSignature)
+		req.Header.Del(signing.HeaderTimestamp)
+		return
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	timestamp, signature := signing.Sign([]byte(msp.config.Proxy.SigningSecret), req.Method, req.URL.RequestURI(), body, time.Now())
+	req.Header.Set(signing.HeaderTimestamp, timestamp)
+	req.Header.Set(signing.HeaderSignature, signature)
+}
+
+// normalizeProxyPath collapses repeated slashes in a path relative to the
+// proxy prefix and roots it, keeping any trailing slash, so that "", "/" and
+// "//foo/" become "/", "/" and "/foo/"
+func normalizeProxyPath(p string) string {
+	normalized := make([]byte, 1, len(p)+1)
+	normalized[0] = '/'
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && normalized[len(normalized)-1] == '/' {
+			continue
+		}
+		normalized = append(normalized, p[i])
+	}
+	return string(normalized)
+}
+
 func isGCSProxyPath(path string) bool {
 	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
 	if len(parts) == 0 {
@@ -177,17 +268,26 @@ func (msp *MainServiceProxy) modifyResponse(resp *http.Response) error {
 	resp.Header.Del("Access-Control-Allow-Headers")
 	resp.Header.Del("Access-Control-Max-Age")
 
+	// The upstream answered, so any outage backoff starts over
+	msp.consecutiveFailures.Store(0)
+
+	// Successful bodies are never read here; the reverse proxy copies them
+	// to the client in chunks, so large images stream without buffering
 	if statusCode >= 200 && statusCode < 400 {
 		msp.logger.Debug("Proxy response",
 			"status", statusCode,
 			"url", requestURL,
 		)
 
-		// Cache headers for image/tile endpoints
-		if strings.Contains(resp.Request.URL.Path, "/tiles/") ||
-			strings.Contains(resp.Request.URL.Path, "/images/") {
+		// Cache headers for image/tile endpoints. HEAD responses carry the
+		// same headers as GET so clients can check existence and freshness.
+		// Only an ETag from the upstream identifies the content; one made up
+		// here would answer 304 for an image that has since changed.
+		if isImagePath(resp.Request.URL.Path) {
 			resp.Header.Set("Cache-Control", "public, max-age=3600")
-			resp.Header.Set("ETag", fmt.Sprintf(`"%s"`, resp.Request.URL.Path))
+			if etag := resp.Header.Get("ETag"); etag != "" && statusCode == http.StatusOK && respond.ETagMatches(resp.Request.Header.Get("If-None-Match"), etag) {
+				notModified(resp)
+			}
 		}
 
 		return nil
@@ -199,29 +299,142 @@ func (msp *MainServiceProxy) modifyResponse(resp *http.Response) error {
 		"url", requestURL,
 	)
 
-	// Read and log error body
-	if resp.Body != nil {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body = io.NopCloser(bytes.NewBuffer(body))
+	// Read and log error body; HEAD responses never have one. Only up to
+	// maxErrorBodySize is buffered; larger bodies stream through unchanged.
+	if resp.Body != nil && resp.Request.Method != http.MethodHead {
+		original := resp.Body
+		body, _ := io.ReadAll(io.LimitReader(original, maxErrorBodySize+1))
+
+		if len(body) > maxErrorBodySize {
+			msp.logger.Warn("Error response body too large to inspect",
+				"url", requestURL,
+				"content_length", resp.ContentLength,
+			)
+			resp.Body = readCloser{
+				Reader: io.MultiReader(bytes.NewReader(body), original),
+				Closer: original,
+			}
+			return nil
+		}
+
+		original.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
 
 		if len(body) > 0 && len(body) < 1000 {
 			msp.logger.Warn("Error response body",
 				"body", string(body),
 			)
 		}
+
+		if msp.config.Proxy.NormalizeUpstreamErrors {
+			msp.normalizeErrorBody(resp, body)
+		}
 	}
 
 	return nil
 }
 
+// maxErrorBodySize caps how much of an upstream error body is read into
+// memory for logging and normalization
+const maxErrorBodySize = 64 << 10
+
+// readCloser joins a reader with the closer of the body it was built from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func isImagePath(path string) bool {
+	return strings.Contains(path, "/tiles/") || strings.Contains(path, "/images/")
+}
+
+// notModified turns a successful response into a 304 without a body
+func notModified(resp *http.Response) {
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+	resp.StatusCode = http.StatusNotModified
+	resp.Status = fmt.Sprintf("%d %s", http.StatusNotModified, http.StatusText(http.StatusNotModified))
+	resp.Body = http.NoBody
+	resp.ContentLength = 0
+	resp.Header.Del("Content-Length")
+	resp.Header.Del("Content-Type")
+	resp.Header.Del("Content-Encoding")
+}
+
+// normalizeErrorBody rewrites a JSON error body from the upstream into the
+// standard ErrorResponse shape, keeping the original body under details.
+// Non-JSON bodies are passed through untouched.
+func (msp *MainServiceProxy) normalizeErrorBody(resp *http.Response, body []byte) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "application/json" || len(body) == 0 {
+		return
+	}
+
+	var upstream interface{}
+	if err := jsonutil.Unmarshal(body, &upstream); err != nil {
+		return
+	}
+
+	errorResponse := response.ErrorResponse{
+		ErrorType: "upstream_error",
+		Message:   http.StatusText(resp.StatusCode),
+		Details: map[string]interface{}{
+			"upstream": upstream,
+		},
+	}
+	if fields, ok := upstream.(map[string]interface{}); ok {
+		if errType, ok := fields["error"].(string); ok && errType != "" {
+			errorResponse.ErrorType = errType
+		}
+		if message, ok := fields["message"].(string); ok && message != "" {
+			errorResponse.Message = message
+		}
+	}
+
+	normalized, err := json.Marshal(errorResponse)
+	if err != nil {
+		return
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(normalized))
+	resp.ContentLength = int64(len(normalized))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(normalized)))
+	resp.Header.Del("Content-Encoding")
+}
+
 func (msp *MainServiceProxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	// The client went away and the upstream round trip was aborted with it;
+	// this says nothing about upstream health and nobody is left to answer
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		msp.logger.Debug("Proxy request canceled by client",
+			"url", r.URL.String(),
+			"method", r.Method,
+		)
+		return
+	}
+
 	msp.logger.Error("Proxy request failed",
 		"error", err,
 		"url", r.URL.String(),
 		"method", r.Method,
 	)
 
+	// The request's own deadline passed; the upstream is not necessarily down
+	if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGatewayTimeout)
+		json.NewEncoder(w).Encode(response.ErrorResponse{
+			ErrorType: string(sharedErrors.ErrorTypeTimeout),
+			Message:   "The request timed out",
+		})
+		return
+	}
+
+	retryAfter := msp.retryAfter(msp.consecutiveFailures.Add(1))
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
 	w.WriteHeader(http.StatusServiceUnavailable)
 
 	errorType := "connection_error"
@@ -231,20 +444,43 @@ func (msp *MainServiceProxy) errorHandler(w http.ResponseWriter, r *http.Request
 		errorType = "connection_refused"
 	}
 
-	errorResponse := map[string]interface{}{
-		"error":   "service_unavailable",
-		"message": "Main service is temporarily unavailable",
-		"details": errorType,
+	errorResponse := response.ErrorResponse{
+		ErrorType: "service_unavailable",
+		Message:   "Main service is temporarily unavailable",
+		Details: map[string]interface{}{
+			"cause":               errorType,
+			"retry_after_seconds": retryAfter,
+		},
 	}
 
 	json.NewEncoder(w).Encode(errorResponse)
 }
 
+// retryAfter returns the backoff in seconds after the given number of
+// consecutive upstream failures, doubling from the configured base up to the
+// configured maximum
+func (msp *MainServiceProxy) retryAfter(failures int64) int {
+	base := max(msp.config.Proxy.RetryAfter, 1)
+	limit := max(msp.config.Proxy.RetryAfterMax, base)
+
+	retryAfter := base
+	for i := int64(1); i < failures && retryAfter < limit; i++ {
+		retryAfter *= 2
+	}
+	return min(retryAfter, limit)
+}
+
 func (msp *MainServiceProxy) setCORSHeaders(c *gin.Context) {
 	origin := c.Request.Header.Get("Origin")
 
-	allowedOrigins := make([]string, len(msp.config.AllowedOrigins))
-	copy(allowedOrigins, msp.config.AllowedOrigins)
+	if msp.usePublicCORS(c, origin) {
+		middleware.SetPublicCORSHeaders(c.Writer.Header(), origin, &msp.config.CORS)
+		msp.logger.Debug("Public CORS headers set", "origin", origin)
+		return
+	}
+
+	allowedOrigins := make([]string, len(msp.config.CORS.AllowedOrigins))
+	copy(allowedOrigins, msp.config.CORS.AllowedOrigins)
 	allowedOrigins = append(allowedOrigins,
 		"https://localhost:5173", // Explicit ekle
 		"http://localhost:5173",  // HTTP de kabul et
@@ -265,25 +501,61 @@ func (msp *MainServiceProxy) setCORSHeaders(c *gin.Context) {
 	}
 
 	if !originAllowed && origin != "" {
-		msp.logger.Warn("CORS: Origin not allowed", "origin", origin, "allowed", msp.config.AllowedOrigins)
+		msp.logger.Warn("CORS: Origin not allowed", "origin", origin, "allowed", msp.config.CORS.AllowedOrigins)
 		return
 	}
 
 	allowOrigin := origin
-	if allowOrigin == "" && len(msp.config.AllowedOrigins) > 0 {
-		allowOrigin = msp.config.AllowedOrigins[0]
+	if allowOrigin == "" && len(msp.config.CORS.AllowedOrigins) > 0 {
+		allowOrigin = msp.config.CORS.AllowedOrigins[0]
 	}
 
-	c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
-	c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-	c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Session-ID, Cookie")
-	c.Writer.Header().Set("Access-Control-Max-Age", "3600")
-	c.Writer.Header().Set("Access-Control-Expose-Headers", "Set-Cookie")
+	middleware.SetCORSHeaders(c.Writer.Header(), allowOrigin, &msp.config.CORS)
 
 	msp.logger.Debug("CORS headers set", "origin", allowOrigin)
 }
 
+// rejectLongURL answers 414 when the request's path and query exceed the
+// configured limit, before any authentication or upstream work is done.
+// It runs after the CORS headers are set so browsers can read the error.
+func (msp *MainServiceProxy) rejectLongURL(c *gin.Context) bool {
+	limit := msp.config.Proxy.MaxURLLength
+	length := len(c.Request.URL.RequestURI())
+	if limit <= 0 || length <= limit {
+		return false
+	}
+
+	msp.logger.Warn("Rejected proxy request with overlong URL",
+		"length", length,
+		"max_length", limit,
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path[:min(200, len(c.Request.URL.Path))],
+		"client_ip", c.ClientIP(),
+	)
+	respond.Error(c, http.StatusRequestURITooLong, "uri_too_long", "Request URL is too long", map[string]interface{}{
+		"length":     length,
+		"max_length": limit,
+	})
+	return true
+}
+
+// usePublicCORS reports whether a request gets the public CORS policy: it
+// targets a public path from a public origin without credentials. Preflights
+// never carry credentials, so those from origins on the credentialed
+// allowlist keep the credentialed policy their actual request may need.
+func (msp *MainServiceProxy) usePublicCORS(c *gin.Context, origin string) bool {
+	if origin == "" || !msp.config.CORS.AllowsPublicOrigin(origin) {
+		return false
+	}
+	if !msp.isPublicPath(c.Request.URL.Path) || middleware.HasCredentials(c.Request) {
+		return false
+	}
+	if c.Request.Method == http.MethodOptions && slices.Contains(msp.config.CORS.AllowedOrigins, origin) {
+		return false
+	}
+	return true
+}
+
 func (msp *MainServiceProxy) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -297,6 +569,15 @@ func (msp *MainServiceProxy) Handler() gin.HandlerFunc {
 			return
 		}
 
+		if msp.rejectLongURL(c) {
+			return
+		}
+
+		if msp.isPublicPath(c.Request.URL.Path) {
+			msp.servePublic(c)
+			return
+		}
+
 		// Authenticate request
 		user, err := msp.authenticateRequest(c)
 		if err != nil {
@@ -310,16 +591,21 @@ func (msp *MainServiceProxy) Handler() gin.HandlerFunc {
 				"user_id", user.UserID,
 				"status", user.Status,
 			)
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "account_inactive",
-				"message": "Account is not active",
-			})
+			if statusErr := msp.authService.AccountStatusError(user.Status); statusErr != nil {
+				respond.Error(c, http.StatusForbidden, string(statusErr.Type), statusErr.Message, statusErr.Details)
+				return
+			}
+			respond.Error(c, http.StatusForbidden, "account_inactive", "Account is not active", nil)
 			return
 		}
 
-		// **FIX: Header'ları request'e ekle**
-		c.Request.Header.Set("X-User-ID", user.UserID)
-		c.Request.Header.Set("X-User-Role", string(user.Role))
+		// Drop client-supplied identity headers before injecting our own
+		msp.sanitizeHeaders(c)
+		if err := msp.setIdentityHeaders(c, user); err != nil {
+			msp.logger.Error("Failed to sign auth context", "user_id", user.UserID, "error", err)
+			respond.Error(c, http.StatusInternalServerError, "internal_error", "Failed to forward request", nil)
+			return
+		}
 
 		// Log slow requests
 		defer func() {
@@ -333,14 +619,163 @@ func (msp *MainServiceProxy) Handler() gin.HandlerFunc {
 			}
 		}()
 
-		// Proxy the request
-		msp.proxy.ServeHTTP(c.Writer, c.Request)
+		msp.serve(c)
+	}
+}
+
+// serve forwards the request upstream. The outgoing request carries the
+// incoming request's context, so a client disconnect cancels the upstream
+// round trip, including a response body still being streamed.
+func (msp *MainServiceProxy) serve(c *gin.Context) {
+	if !msp.bufferSignedBody(c) {
+		return
+	}
+
+	msp.proxy.ServeHTTP(c.Writer, c.Request)
+
+	if errors.Is(c.Request.Context().Err(), context.Canceled) {
+		metrics.RecordProxyClientCanceled()
+	}
+}
+
+// bufferSignedBody reads the request body into memory when requests are
+// signed, since the signature covers it. Bodies over the configured limit
+// are answered with 413 and unreadable ones with 502, rather than forwarding
+// a request whose signature does not match what the upstream receives.
+func (msp *MainServiceProxy) bufferSignedBody(c *gin.Context) bool {
+	if msp.config.Proxy.SigningSecret == "" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return true
+	}
+
+	limit := msp.config.Proxy.MaxSignedBodyBytes
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respond.Error(c, http.StatusRequestEntityTooLarge, "payload_too_large", "Request body is too large", map[string]interface{}{
+				"max_bytes": limit,
+			})
+			return false
+		}
+		msp.logger.Warn("Failed to read request body for signing", "path", c.Request.URL.Path, "error", err)
+		respond.Error(c, http.StatusBadGateway, "bad_gateway", "Failed to forward request", nil)
+		return false
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	return true
+}
+
+// isPublicPath reports whether a request path falls under one of the
+// configured public prefixes, matched on whole path segments so that
+// "/demo" covers "/demo/..." but not "/demo2/...". Paths that are not in
+// canonical form never match, so dot segments cannot climb out of a public
+// prefix.
+func (msp *MainServiceProxy) isPublicPath(requestPath string) bool {
+	if len(msp.config.Proxy.PublicPathPrefixes) == 0 {
+		return false
+	}
+
+	trimmed := strings.TrimPrefix(requestPath, "/api/v1/proxy")
+	if cleaned := path.Clean(trimmed); cleaned != trimmed && cleaned+"/" != trimmed {
+		return false
 	}
+
+	for _, prefix := range msp.config.Proxy.PublicPathPrefixes {
+		if trimmed == prefix || strings.HasPrefix(trimmed, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// servePublic forwards a request to a public path without authentication.
+// Client-supplied identity headers are still stripped and none are injected,
+// so the upstream sees the request as anonymous.
+func (msp *MainServiceProxy) servePublic(c *gin.Context) {
+	msp.sanitizeHeaders(c)
+
+	msp.logger.Info("Anonymous proxy request",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"client_ip", c.ClientIP(),
+		"user_agent", c.Request.UserAgent(),
+	)
+
+	msp.serve(c)
+}
+
+// sanitizeHeaders removes the configured untrusted headers from the incoming
+// request. When the caller came through a trusted proxy, X-Forwarded-For is
+// reset to the resolved client IP; the reverse proxy then appends the peer
+// address as usual.
+func (msp *MainServiceProxy) sanitizeHeaders(c *gin.Context) {
+	clientIP := c.ClientIP()
+
+	for _, header := range msp.config.Proxy.StripHeaders {
+		if values := c.Request.Header.Values(header); len(values) > 0 {
+			msp.logger.Debug("Stripping client-supplied header", "header", header)
+		}
+		c.Request.Header.Del(header)
+	}
+
+	if c.Request.Header.Get("X-Forwarded-For") == "" && clientIP != "" && clientIP != c.RemoteIP() {
+		c.Request.Header.Set("X-Forwarded-For", clientIP)
+	}
+}
+
+// setIdentityHeaders tells the upstream who the user is, through the signed
+// X-Auth-Context token when a secret is configured and through the plain
+// X-User-ID and X-User-Role headers unless they are disabled
+func (msp *MainServiceProxy) setIdentityHeaders(c *gin.Context, user *model.User) error {
+	if msp.config.Proxy.PlainIdentityHeaders {
+		c.Request.Header.Set("X-User-ID", user.UserID)
+		c.Request.Header.Set("X-User-Role", string(user.Role))
+	}
+
+	if msp.config.Proxy.AuthContextSecret == "" {
+		return nil
+	}
+
+	token, err := signing.SignAuthContext([]byte(msp.config.Proxy.AuthContextSecret), signing.AuthContext{
+		UserID:    user.UserID,
+		Role:      string(user.Role),
+		SessionID: c.GetString("session_id"),
+		Metadata:  msp.forwardedMetadata(c),
+	}, time.Now(), time.Duration(msp.config.Proxy.AuthContextTTL)*time.Second)
+	if err != nil {
+		return err
+	}
+	c.Request.Header.Set(signing.HeaderAuthContext, token)
+	return nil
+}
+
+// forwardedMetadata returns the configured session metadata keys of the
+// request's session, or nil for bearer requests and sessions without them
+func (msp *MainServiceProxy) forwardedMetadata(c *gin.Context) map[string]interface{} {
+	session, ok := c.Value("session").(*model.Session)
+	if !ok || len(msp.config.Proxy.AuthContextMetadata) == 0 {
+		return nil
+	}
+
+	var metadata map[string]interface{}
+	for _, key := range msp.config.Proxy.AuthContextMetadata {
+		value, ok := session.Metadata[key]
+		if !ok {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		metadata[key] = value
+	}
+	return metadata
 }
 
 func (msp *MainServiceProxy) authenticateRequest(c *gin.Context) (*model.User, error) {
 	// 1. Try session authentication first (highest priority)
-	if sessionID, err := c.Cookie("session_id"); err == nil && sessionID != "" {
+	if sessionID, err := c.Cookie(msp.config.Cookie.Name); err == nil && sessionID != "" {
 		msp.logger.Debug("Attempting session cookie authentication",
 			"session_id", sessionID[:min(8, len(sessionID))],
 		)
@@ -349,15 +784,29 @@ func (msp *MainServiceProxy) authenticateRequest(c *gin.Context) (*model.User, e
 		if err == nil && session != nil {
 			user, err := msp.authService.GetUserByUserID(c.Request.Context(), session.UserID)
 			if err == nil {
-
-				msp.updateSessionCookie(c, session)
+				if bearerUser := msp.bearerUser(c); bearerUser != nil && bearerUser.UserID != user.UserID {
+					return nil, &conflictingCredentialsError{sessionUserID: user.UserID, bearerUserID: bearerUser.UserID}
+				}
+				if msp.sessionService.IsInGracePeriod(session) {
+					// Keep the cookie as is; the client must re-authenticate
+					c.Header(middleware.SessionExpiringHeader, "true")
+				} else {
+					msp.updateSessionCookie(c, session)
+				}
 				msp.logger.Debug("Session cookie authentication successful",
 					"user_id", user.UserID,
 				)
+				c.Set("session_id", session.SessionID)
+				c.Set("session", session)
 				return user, nil
 			}
 		}
 
+		// Stop the browser from resending a cookie for a session that is gone
+		if sharedErrors.IsType(err, sharedErrors.ErrorTypeNotFound) {
+			cookie.ClearSession(c, &msp.config.Cookie)
+		}
+
 		msp.logger.Warn("Session cookie authentication failed",
 			"session_id", sessionID[:min(8, len(sessionID))],
 			"error", err,
@@ -384,22 +833,54 @@ func (msp *MainServiceProxy) authenticateRequest(c *gin.Context) (*model.User, e
 			msp.logger.Warn("Bearer token authentication failed",
 				"error", err,
 			)
+			// A valid token for an account that may not be used is a 403
+			if middleware.IdentifiedError(err) != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return nil, fmt.Errorf("no valid authentication found")
 }
 
+// conflictingCredentialsError reports a session cookie and bearer token
+// that belong to different users
+type conflictingCredentialsError struct {
+	sessionUserID string
+	bearerUserID  string
+}
+
+func (e *conflictingCredentialsError) Error() string {
+	return "session cookie and bearer token belong to different users"
+}
+
+// bearerUser returns the user of the request's bearer token, or nil when it
+// carries no valid one
+func (msp *MainServiceProxy) bearerUser(c *gin.Context) *model.User {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	user, err := msp.authService.VerifyToken(c.Request.Context(), token)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
 func (msp *MainServiceProxy) handleAuthError(c *gin.Context, err error) {
+	var conflict *conflictingCredentialsError
+	if errors.As(err, &conflict) {
+		middleware.RejectConflictingCredentials(c, msp.logger, conflict.sessionUserID, conflict.bearerUserID)
+		return
+	}
+
 	msp.logger.Warn("Authentication failed for proxy request",
 		"error", err,
 		"path", c.Request.URL.Path,
 	)
 
-	c.JSON(http.StatusUnauthorized, gin.H{
-		"error":   "authentication_required",
-		"message": "Valid Bearer token or session required",
-	})
+	middleware.RejectAuthFailure(c, err, "authentication_required", "Valid Bearer token or session required", nil)
 }
 
 func min(a, b int) int {
@@ -410,27 +891,5 @@ func min(a, b int) int {
 }
 
 func (msp *MainServiceProxy) updateSessionCookie(c *gin.Context, session *model.Session) {
-	cookieCfg := msp.config.Cookie
-	maxAge := int(time.Until(session.ExpiresAt).Seconds())
-
-	var sameSite http.SameSite
-	switch cookieCfg.SameSite {
-	case "Strict":
-		sameSite = http.SameSiteStrictMode
-	case "None":
-		sameSite = http.SameSiteNoneMode
-	default:
-		sameSite = http.SameSiteLaxMode
-	}
-
-	c.SetSameSite(sameSite)
-	c.SetCookie(
-		cookieCfg.Name,
-		session.SessionID,
-		maxAge,
-		"/",
-		cookieCfg.Domain,
-		cookieCfg.Secure,
-		cookieCfg.HTTPOnly,
-	)
+	cookie.SetSession(c, &msp.config.Cookie, session.SessionID, session.ExpiresAt)
 }
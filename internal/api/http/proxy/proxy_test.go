@@ -0,0 +1,519 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/service"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/pkg/config"
+	"github.com/histopathai/auth-service/pkg/signing"
+)
+
+const testCookieName = "session"
+
+// fakeAuthenticator resolves bearer tokens and user IDs from fixed maps
+type fakeAuthenticator struct {
+	tokens map[string]*model.User
+	users  map[string]*model.User
+}
+
+func (f *fakeAuthenticator) VerifyToken(ctx context.Context, idToken string) (*model.User, error) {
+	user, ok := f.tokens[idToken]
+	if !ok {
+		return nil, sharedErrors.NewUnauthorizedError("invalid token")
+	}
+	return user, nil
+}
+
+func (f *fakeAuthenticator) GetUserByUserID(ctx context.Context, userID string) (*model.User, error) {
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, sharedErrors.NewNotFoundError("user not found")
+	}
+	return user, nil
+}
+
+func (f *fakeAuthenticator) AccountStatusError(status model.UserStatus) *sharedErrors.Err {
+	if status != model.StatusSuspended {
+		return nil
+	}
+	return sharedErrors.NewAccountStatusError(sharedErrors.ErrorTypeAccountSuspended, "Account is suspended", nil)
+}
+
+// fakeSessionService serves sessions from a fixed map; sessions past their
+// expiry are reported the way SessionServiceImpl reports them. Methods the
+// proxy does not call panic through the nil embedded interface.
+type fakeSessionService struct {
+	service.SessionService
+	sessions map[string]*model.Session
+}
+
+func (f *fakeSessionService) ValidateAndExtend(ctx context.Context, sessionID string) (*model.Session, error) {
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, sharedErrors.NewNotFoundError("session_not_found")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, sharedErrors.NewNotFoundError("session_expired")
+	}
+	return session, nil
+}
+
+func (f *fakeSessionService) IsInGracePeriod(session *model.Session) bool {
+	return false
+}
+
+func newTestProxy(auth *fakeAuthenticator, sessions *fakeSessionService) *MainServiceProxy {
+	return &MainServiceProxy{
+		authService:    auth,
+		sessionService: sessions,
+		config:         &config.Config{Cookie: config.CookieConfig{Name: testCookieName}},
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// withUpstream points msp at a test server running upstream and wires the
+// reverse proxy the way NewMainServiceProxy does, without an ID token source
+func withUpstream(t *testing.T, msp *MainServiceProxy, upstream http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+
+	msp.targetURL, _ = url.Parse(server.URL + "/")
+	msp.proxy = &httputil.ReverseProxy{
+		Director:       msp.director,
+		ModifyResponse: msp.modifyResponse,
+		ErrorHandler:   msp.errorHandler,
+	}
+}
+
+// closeNotifyRecorder implements the CloseNotifier gin's writer asserts
+// when the reverse proxy asks for it
+type closeNotifyRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r closeNotifyRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+func newTestContext(cookie string, bearer string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(closeNotifyRecorder{recorder})
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/proxy/slides", nil)
+	if cookie != "" {
+		c.Request.AddCookie(&http.Cookie{Name: testCookieName, Value: cookie})
+	}
+	if bearer != "" {
+		c.Request.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return c, recorder
+}
+
+func TestAuthenticateRequest(t *testing.T) {
+	cookieUser := &model.User{UserID: "cookie-user", Status: model.StatusActive, Role: model.RoleUser}
+	bearerUser := &model.User{UserID: "bearer-user", Status: model.StatusActive, Role: model.RoleUser}
+
+	auth := &fakeAuthenticator{
+		tokens: map[string]*model.User{"valid-token": bearerUser},
+		users:  map[string]*model.User{cookieUser.UserID: cookieUser, bearerUser.UserID: bearerUser},
+	}
+	sessions := &fakeSessionService{sessions: map[string]*model.Session{
+		"valid-session":   {SessionID: "valid-session", UserID: cookieUser.UserID, ExpiresAt: time.Now().Add(time.Hour)},
+		"expired-session": {SessionID: "expired-session", UserID: bearerUser.UserID, ExpiresAt: time.Now().Add(-time.Hour)},
+	}}
+
+	tests := []struct {
+		name          string
+		cookie        string
+		bearer        string
+		wantUserID    string
+		wantSessionID string
+		wantErr       bool
+	}{
+		{name: "valid session cookie", cookie: "valid-session", wantUserID: cookieUser.UserID, wantSessionID: "valid-session"},
+		{name: "expired session falls back to bearer token", cookie: "expired-session", bearer: "valid-token", wantUserID: bearerUser.UserID},
+		{name: "invalid session and bearer token", cookie: "unknown-session", bearer: "invalid-token", wantErr: true},
+		{name: "no credentials", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msp := newTestProxy(auth, sessions)
+			c, _ := newTestContext(tt.cookie, tt.bearer)
+
+			user, err := msp.authenticateRequest(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("authenticateRequest() = %v, want an error", user.UserID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authenticateRequest() error = %v", err)
+			}
+			if user.UserID != tt.wantUserID {
+				t.Errorf("authenticateRequest() user = %s, want %s", user.UserID, tt.wantUserID)
+			}
+			if got := c.GetString("session_id"); got != tt.wantSessionID {
+				t.Errorf("session_id = %q, want %q", got, tt.wantSessionID)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRequestExpiredSessionClearsCookie(t *testing.T) {
+	user := &model.User{UserID: "user-1", Status: model.StatusActive}
+	msp := newTestProxy(
+		&fakeAuthenticator{users: map[string]*model.User{user.UserID: user}},
+		&fakeSessionService{sessions: map[string]*model.Session{
+			"expired-session": {SessionID: "expired-session", UserID: user.UserID, ExpiresAt: time.Now().Add(-time.Hour)},
+		}},
+	)
+	c, recorder := newTestContext("expired-session", "")
+
+	if _, err := msp.authenticateRequest(c); err == nil {
+		t.Fatal("authenticateRequest() accepted an expired session")
+	}
+	cleared := false
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == testCookieName && cookie.MaxAge < 0 {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Error("expired session cookie was not cleared")
+	}
+}
+
+func TestHandlerRejectsSuspendedUser(t *testing.T) {
+	suspended := &model.User{UserID: "suspended-user", Status: model.StatusSuspended, Role: model.RoleUser}
+	auth := &fakeAuthenticator{
+		tokens: map[string]*model.User{"suspended-token": suspended},
+		users:  map[string]*model.User{suspended.UserID: suspended},
+	}
+	sessions := &fakeSessionService{sessions: map[string]*model.Session{
+		"suspended-session": {SessionID: "suspended-session", UserID: suspended.UserID, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+
+	tests := []struct {
+		name   string
+		cookie string
+		bearer string
+	}{
+		{name: "session cookie", cookie: "suspended-session"},
+		{name: "bearer token", bearer: "suspended-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msp := newTestProxy(auth, sessions)
+			c, recorder := newTestContext(tt.cookie, tt.bearer)
+
+			msp.Handler()(c)
+
+			if recorder.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestHandlerRejectsUnauthenticatedRequests(t *testing.T) {
+	user := &model.User{UserID: "user-1", Status: model.StatusActive, Role: model.RoleUser}
+	auth := &fakeAuthenticator{users: map[string]*model.User{user.UserID: user}}
+	sessions := &fakeSessionService{sessions: map[string]*model.Session{
+		"expired-session": {SessionID: "expired-session", UserID: user.UserID, ExpiresAt: time.Now().Add(-time.Hour)},
+	}}
+
+	tests := []struct {
+		name   string
+		cookie string
+		bearer string
+	}{
+		{name: "no credentials"},
+		{name: "invalid bearer token", bearer: "forged-token"},
+		{name: "unknown session", cookie: "forged-session"},
+		{name: "expired session", cookie: "expired-session"},
+		{name: "invalid session and bearer token", cookie: "forged-session", bearer: "forged-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msp := newTestProxy(auth, sessions)
+			c, recorder := newTestContext(tt.cookie, tt.bearer)
+
+			msp.Handler()(c)
+
+			if recorder.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+			}
+			if !strings.Contains(recorder.Body.String(), `"authentication_required"`) {
+				t.Errorf("body = %s, want the authentication_required error", recorder.Body)
+			}
+		})
+	}
+}
+
+func TestModifyResponseConditionalImageRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		upstreamETag string
+		ifNoneMatch  string
+		wantStatus   int
+		wantETag     string
+	}{
+		{name: "matching upstream ETag", upstreamETag: `"v2"`, ifNoneMatch: `"v2"`, wantStatus: http.StatusNotModified, wantETag: `"v2"`},
+		{name: "stale upstream ETag", upstreamETag: `"v2"`, ifNoneMatch: `"v1"`, wantStatus: http.StatusOK, wantETag: `"v2"`},
+		{name: "no upstream ETag", ifNoneMatch: `"/api/v1/images/slide-1"`, wantStatus: http.StatusOK},
+		{name: "wildcard without upstream ETag", ifNoneMatch: "*", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msp := newTestProxy(&fakeAuthenticator{}, &fakeSessionService{})
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/images/slide-1", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    req,
+			}
+			if tt.upstreamETag != "" {
+				resp.Header.Set("ETag", tt.upstreamETag)
+			}
+
+			if err := msp.modifyResponse(resp); err != nil {
+				t.Fatalf("modifyResponse() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestSignedRequestBodies(t *testing.T) {
+	const secret = "signing-secret"
+
+	tests := []struct {
+		name       string
+		body       string
+		wantServed bool
+		wantStatus int
+	}{
+		{name: "body within the limit is signed", body: `{"a":1}`, wantServed: true},
+		{name: "body over the limit is rejected", body: `{"slide":"too large"}`, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msp := newTestProxy(&fakeAuthenticator{}, &fakeSessionService{})
+			msp.config.Proxy.SigningSecret = secret
+			msp.config.Proxy.MaxSignedBodyBytes = 16
+
+			c, recorder := newTestContext("", "")
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/proxy/slides", strings.NewReader(tt.body))
+
+			served := msp.bufferSignedBody(c)
+			if served != tt.wantServed {
+				t.Fatalf("bufferSignedBody() = %v, want %v", served, tt.wantServed)
+			}
+			if !served {
+				if recorder.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+				}
+				return
+			}
+
+			msp.signRequest(c.Request)
+			body, _ := io.ReadAll(c.Request.Body)
+			if string(body) != tt.body {
+				t.Errorf("forwarded body = %q, want %q", body, tt.body)
+			}
+			err := signing.Verify([]byte(secret), c.Request.Method, c.Request.URL.RequestURI(), body,
+				c.Request.Header.Get(signing.HeaderTimestamp), c.Request.Header.Get(signing.HeaderSignature), time.Minute, time.Now())
+			if err != nil {
+				t.Errorf("signature does not cover the forwarded body: %v", err)
+			}
+		})
+	}
+}
+
+func TestHandlerOverwritesForgedIdentityHeaders(t *testing.T) {
+	user := &model.User{UserID: "user-1", Status: model.StatusActive, Role: model.RoleUser}
+	msp := newTestProxy(
+		&fakeAuthenticator{tokens: map[string]*model.User{"valid-token": user}},
+		&fakeSessionService{},
+	)
+	msp.config.Proxy.PlainIdentityHeaders = true
+	msp.config.Proxy.StripHeaders = []string{"X-User-ID", "X-User-Role", "X-Session-ID", "X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Proto", "X-Real-IP", "X-Auth-Context"}
+
+	var forwarded http.Header
+	withUpstream(t, msp, func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+	})
+
+	c, recorder := newTestContext("", "valid-token")
+	c.Request.Header.Set("X-User-Role", "admin")
+	c.Request.Header.Set("X-User-ID", "admin-1")
+	c.Request.Header.Set("X-Session-ID", "forged-session")
+	c.Request.Header.Set("X-Forwarded-Host", "evil.example.com")
+
+	msp.Handler()(c)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	want := map[string]string{
+		"X-User-Role":      string(model.RoleUser),
+		"X-User-ID":        user.UserID,
+		"X-Session-ID":     "",
+		"X-Forwarded-Host": c.Request.Host,
+	}
+	for header, value := range want {
+		if got := forwarded.Get(header); got != value {
+			t.Errorf("forwarded %s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+// countingBody is a response body of size bytes that counts how many have
+// been read
+type countingBody struct {
+	size int64
+	read int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	if b.read >= b.size {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if remaining := b.size - b.read; int64(n) > remaining {
+		n = int(remaining)
+	}
+	for i := range p[:n] {
+		p[i] = 'x'
+	}
+	b.read += int64(n)
+	return n, nil
+}
+
+func (b *countingBody) Close() error {
+	return nil
+}
+
+func TestModifyResponseBoundsBufferedBody(t *testing.T) {
+	const size = 64 << 20
+
+	tests := []struct {
+		name     string
+		status   int
+		wantRead int64
+	}{
+		{name: "successful response is not read", status: http.StatusOK, wantRead: 0},
+		{name: "error response is read up to the cap", status: http.StatusInternalServerError, wantRead: maxErrorBodySize + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msp := newTestProxy(&fakeAuthenticator{}, &fakeSessionService{})
+			body := &countingBody{size: size}
+			resp := &http.Response{
+				StatusCode:    tt.status,
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+				Body:          body,
+				ContentLength: size,
+				Request:       httptest.NewRequest(http.MethodGet, "/api/v1/images/slide-1", nil),
+			}
+
+			if err := msp.modifyResponse(resp); err != nil {
+				t.Fatalf("modifyResponse() error = %v", err)
+			}
+			if body.read > tt.wantRead {
+				t.Errorf("modifyResponse() read %d bytes of the body, want at most %d", body.read, tt.wantRead)
+			}
+
+			// The client still receives the whole body
+			forwarded, _ := io.Copy(io.Discard, resp.Body)
+			if forwarded != size {
+				t.Errorf("forwarded %d bytes, want %d", forwarded, size)
+			}
+		})
+	}
+}
+
+func TestDirectorRewritesPaths(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantPath string
+	}{
+		{path: "/api/v1/proxy", wantPath: "/api/v1/"},
+		{path: "/api/v1/proxy/", wantPath: "/api/v1/"},
+		{path: "/api/v1/proxy/foo", wantPath: "/api/v1/foo"},
+		{path: "/api/v1/proxy/foo/", wantPath: "/api/v1/foo/"},
+		{path: "/api/v1/proxy//foo//bar", wantPath: "/api/v1/foo/bar"},
+		{path: "/api/v1/proxy/123e4567-e89b-12d3-a456-426614174000/tiles/0/0/0.jpg", wantPath: "/api/v1/proxy/123e4567-e89b-12d3-a456-426614174000/tiles/0/0/0.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			msp := newTestProxy(&fakeAuthenticator{}, &fakeSessionService{})
+			msp.targetURL, _ = url.Parse("http://upstream.internal/")
+			req := httptest.NewRequest(http.MethodGet, tt.path+"?page=2", nil)
+
+			msp.director(req)
+
+			if req.URL.Path != tt.wantPath {
+				t.Errorf("upstream path = %q, want %q", req.URL.Path, tt.wantPath)
+			}
+			if req.URL.Host != "upstream.internal" || req.URL.RawQuery != "page=2" {
+				t.Errorf("upstream URL = %s, want the upstream host and the original query", req.URL)
+			}
+		})
+	}
+}
+
+func TestIsPublicPath(t *testing.T) {
+	msp := newTestProxy(&fakeAuthenticator{}, &fakeSessionService{})
+	msp.config.Proxy.PublicPathPrefixes = []string{"/demo", "/public/"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/api/v1/proxy/demo", want: true},
+		{path: "/api/v1/proxy/demo/", want: true},
+		{path: "/api/v1/proxy/demo/slides/1", want: true},
+		{path: "/api/v1/proxy/public", want: false},
+		{path: "/api/v1/proxy/public/tiles/0/0", want: true},
+		{path: "/api/v1/proxy/demo-private/slides", want: false},
+		{path: "/api/v1/proxy/demo2/slides", want: false},
+		{path: "/api/v1/proxy/publicity/x", want: false},
+		{path: "/api/v1/proxy/demo/../admin", want: false},
+		{path: "/api/v1/proxy/slides", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := msp.isPublicPath(tt.path); got != tt.want {
+			t.Errorf("isPublicPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
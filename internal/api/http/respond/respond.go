@@ -0,0 +1,42 @@
+// Package respond writes JSON responses in the service's standard envelope.
+// Successful responses carry their payload under "data"; errors use
+// response.ErrorResponse. Handlers, middleware and the proxy all write
+// through this package so every endpoint returns a predictable shape.
+package respond
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/histopathai/auth-service/internal/api/http/dto/response"
+)
+
+// Success writes data wrapped in the success envelope
+func Success(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, response.SuccessResponse{
+		Data: data,
+	})
+}
+
+// List writes a paginated list in the success envelope
+func List(c *gin.Context, data interface{}, pagination response.PaginationResponse) {
+	c.JSON(http.StatusOK, response.ListResponse{
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
+// Error writes an error response
+func Error(c *gin.Context, statusCode int, errType string, message string, details interface{}) {
+	c.JSON(statusCode, response.ErrorResponse{
+		ErrorType: errType,
+		Message:   message,
+		Details:   details,
+	})
+}
+
+// AbortWithError writes an error response and stops the handler chain
+func AbortWithError(c *gin.Context, statusCode int, errType string, message string, details interface{}) {
+	Error(c, statusCode, errType, message, details)
+	c.Abort()
+}
@@ -2,13 +2,17 @@ package router
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/histopathai/auth-service/internal/api/http/handler"
 	"github.com/histopathai/auth-service/internal/api/http/middleware"
 	"github.com/histopathai/auth-service/internal/api/http/proxy"
 	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/domain/repository"
 	"github.com/histopathai/auth-service/internal/service"
+	"github.com/histopathai/auth-service/internal/shared/metrics"
 	"github.com/histopathai/auth-service/pkg/config"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -24,22 +28,33 @@ type Router struct {
 	authMiddleware *middleware.AuthMiddleware
 	logger         *slog.Logger
 	mainProxy      *proxy.MainServiceProxy
+	rateLimiters   []*middleware.RateLimiter
+	visitorLimits  middleware.VisitorLimits
+	rateLimitStore repository.RateLimitStore
 	Config         *config.Config
 }
 
 type RouterConfig struct {
 	AuthService    *service.AuthService
-	SessionService *service.SessionService
+	SessionService service.SessionService
 	Logger         *slog.Logger
 	MainServiceURL string
 	Config         *config.Config
+	// RateLimitStore backs limits that must hold across replicas
+	RateLimitStore repository.RateLimitStore
+	// HealthChecks are run by the readiness probe, keyed by dependency name
+	HealthChecks map[string]handler.DependencyCheck
+	// StartedAt is when the service started, for reporting uptime
+	StartedAt time.Time
+	// SessionLocator adds locations to session listings; nil leaves them out
+	SessionLocator *service.SessionLocator
 }
 
 func NewRouter(config *RouterConfig, appConfig *config.Config) (*Router, error) {
-	authHandler := handler.NewAuthHandler(*config.AuthService, config.Logger)
+	authHandler := handler.NewAuthHandler(*config.AuthService, appConfig, config.Logger)
 	adminHandler := handler.NewAdminHandler(*config.AuthService, config.Logger)
-	healthHandler := handler.NewHealthHandler(config.Logger)
-	sessionHandler := handler.NewSessionHandler(config.SessionService, config.AuthService, appConfig, config.Logger)
+	healthHandler := handler.NewHealthHandler(config.HealthChecks, config.StartedAt, config.Logger)
+	sessionHandler := handler.NewSessionHandler(config.SessionService, config.AuthService, config.SessionLocator, appConfig, config.Logger)
 
 	authMiddleware := middleware.NewAuthMiddleware(
 		*config.AuthService,
@@ -48,16 +63,23 @@ func NewRouter(config *RouterConfig, appConfig *config.Config) (*Router, error)
 		config.Logger,
 	)
 
-	// Pass config to proxy
-	mainProxy, err := proxy.NewMainServiceProxy(
-		config.MainServiceURL,
-		config.AuthService,
-		config.SessionService,
-		config.Config,
-		config.Logger,
-	)
-	if err != nil {
-		return nil, err
+	// Deployments fronting the main service with their own gateway run
+	// without the proxy
+	var mainProxy *proxy.MainServiceProxy
+	if appConfig.Proxy.Enabled {
+		var err error
+		mainProxy, err = proxy.NewMainServiceProxy(
+			config.MainServiceURL,
+			config.AuthService,
+			config.SessionService,
+			config.Config,
+			config.Logger,
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		config.Logger.Info("Main service proxy disabled")
 	}
 
 	return &Router{
@@ -68,33 +90,73 @@ func NewRouter(config *RouterConfig, appConfig *config.Config) (*Router, error)
 		sessionHandler: sessionHandler,
 		authMiddleware: authMiddleware,
 		mainProxy:      mainProxy,
-		logger:         config.Logger,
+		rateLimitStore: config.RateLimitStore,
+		visitorLimits: middleware.VisitorLimits{
+			IdleTimeout: time.Duration(appConfig.Server.RateLimitVisitorIdleTimeout) * time.Second,
+			MaxVisitors: appConfig.Server.RateLimitMaxVisitors,
+		},
+		logger: config.Logger,
 	}, nil
 }
 
 func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 
+	// Gin's debug output bypasses slog and would break JSON log parsing
+	if appConfig.Logging.Format == "json" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Free-form request fields such as session metadata would otherwise
+	// decode numbers as float64 and lose precision beyond 2^53
+	binding.EnableDecoderUseNumber = true
+
 	if len(appConfig.Security.TrustedProxies) > 0 {
 		r.engine.SetTrustedProxies(appConfig.Security.TrustedProxies)
 	}
 
 	// Global middleware
-	r.engine.Use(middleware.RecoveryMiddleware())
-	r.engine.Use(middleware.LoggingMiddleware())
+	r.engine.Use(middleware.RecoveryMiddleware(r.logger))
+	r.engine.Use(middleware.RequestID())
+	r.engine.Use(middleware.LoggingMiddleware(&appConfig.Logging, r.logger))
+	if len(appConfig.Logging.BodyLogPaths) > 0 {
+		r.logger.Warn("Request and response body logging enabled",
+			"paths", appConfig.Logging.BodyLogPaths,
+			"sample_rate", appConfig.Logging.BodyLogSampleRate,
+		)
+		r.engine.Use(middleware.BodyLogging(&appConfig.Logging, r.logger))
+	}
 	r.engine.Use(middleware.CORSMiddleware(appConfig))
 
 	// Rate limiter
-	rateLimiter := middleware.NewRateLimiter(100, 200)
+	rateLimiter := r.newRateLimiter("global", 100, 200, time.Second)
+	rateLimiter.SetSoftLimit(appConfig.Server.RateLimitSoftThreshold, r.logger)
 	r.engine.Use(rateLimiter.RateLimit())
 
+	// Validated at startup, so an error cannot occur here
+	routeLimits, _ := appConfig.Server.RouteRateLimitOverrides()
+
 	r.engine.GET("/favicon.ico", func(c *gin.Context) {
 		c.Status(204)
 	})
 
+	// Served at the root for deploy checks and under /api/v1 as documented
+	r.engine.GET("/version", r.healthHandler.Version)
+
 	r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	requestTimeout := middleware.Timeout(time.Duration(appConfig.Server.RequestTimeout) * time.Second)
+	adminTimeout := middleware.Timeout(time.Duration(appConfig.Server.AdminRequestTimeout) * time.Second)
+	proxyTimeout := middleware.Timeout(time.Duration(appConfig.Server.ProxyRequestTimeout) * time.Second)
+
 	// API v1 routes
 	v1 := r.engine.Group("/api/v1")
+	if appConfig.Server.RequireJSONContentType {
+		// The user import takes CSV and the proxy passes bodies through
+		v1.Use(middleware.RequireJSONContentType(
+			"/api/v1/admin/users/import",
+			"/api/v1/proxy/*proxyPath",
+		))
+	}
 	{
 		// Health check routes (no auth required)
 		health := v1.Group("/health")
@@ -102,25 +164,49 @@ func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 			health.GET("", r.healthHandler.Health)
 			health.GET("/ready", r.healthHandler.Ready)
 		}
+		v1.GET("/version", r.healthHandler.Version)
 
 		// Auth routes
-		auth := v1.Group("/auth")
+		auth := v1.Group("/auth", requestTimeout)
 		{
 			// Public endpoints (no authentication required)
-			auth.POST("/register", r.authHandler.Register)
+			// Registrations create Firebase accounts, so they are limited
+			// per IP across replicas more strictly than other endpoints
+			register := r.routeRateLimit(routeLimits, config.RateLimitGroupRegister)
+			if limit := appConfig.Registration.RateLimitPerHour; limit > 0 && r.rateLimitStore != nil {
+				register = append(register, middleware.SharedRateLimit(r.rateLimitStore, "register", limit, time.Hour, r.logger))
+			}
+			auth.POST("/register", append(register, r.authHandler.Register)...)
 			auth.POST("/verify", r.authHandler.VerifyToken)
 
+			// Both send email, so they are limited like the password route
+			resetLimit := r.routeRateLimit(routeLimits, config.RateLimitGroupPassword)
+			auth.POST("/password-reset", append(resetLimit, r.authHandler.RequestPasswordReset)...)
+			verificationLimit := r.routeRateLimit(routeLimits, config.RateLimitGroupPassword)
+			auth.POST("/verification-email", append(verificationLimit, r.authMiddleware.RequireAuthOrSession(), r.authHandler.SendVerificationEmail)...)
+
+			if appConfig.Registration.EmailAvailabilityCheck {
+				emailLimiter := r.newRateLimiter(
+					"email_available",
+					appConfig.Registration.EmailAvailabilityRatePerMinute,
+					appConfig.Registration.EmailAvailabilityRatePerMinute,
+					time.Minute,
+				)
+				auth.GET("/email-available", emailLimiter.RateLimit(), r.authHandler.CheckEmailAvailability)
+			}
+
 			// Protected endpoints (require session)
 			authenticated := auth.Group("")
 			authenticated.Use(r.authMiddleware.RequireSession())
 			authenticated.Use(r.authMiddleware.RequireStatus(model.StatusActive))
 			{
-				authenticated.PUT("/password", r.authHandler.ChangePasswordSelf)
+				password := r.routeRateLimit(routeLimits, config.RateLimitGroupPassword)
+				authenticated.PUT("/password", append(password, r.authHandler.ChangePasswordSelf)...)
 			}
 		}
 
 		// User routes (protected - require session or bearer token)
-		user := v1.Group("/user")
+		user := v1.Group("/user", requestTimeout)
 		user.Use(r.authMiddleware.RequireAuthOrSession())
 		user.Use(r.authMiddleware.RequireStatus(model.StatusActive))
 		{
@@ -129,7 +215,7 @@ func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 		}
 
 			// Public user info routes (any active user can look up display name by ID)
-			users := v1.Group("/users")
+			users := v1.Group("/users", requestTimeout)
 			users.Use(r.authMiddleware.RequireAuthOrSession())
 			users.Use(r.authMiddleware.RequireStatus(model.StatusActive))
 			{
@@ -137,17 +223,21 @@ func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 			}
 
 		// Session routes
-		sessions := v1.Group("/sessions")
+		sessions := v1.Group("/sessions", requestTimeout)
 		{
 			sessions.PUT("", r.sessionHandler.CreateSession)
 
 			sessions.DELETE("/current", r.sessionHandler.Logout)
 
+			// Open to inactive accounts so a reactivation can be picked up
+			sessions.POST("/refresh-profile", r.authMiddleware.RequireSession(), r.sessionHandler.RefreshProfile)
+
 			authenticated := sessions.Group("")
 			authenticated.Use(r.authMiddleware.RequireSession())
 			authenticated.Use(r.authMiddleware.RequireStatus(model.StatusActive))
 			{
 				authenticated.GET("", r.sessionHandler.ListMySessions)
+				authenticated.GET("/all", r.sessionHandler.ListAllMySessions)
 				authenticated.GET("/stats", r.sessionHandler.GetMySessionStats)
 				authenticated.PUT("/revoke-all", r.sessionHandler.RevokeAllMySessions)
 				authenticated.PUT("/:session_id/extend", r.sessionHandler.ExtendSession)
@@ -158,7 +248,7 @@ func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 		}
 
 		// Admin routes (admin only)
-		admin := v1.Group("/admin")
+		admin := v1.Group("/admin", adminTimeout)
 		admin.Use(r.authMiddleware.RequireAuthOrSession())
 		admin.Use(r.authMiddleware.RequireRole(model.RoleAdmin))
 		admin.Use(r.authMiddleware.RequireStatus(model.StatusActive))
@@ -166,58 +256,104 @@ func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 			users := admin.Group("/users")
 			{
 				users.GET("", r.adminHandler.ListUsers)
+				users.GET("/pending", r.adminHandler.ListPendingUsers)
+				users.GET("/by-email", r.adminHandler.GetUserByEmail)
+				users.POST("/import", r.adminHandler.ImportUsers)
 				users.GET("/:user_id", r.adminHandler.GetUser)
+				users.GET("/:user_id/permissions", r.adminHandler.GetUserPermissions)
+				users.PATCH("/:user_id", r.adminHandler.UpdateUser)
 				users.POST("/:user_id/approve", r.adminHandler.ApproveUser)
 				users.POST("/:user_id/suspend", r.adminHandler.SuspendUser)
+				users.POST("/:user_id/verify-email", r.adminHandler.VerifyUserEmail)
 				users.POST("/:user_id/make-admin", r.adminHandler.MakeAdmin)
+				users.POST("/:user_id/confirm-promotion", r.adminHandler.ConfirmPromotion)
+				users.POST("/:user_id/transfer-ownership", r.adminHandler.TransferOwnership)
+				users.DELETE("/:user_id/transfer-ownership", r.adminHandler.CancelOwnershipTransfer)
 				users.PUT("/:user_id/delete", r.adminHandler.DeleteUser)
 				users.GET("/:user_id/sessions", r.sessionHandler.ListUserSessions)
 				users.DELETE("/:user_id/sessions", r.sessionHandler.RevokeAllUserSessions)
 
 			}
 
+			admin.GET("/metrics", gin.WrapH(metrics.Handler()))
+			admin.GET("/stats/users", r.adminHandler.GetUserStats)
+			admin.GET("/feature-flags", r.adminHandler.ListFeatureFlags)
+
 			adminSessions := admin.Group("/sessions")
 			{
 				adminSessions.DELETE("/:session_id", r.sessionHandler.RevokeUserSession)
 			}
 		}
 
+		// Internal service-to-service routes
+		if len(appConfig.InternalAPI.AllowedServiceAccounts) > 0 {
+			serviceAuth := middleware.NewServiceAuthMiddleware(&appConfig.InternalAPI, r.logger)
+			internal := v1.Group("/internal", requestTimeout)
+			internal.Use(serviceAuth.RequireServiceAccount())
+			{
+				internal.PATCH("/sessions/:session_id/metadata", r.sessionHandler.UpdateSessionMetadata)
+				internal.POST("/users/:user_id/transfer-ownership/ack", r.adminHandler.AcknowledgeOwnershipTransfer)
+			}
+		}
+
 		// Main service proxy routes
-		proxy := v1.Group("/proxy")
-		{
-			proxy.Any("/*proxyPath", r.mainProxy.Handler())
+		if r.mainProxy != nil {
+			proxy := v1.Group("/proxy", proxyTimeout)
+			{
+				proxy.Any("/*proxyPath", r.mainProxy.Handler())
+			}
 		}
 	}
 
-	r.logger.Info("Router setup completed",
-		"routes", []string{
-			"POST /api/v1/auth/register (public)",
-			"POST /api/v1/auth/verify (public)",
-			"PUT /api/v1/auth/password (session required)",
-			"GET /api/v1/user/profile (auth or session)",
-			"DELETE /api/v1/user/account (auth or session)",
-			"PUT /api/v1/sessions (token in body)",
-			"GET /api/v1/sessions/current (session required)",
-			"GET /api/v1/sessions (session required)",
-			"GET /api/v1/sessions/stats (session required)",
-			"PUT /api/v1/sessions/revoke-all (session required)",
-			"DELETE /api/v1/sessions/:session_id (session required)",
-			"PUT /api/v1/sessions/:session_id/extend (session required)",
-			"GET /api/v1/admin/users (admin + session or bearer)",
-			"GET /api/v1/admin/users/:user_id (admin + session or bearer)",
-			"POST /api/v1/admin/users/:user_id/approve (admin + session or bearer)",
-			"POST /api/v1/admin/users/:user_id/suspend (admin + session or bearer)",
-			"POST /api/v1/admin/users/:user_id/make-admin (admin + session or bearer)",
-			"PUT /api/v1/admin/users/:user_id/delete (admin + session or bearer)",
-			"GET /api/v1/admin/users/:user_id/sessions (admin + session or bearer)",
-			"DELETE /api/v1/admin/users/:user_id/sessions (admin + session or bearer)",
-			"DELETE /api/v1/admin/sessions/:session_id (admin + session or bearer)",
-			"GET /api/v1/users/:user_id (auth or session)",
-			"ANY /api/v1/proxy/*proxyPath (auth or session)",
-			"GET /api/v1/health (public)",
-			"GET /api/v1/health/ready (public)",
-		},
-	)
+	routes := []string{
+		"POST /api/v1/auth/register (public, rate limited per IP)",
+		"POST /api/v1/auth/verify (public)",
+		"POST /api/v1/auth/password-reset (public)",
+		"POST /api/v1/auth/verification-email (auth or session)",
+		"GET /api/v1/auth/email-available (public, rate limited)",
+		"PUT /api/v1/auth/password (session required)",
+		"GET /api/v1/user/profile (auth or session)",
+		"DELETE /api/v1/user/account (auth or session)",
+		"PUT /api/v1/sessions (token in body)",
+		"GET /api/v1/sessions/current (session required)",
+		"GET /api/v1/sessions (session required)",
+		"GET /api/v1/sessions/all (session required)",
+		"GET /api/v1/sessions/stats (session required)",
+		"PUT /api/v1/sessions/revoke-all (session required)",
+		"POST /api/v1/sessions/refresh-profile (session required)",
+		"DELETE /api/v1/sessions/:session_id (session required)",
+		"PUT /api/v1/sessions/:session_id/extend (session required)",
+		"GET /api/v1/admin/users (admin + session or bearer)",
+		"GET /api/v1/admin/users/pending (admin + session or bearer)",
+		"GET /api/v1/admin/users/by-email (admin + session or bearer)",
+		"POST /api/v1/admin/users/import (admin + session or bearer)",
+		"GET /api/v1/admin/users/:user_id (admin + session or bearer)",
+		"PATCH /api/v1/admin/users/:user_id (admin + session or bearer)",
+		"POST /api/v1/admin/users/:user_id/approve (admin + session or bearer)",
+		"POST /api/v1/admin/users/:user_id/suspend (admin + session or bearer)",
+		"POST /api/v1/admin/users/:user_id/verify-email (admin + session or bearer)",
+		"POST /api/v1/admin/users/:user_id/make-admin (admin + session or bearer)",
+		"POST /api/v1/admin/users/:user_id/confirm-promotion (admin + session or bearer)",
+		"POST /api/v1/admin/users/:user_id/transfer-ownership (admin + session or bearer)",
+		"DELETE /api/v1/admin/users/:user_id/transfer-ownership (admin + session or bearer)",
+		"PUT /api/v1/admin/users/:user_id/delete (admin + session or bearer)",
+		"GET /api/v1/admin/users/:user_id/sessions (admin + session or bearer)",
+		"DELETE /api/v1/admin/users/:user_id/sessions (admin + session or bearer)",
+		"DELETE /api/v1/admin/sessions/:session_id (admin + session or bearer)",
+		"GET /api/v1/admin/metrics (admin + session or bearer)",
+		"GET /api/v1/admin/stats/users (admin + session or bearer)",
+		"GET /api/v1/admin/feature-flags (admin + session or bearer)",
+		"GET /api/v1/users/:user_id (auth or session)",
+		"PATCH /api/v1/internal/sessions/:session_id/metadata (service account)",
+		"POST /api/v1/internal/users/:user_id/transfer-ownership/ack (service account)",
+		"GET /api/v1/health (public)",
+		"GET /api/v1/health/ready (public)",
+		"GET /version and /api/v1/version (public)",
+	}
+	if r.mainProxy != nil {
+		routes = append(routes, "ANY /api/v1/proxy/*proxyPath (auth or session)")
+	}
+	r.logger.Info("Router setup completed", "routes", routes)
 
 	return r.engine
 }
@@ -225,3 +361,30 @@ func (r *Router) Setup(appConfig *config.Config) *gin.Engine {
 func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
+
+// routeRateLimit returns the limiter of a route group with an overridden
+// limit, applied on top of the global limiter, or no handlers otherwise
+func (r *Router) routeRateLimit(limits map[string]config.RouteRateLimit, group string) []gin.HandlerFunc {
+	limit, ok := limits[group]
+	if !ok {
+		return nil
+	}
+
+	r.logger.Info("Route rate limit configured", "group", group, "rate_per_minute", limit.Rate, "burst", limit.Burst)
+	return []gin.HandlerFunc{r.newRateLimiter(group, limit.Rate, limit.Burst, time.Minute).RateLimit()}
+}
+
+// newRateLimiter creates a rate limiter owned by the router so it is stopped on Close
+func (r *Router) newRateLimiter(name string, rate, burst int, period time.Duration) *middleware.RateLimiter {
+	rl := middleware.NewRateLimiterWithPeriod(name, rate, burst, period, r.visitorLimits)
+	r.rateLimiters = append(r.rateLimiters, rl)
+	return rl
+}
+
+// Close stops background goroutines started by the router's middleware
+func (r *Router) Close() {
+	for _, rl := range r.rateLimiters {
+		rl.Stop()
+	}
+	r.rateLimiters = nil
+}
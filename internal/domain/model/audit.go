@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionAdminPromoted           AuditAction = "admin_promoted"
+	AuditActionAdminPromotionRequested AuditAction = "admin_promotion_requested"
+	AuditActionAdminPromotionConfirmed AuditAction = "admin_promotion_confirmed"
+	AuditActionAdminBootstrapped       AuditAction = "admin_bootstrapped"
+	AuditActionUserAutoRejected        AuditAction = "user_auto_rejected"
+	AuditActionUserAutoDeleted         AuditAction = "user_auto_deleted"
+	AuditActionOwnershipTransferStart  AuditAction = "ownership_transfer_requested"
+	AuditActionOwnershipTransferAck    AuditAction = "ownership_transfer_acknowledged"
+	AuditActionOwnershipTransferCancel AuditAction = "ownership_transfer_cancelled"
+	AuditActionClaimsOutOfSync         AuditAction = "claims_out_of_sync"
+	AuditActionEmailVerifiedByAdmin    AuditAction = "email_verified_by_admin"
+	AuditActionUserImported            AuditAction = "user_imported"
+)
+
+// AuditActorSystem is the actor ID recorded for actions taken by background jobs
+const AuditActorSystem = "system"
+
+type AuditEntry struct {
+	EntryID      string
+	Action       AuditAction
+	ActorID      string
+	TargetUserID string
+	Details      map[string]interface{}
+	CreatedAt    time.Time
+}
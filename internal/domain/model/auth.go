@@ -11,4 +11,29 @@ type UserAuthInfo struct {
 	Email         string
 	EmailVerified bool
 	DisplayName   string
+	// Claims holds every claim of the verified ID token; from GetAuthInfo it
+	// holds the user's custom claims
+	Claims map[string]interface{}
 }
+
+// RegistrationNextStep tells the client what the user must do after registering
+type RegistrationNextStep string
+
+const (
+	NextStepVerifyEmail   RegistrationNextStep = "verify_email"
+	NextStepAwaitApproval RegistrationNextStep = "await_approval"
+	NextStepReady         RegistrationNextStep = "ready"
+)
+
+type RegistrationResult struct {
+	User     *User
+	NextStep RegistrationNextStep
+}
+
+// ActionLinkType is the kind of out-of-band email action link
+type ActionLinkType string
+
+const (
+	ActionLinkVerifyEmail   ActionLinkType = "verify_email"
+	ActionLinkPasswordReset ActionLinkType = "password_reset"
+)
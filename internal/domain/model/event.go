@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type EventType string
+
+const (
+	EventUserOwnershipTransfer          EventType = "user.ownership_transfer"
+	EventUserOwnershipTransferCancelled EventType = "user.ownership_transfer_cancelled"
+)
+
+// Activity events feed analytics and are delivered on a best-effort basis.
+//   - session.created: user_id, scope, expires_at, remember_me
+//   - session.revoked: user_id, scope, reason ("revoked" or "revoke_all")
+//   - auth.login_failed: reason (the error type), and user_id when the
+//     token was valid but the account could not sign in
+const (
+	EventSessionCreated  EventType = "session.created"
+	EventSessionRevoked  EventType = "session.revoked"
+	EventAuthLoginFailed EventType = "auth.login_failed"
+)
+
+// Event is a domain event delivered to other services
+type Event struct {
+	EventID    string
+	Type       EventType
+	OccurredAt time.Time
+	// CorrelationID is the ID of the request that caused the event, matching
+	// the request_id of its log lines
+	CorrelationID string
+	Data          map[string]interface{}
+}
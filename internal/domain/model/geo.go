@@ -0,0 +1,20 @@
+package model
+
+// GeoLocation is the approximate location of an IP address
+type GeoLocation struct {
+	City string
+	// Country is the ISO 3166-1 alpha-2 country code
+	Country string
+}
+
+// String formats the location for display, e.g. "Istanbul, TR"
+func (l GeoLocation) String() string {
+	switch {
+	case l.City != "" && l.Country != "":
+		return l.City + ", " + l.Country
+	case l.City != "":
+		return l.City
+	default:
+		return l.Country
+	}
+}
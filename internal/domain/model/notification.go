@@ -0,0 +1,7 @@
+package model
+
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
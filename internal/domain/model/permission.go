@@ -0,0 +1,57 @@
+package model
+
+import "slices"
+
+// Permission is an operation this service lets a user perform. Services
+// behind the proxy apply their own checks to the forwarded role.
+type Permission string
+
+const (
+	PermissionProfileRead    Permission = "profile:read"
+	PermissionAccountDelete  Permission = "account:delete"
+	PermissionUsersRead      Permission = "users:read"
+	PermissionPasswordChange Permission = "password:change"
+	PermissionSessionsManage Permission = "sessions:manage"
+	PermissionProxyAccess    Permission = "proxy:access"
+	PermissionAdminUsers     Permission = "admin:users"
+	PermissionAdminSessions  Permission = "admin:sessions"
+	PermissionAdminStats     Permission = "admin:stats"
+)
+
+// selfServicePermissions are granted to every role
+var selfServicePermissions = []Permission{
+	PermissionProfileRead,
+	PermissionAccountDelete,
+	PermissionUsersRead,
+	PermissionPasswordChange,
+	PermissionSessionsManage,
+	PermissionProxyAccess,
+}
+
+// rolePermissions mirrors the role checks on the API routes: the admin
+// routes require the admin role, every other route any role
+var rolePermissions = map[UserRole][]Permission{
+	RoleAdmin:      append(slices.Clone(selfServicePermissions), PermissionAdminUsers, PermissionAdminSessions, PermissionAdminStats),
+	RoleUser:       selfServicePermissions,
+	RoleViewer:     selfServicePermissions,
+	RoleUnassigned: selfServicePermissions,
+}
+
+// Permissions returns the permissions the role grants to an active user
+func (r UserRole) Permissions() []Permission {
+	return slices.Clone(rolePermissions[r])
+}
+
+// UserPermissions is the authorization picture of a user
+type UserPermissions struct {
+	UserID string
+	Role   UserRole
+	Status UserStatus
+	// RolePermissions are the permissions of the user's role
+	RolePermissions []Permission
+	// Permissions are what the user may do now: the role's permissions
+	// when the account is active, none otherwise
+	Permissions []Permission
+	// Reason explains why an account that is not active has no permissions
+	Reason string
+}
@@ -2,9 +2,13 @@ package model
 
 import "time"
 
+// DefaultSessionScope is the scope of sessions created without an explicit scope
+const DefaultSessionScope = "default"
+
 type Session struct {
 	SessionID    string
 	UserID       string
+	Scope        string
 	CreatedAt    time.Time
 	ExpiresAt    time.Time
 	LastUsedAt   time.Time
@@ -12,6 +16,77 @@ type Session struct {
 	Metadata     map[string]interface{}
 }
 
+// SessionInfo is a read-only view of a session used in listings and statistics
+type SessionInfo struct {
+	SessionID    string
+	Scope        string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	LastUsedAt   time.Time
+	RequestCount int64
+	Metadata     map[string]interface{}
+}
+
+// SessionStats summarizes a user's active sessions
+type SessionStats struct {
+	ActiveSessions int
+	Sessions       []SessionInfo
+}
+
+// ScopeSessionStats summarizes a user's sessions in one scope together with
+// the scope's lifetime policy
+type ScopeSessionStats struct {
+	Scope              string
+	Expiration         time.Duration
+	MaxSessionsPerUser int
+	ActiveSessions     int
+	Sessions           []SessionInfo
+}
+
+// ScopedSessionStats groups a user's active sessions by scope
+type ScopedSessionStats struct {
+	ActiveSessions int
+	// MaxSessions caps active sessions across all scopes; zero means no cap
+	MaxSessions int
+	Scopes      []ScopeSessionStats
+}
+
+// SessionRevocation is the outcome of revoking several sessions at once.
+// Sessions that disappear concurrently count as neither revoked nor failed.
+type SessionRevocation struct {
+	Revoked int
+	Failed  []SessionRevocationFailure
+}
+
+// SessionRevocationFailure names a session that could not be revoked and
+// should be retried
+type SessionRevocationFailure struct {
+	SessionID string
+	Reason    string
+}
+
+// FailedIDs returns the IDs of the sessions that could not be revoked
+func (r *SessionRevocation) FailedIDs() []string {
+	ids := make([]string, len(r.Failed))
+	for i, failure := range r.Failed {
+		ids[i] = failure.SessionID
+	}
+	return ids
+}
+
+// ScopeOrDefault returns the session scope, treating an empty scope as the default
+func (s *Session) ScopeOrDefault() string {
+	if s.Scope == "" {
+		return DefaultSessionScope
+	}
+	return s.Scope
+}
+
+// IsExpired reports whether the session is past its expiry at the given time
+func (s *Session) IsExpired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
 func (s *Session) GetID() string {
 	return s.SessionID
 }
@@ -8,8 +8,18 @@ const (
 	StatusPending   UserStatus = "pending"
 	StatusActive    UserStatus = "active"
 	StatusSuspended UserStatus = "suspended"
+	StatusRejected  UserStatus = "rejected"
 )
 
+// IsValid reports whether s is one of the defined statuses
+func (s UserStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusActive, StatusSuspended, StatusRejected:
+		return true
+	}
+	return false
+}
+
 type UserRole string
 
 const (
@@ -19,12 +29,43 @@ const (
 	RoleUnassigned UserRole = "unassigned"
 )
 
+// IsValid reports whether r is one of the defined roles
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleUser, RoleViewer, RoleUnassigned:
+		return true
+	}
+	return false
+}
+
+// OwnershipTransferStatus tracks handing a user's data in other services to another user
+type OwnershipTransferStatus string
+
+const (
+	OwnershipTransferPending      OwnershipTransferStatus = "pending"
+	OwnershipTransferAcknowledged OwnershipTransferStatus = "acknowledged"
+)
+
 type UpdateUser struct {
 	DisplayName   *string
 	Status        *UserStatus
 	Role          *UserRole
 	AdminApproved *bool
 	ApprovalDate  *time.Time
+	// PendingPromotionBy records the admin who requested promotion; an empty
+	// string clears a pending promotion.
+	PendingPromotionBy *string
+	// OwnershipTransferStatus also stamps the transfer time; an empty status
+	// clears the transfer entirely
+	OwnershipTransferStatus *OwnershipTransferStatus
+	OwnershipTransferTo     *string
+}
+
+// UserEdit holds the fields an admin may change directly; nil fields are
+// left unchanged
+type UserEdit struct {
+	DisplayName *string
+	Role        *UserRole
 }
 
 type User struct {
@@ -37,6 +78,30 @@ type User struct {
 	Role          UserRole
 	AdminApproved bool
 	ApprovalDate  time.Time
+
+	PendingPromotionBy string
+	PendingPromotionAt time.Time
+
+	OwnershipTransferStatus OwnershipTransferStatus
+	OwnershipTransferTo     string
+	OwnershipTransferAt     time.Time
+}
+
+// UserCountFilter narrows a user count; zero fields do not filter
+type UserCountFilter struct {
+	Status       UserStatus
+	Role         UserRole
+	CreatedAfter time.Time
+}
+
+// UserStats aggregates user counts for the admin dashboard
+type UserStats struct {
+	Total    int64
+	ByStatus map[UserStatus]int64
+	ByRole   map[UserRole]int64
+	// NewThisWeek counts users registered in the last seven days
+	NewThisWeek int64
+	GeneratedAt time.Time
 }
 
 func (u *User) GetID() string {
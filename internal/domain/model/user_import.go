@@ -0,0 +1,33 @@
+package model
+
+// UserImportRow is one user to create from an import file
+type UserImportRow struct {
+	// Line is the row's line number in the file, for reporting
+	Line        int
+	Email       string
+	DisplayName string
+	Role        UserRole
+	Status      UserStatus
+}
+
+// UserImportOutcome is what happened to a single import row
+type UserImportOutcome string
+
+const (
+	UserImportCreated UserImportOutcome = "created"
+	// UserImportValid marks a row that passed validation in a dry run
+	UserImportValid   UserImportOutcome = "valid"
+	UserImportSkipped UserImportOutcome = "skipped"
+	UserImportFailed  UserImportOutcome = "failed"
+)
+
+// UserImportResult reports the outcome of a single import row
+type UserImportResult struct {
+	Line    int
+	Email   string
+	Outcome UserImportOutcome
+	// UserID is set for created users
+	UserID string
+	// Error explains why the row was skipped or failed
+	Error string
+}
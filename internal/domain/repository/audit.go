@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditEntry) error
+}
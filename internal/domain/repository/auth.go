@@ -9,9 +9,26 @@ import (
 type AuthRepository interface {
 	VerifyIDToken(ctx context.Context, idToken string) (*model.UserAuthInfo, error)
 
+	// CreateUser creates a Firebase user without a password and returns its
+	// UID. The user sets a password through a password reset link.
+	CreateUser(ctx context.Context, email string, displayName string) (string, error)
+
 	ChangePassword(ctx context.Context, userID string, newPassword string) error
 
 	Delete(ctx context.Context, userID string) error
 
 	GetAuthInfo(ctx context.Context, userID string) (*model.UserAuthInfo, error)
+
+	EmailExists(ctx context.Context, email string) (bool, error)
+
+	// SetCustomClaims replaces the custom claims carried in the user's ID tokens
+	SetCustomClaims(ctx context.Context, userID string, claims map[string]interface{}) error
+
+	// SetEmailVerified marks the user's email as verified or not; ID tokens
+	// minted afterwards carry the new email_verified claim
+	SetEmailVerified(ctx context.Context, userID string, verified bool) error
+
+	// GenerateActionLink creates an email action link. A non-empty
+	// continueURL is where the user is sent after completing the action.
+	GenerateActionLink(ctx context.Context, linkType model.ActionLinkType, email string, continueURL string) (string, error)
 }
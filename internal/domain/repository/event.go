@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event *model.Event) error
+}
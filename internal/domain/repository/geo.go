@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// GeoResolver maps IP addresses to approximate locations
+type GeoResolver interface {
+	// Resolve returns nil without an error for addresses it has no
+	// location for
+	Resolve(ctx context.Context, ip string) (*model.GeoLocation, error)
+}
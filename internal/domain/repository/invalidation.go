@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// UserInvalidationBroadcaster propagates user cache invalidations to every
+// replica of the service
+type UserInvalidationBroadcaster interface {
+	Broadcast(ctx context.Context, userID string) error
+
+	// Listen calls onInvalidate for each invalidation broadcast after it
+	// starts. It blocks until ctx is cancelled or the subscription fails.
+	Listen(ctx context.Context, onInvalidate func(userID string)) error
+}
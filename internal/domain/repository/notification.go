@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+type EmailSender interface {
+	Send(ctx context.Context, message *model.EmailMessage) error
+}
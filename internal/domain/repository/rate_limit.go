@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitStore counts requests in fixed windows shared by all replicas
+type RateLimitStore interface {
+	// Allow records a request for key and reports whether it is within limit
+	// for the current window. When it is not, retryAfter is the time until
+	// the window resets.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
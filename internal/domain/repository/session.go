@@ -13,5 +13,8 @@ type SessionRepository interface {
 	Delete(ctx context.Context, sessionID string) error
 	DeleteByUser(ctx context.Context, userID string) error
 	ListByUser(ctx context.Context, userID string) ([]*model.Session, error)
+	// ListByUserPaged returns a page of a user's active sessions, newest
+	// first, along with the total number of active sessions
+	ListByUserPaged(ctx context.Context, userID string, limit, offset int) ([]*model.Session, int, error)
 	GetStats() map[string]interface{}
 }
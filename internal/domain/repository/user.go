@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/shared/query"
@@ -12,11 +13,28 @@ type UserRepository interface {
 
 	GetByUserID(ctx context.Context, userID string) (*model.User, error)
 
+	// GetByEmail matches the email case-insensitively and returns nil, nil
+	// when no user has it
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 
 	Update(ctx context.Context, userID string, updates *model.UpdateUser) error
 
+	// UpdateIfUnmodified applies updates only while the user's UpdatedAt still
+	// equals updatedAt, and returns a conflict error when it has changed
+	UpdateIfUnmodified(ctx context.Context, userID string, updates *model.UpdateUser, updatedAt time.Time) error
+
 	Delete(ctx context.Context, userID string) error
 
+	// Count returns the number of users matching filter without loading them
+	Count(ctx context.Context, filter model.UserCountFilter) (int64, error)
+
+	// HasAdmin reports whether at least one user holds the admin role
+	HasAdmin(ctx context.Context) (bool, error)
+
 	List(ctx context.Context, pagination *query.Pagination) (*query.Result[*model.User], error)
+
+	// ListPending lists users awaiting approval, oldest first. A non-zero
+	// createdBefore restricts the result to users registered before it,
+	// leaving out users whose registration time was never recorded.
+	ListPending(ctx context.Context, createdBefore time.Time, pagination *query.Pagination) (*query.Result[*model.User], error)
 }
@@ -1,7 +1,10 @@
 package firebase
 
 import (
+	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"firebase.google.com/go/auth"
 	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
@@ -14,6 +17,14 @@ func MapFirebaseAuthError(err error) error {
 		return nil
 	}
 
+	if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+		return sharedErrors.NewTimeoutError("Firebase Auth operation timed out", err)
+	}
+
+	if isQuotaExceeded(err) {
+		return sharedErrors.NewRateLimitedError("Firebase Auth is temporarily rate limiting requests; please retry later", quotaRetryAfter, err)
+	}
+
 	// Check for email already exists
 	if auth.IsEmailAlreadyExists(err) {
 		return sharedErrors.NewConflictError("Email already in use", nil)
@@ -51,3 +62,32 @@ func MapFirebaseAuthError(err error) error {
 
 	return sharedErrors.NewInternalError("Firebase Auth operation failed", err)
 }
+
+// quotaRetryAfter is how long clients are asked to wait after Firebase Auth
+// rejects a call for quota reasons; Firebase does not say how long itself
+const quotaRetryAfter = 30 * time.Second
+
+// quotaErrorMarkers identify quota rejections from the Auth REST API, which
+// the SDK reports as unknown errors carrying the HTTP status and body
+var quotaErrorMarkers = []string{
+	"status: 429",
+	"quota_exceeded",
+	"too_many_attempts_try_later",
+	"resource_exhausted",
+}
+
+// isQuotaExceeded reports whether Firebase Auth refused the call because a
+// quota or rate limit was exceeded
+func isQuotaExceeded(err error) bool {
+	if status.Code(err) == codes.ResourceExhausted {
+		return true
+	}
+
+	errMsg := strings.ToLower(err.Error())
+	for _, marker := range quotaErrorMarkers {
+		if strings.Contains(errMsg, marker) {
+			return true
+		}
+	}
+	return false
+}
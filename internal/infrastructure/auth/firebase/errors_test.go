@@ -0,0 +1,47 @@
+package firebase
+
+import (
+	"errors"
+	"testing"
+
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMapFirebaseAuthErrorQuotaExceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantQuota bool
+	}{
+		{name: "gRPC resource exhausted", err: status.Error(codes.ResourceExhausted, "quota exceeded"), wantQuota: true},
+		{name: "REST quota exceeded", err: errors.New(`unexpected http response with status: 400; body: {"error":{"message":"QUOTA_EXCEEDED"}}`), wantQuota: true},
+		{name: "REST too many attempts", err: errors.New(`{"error":{"message":"TOO_MANY_ATTEMPTS_TRY_LATER"}}`), wantQuota: true},
+		{name: "REST status 429", err: errors.New("unexpected http response with status: 429"), wantQuota: true},
+		{name: "gRPC unavailable", err: status.Error(codes.Unavailable, "backend unavailable")},
+		{name: "unknown error", err: errors.New("connection reset by peer")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MapFirebaseAuthError(tt.err)
+
+			if !tt.wantQuota {
+				if !sharedErrors.IsType(err, sharedErrors.ErrorTypeInternal) {
+					t.Errorf("MapFirebaseAuthError() = %v, want an internal error", err)
+				}
+				return
+			}
+
+			if !sharedErrors.IsType(err, sharedErrors.ErrorTypeRateLimited) {
+				t.Fatalf("MapFirebaseAuthError() = %v, want a rate limited error", err)
+			}
+			var mapped *sharedErrors.Err
+			errors.As(err, &mapped)
+			if got := mapped.Details["retry_after_seconds"]; got != int(quotaRetryAfter.Seconds()) {
+				t.Errorf("retry_after_seconds = %v, want %d", got, int(quotaRetryAfter.Seconds()))
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package firebase
 
 import (
 	"context"
+	"fmt"
 
 	"firebase.google.com/go/auth"
 	"github.com/histopathai/auth-service/internal/domain/model"
@@ -28,11 +29,26 @@ func (far *FirebaseAuthRepositoryImpl) VerifyIDToken(ctx context.Context, idToke
 		Email:         getStringClaim(token.Claims, "email"),
 		EmailVerified: getBoolClaim(token.Claims, "email_verified"),
 		DisplayName:   getStringClaim(token.Claims, "name"), // ✅ Güvenli
+		Claims:        token.Claims,
 	}
 
 	return authUser, nil
 }
 
+func (far *FirebaseAuthRepositoryImpl) CreateUser(ctx context.Context, email string, displayName string) (string, error) {
+	params := (&auth.UserToCreate{}).Email(email)
+	if displayName != "" {
+		params = params.DisplayName(displayName)
+	}
+
+	u, err := far.client.CreateUser(ctx, params)
+	if err != nil {
+		return "", MapFirebaseAuthError(err)
+	}
+
+	return u.UID, nil
+}
+
 func (far *FirebaseAuthRepositoryImpl) ChangePassword(ctx context.Context, userID string, newPassword string) error {
 
 	_, err := far.client.UpdateUser(ctx, userID, (&auth.UserToUpdate{}).Password(newPassword))
@@ -65,11 +81,38 @@ func (far *FirebaseAuthRepositoryImpl) GetAuthInfo(ctx context.Context, userID s
 		Email:         u.Email,
 		EmailVerified: u.EmailVerified,
 		DisplayName:   u.DisplayName,
+		Claims:        u.CustomClaims,
 	}
 
 	return authUser, nil
 }
 
+func (far *FirebaseAuthRepositoryImpl) EmailExists(ctx context.Context, email string) (bool, error) {
+	_, err := far.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return false, nil
+		}
+		return false, MapFirebaseAuthError(err)
+	}
+
+	return true, nil
+}
+
+func (far *FirebaseAuthRepositoryImpl) SetCustomClaims(ctx context.Context, userID string, claims map[string]interface{}) error {
+	if err := far.client.SetCustomUserClaims(ctx, userID, claims); err != nil {
+		return MapFirebaseAuthError(err)
+	}
+	return nil
+}
+
+func (far *FirebaseAuthRepositoryImpl) SetEmailVerified(ctx context.Context, userID string, verified bool) error {
+	if _, err := far.client.UpdateUser(ctx, userID, (&auth.UserToUpdate{}).EmailVerified(verified)); err != nil {
+		return MapFirebaseAuthError(err)
+	}
+	return nil
+}
+
 func getStringClaim(claims map[string]interface{}, key string) string {
 	if val, ok := claims[key]; ok && val != nil {
 		if str, ok := val.(string); ok {
@@ -87,3 +130,26 @@ func getBoolClaim(claims map[string]interface{}, key string) bool {
 	}
 	return false
 }
+
+func (far *FirebaseAuthRepositoryImpl) GenerateActionLink(ctx context.Context, linkType model.ActionLinkType, email string, continueURL string) (string, error) {
+	var settings *auth.ActionCodeSettings
+	if continueURL != "" {
+		settings = &auth.ActionCodeSettings{URL: continueURL}
+	}
+
+	var link string
+	var err error
+	switch linkType {
+	case model.ActionLinkVerifyEmail:
+		link, err = far.client.EmailVerificationLinkWithSettings(ctx, email, settings)
+	case model.ActionLinkPasswordReset:
+		link, err = far.client.PasswordResetLinkWithSettings(ctx, email, settings)
+	default:
+		return "", fmt.Errorf("unsupported action link type %q", linkType)
+	}
+	if err != nil {
+		return "", MapFirebaseAuthError(err)
+	}
+
+	return link, nil
+}
@@ -0,0 +1,28 @@
+package email
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// LogEmailSenderImpl writes emails to the log instead of delivering them.
+// It is used when no SMTP server is configured, e.g. in local development.
+type LogEmailSenderImpl struct {
+	logger *slog.Logger
+}
+
+func NewLogEmailSender(logger *slog.Logger) *LogEmailSenderImpl {
+	return &LogEmailSenderImpl{
+		logger: logger,
+	}
+}
+
+func (s *LogEmailSenderImpl) Send(ctx context.Context, message *model.EmailMessage) error {
+	s.logger.Info("Email not delivered (no SMTP server configured)",
+		"to", message.To,
+		"subject", message.Subject,
+	)
+	return nil
+}
@@ -0,0 +1,52 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+type SMTPEmailSenderImpl struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPEmailSender(host string, port int, username string, password string, from string) *SMTPEmailSenderImpl {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPEmailSenderImpl{
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		from: from,
+		auth: auth,
+	}
+}
+
+func (s *SMTPEmailSenderImpl) Send(ctx context.Context, message *model.EmailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	body.WriteString("From: " + s.from + "\r\n")
+	body.WriteString("To: " + message.To + "\r\n")
+	body.WriteString("Subject: " + message.Subject + "\r\n")
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(message.Body)
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{message.To}, []byte(body.String())); err != nil {
+		return sharedErrors.NewInternalError("failed to send email", err)
+	}
+
+	return nil
+}
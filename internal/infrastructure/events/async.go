@@ -0,0 +1,81 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/domain/repository"
+)
+
+const asyncPublishTimeout = 10 * time.Second
+
+// AsyncEventPublisherImpl hands events to a background worker so callers
+// never wait on delivery. It suits best-effort events only: when the buffer
+// is full events are dropped, and delivery failures are logged, not retried.
+type AsyncEventPublisherImpl struct {
+	next   repository.EventPublisher
+	events chan *model.Event
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+	logger *slog.Logger
+}
+
+func NewAsyncEventPublisher(next repository.EventPublisher, bufferSize int, logger *slog.Logger) *AsyncEventPublisherImpl {
+	p := &AsyncEventPublisherImpl{
+		next:   next,
+		events: make(chan *model.Event, max(bufferSize, 1)),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+	go p.run()
+	return p
+}
+
+// Publish queues event for delivery and always returns nil
+func (p *AsyncEventPublisherImpl) Publish(ctx context.Context, event *model.Event) error {
+	// Stamp the event now rather than when the worker gets to it
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return nil
+	}
+
+	select {
+	case p.events <- event:
+	default:
+		p.logger.Warn("Event buffer full, dropping event", "type", event.Type, "correlation_id", event.CorrelationID)
+	}
+	return nil
+}
+
+func (p *AsyncEventPublisherImpl) run() {
+	defer close(p.done)
+	for event := range p.events {
+		ctx, cancel := context.WithTimeout(context.Background(), asyncPublishTimeout)
+		if err := p.next.Publish(ctx, event); err != nil {
+			p.logger.Warn("Failed to publish event", "type", event.Type, "correlation_id", event.CorrelationID, "error", err)
+		}
+		cancel()
+	}
+}
+
+// Close stops accepting events and waits for queued ones to be delivered
+func (p *AsyncEventPublisherImpl) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.events)
+	}
+	p.mu.Unlock()
+
+	<-p.done
+	return nil
+}
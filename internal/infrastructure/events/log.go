@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// LogEventPublisherImpl writes events to the log instead of delivering them.
+// It is used when no webhook is configured, e.g. in local development.
+type LogEventPublisherImpl struct {
+	logger *slog.Logger
+}
+
+func NewLogEventPublisher(logger *slog.Logger) *LogEventPublisherImpl {
+	return &LogEventPublisherImpl{
+		logger: logger,
+	}
+}
+
+func (p *LogEventPublisherImpl) Publish(ctx context.Context, event *model.Event) error {
+	p.logger.Info("Event not delivered (no webhook configured)",
+		"type", event.Type,
+		"data", event.Data,
+	)
+	return nil
+}
@@ -0,0 +1,19 @@
+package events
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// NopEventPublisherImpl discards events. It is used for optional event
+// streams that are not configured, e.g. in local development.
+type NopEventPublisherImpl struct{}
+
+func NewNopEventPublisher() *NopEventPublisherImpl {
+	return &NopEventPublisherImpl{}
+}
+
+func (p *NopEventPublisherImpl) Publish(ctx context.Context, event *model.Event) error {
+	return nil
+}
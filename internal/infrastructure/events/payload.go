@@ -0,0 +1,38 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// eventSchemaVersion is bumped whenever eventPayload changes incompatibly
+const eventSchemaVersion = "1"
+
+// eventPayload is the JSON encoding of an event shared by all publishers
+type eventPayload struct {
+	ID            string                 `json:"id"`
+	Type          model.EventType        `json:"type"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// newEventPayload encodes event, filling in its ID and time when unset
+func newEventPayload(event *model.Event) eventPayload {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	return eventPayload{
+		ID:            event.EventID,
+		Type:          event.Type,
+		OccurredAt:    event.OccurredAt,
+		CorrelationID: event.CorrelationID,
+		Data:          event.Data,
+	}
+}
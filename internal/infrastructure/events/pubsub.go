@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+	"google.golang.org/api/pubsub/v1"
+)
+
+// PubSubEventPublisherImpl publishes events to a Google Cloud Pub/Sub topic
+// using application default credentials. Each message carries the JSON
+// payload plus type, schema_version and correlation_id attributes so
+// subscribers can filter without decoding it.
+type PubSubEventPublisherImpl struct {
+	topics *pubsub.ProjectsTopicsService
+	topic  string
+}
+
+// NewPubSubEventPublisher publishes to topic, given either as a full
+// "projects/<project>/topics/<topic>" name or as a topic in projectID
+func NewPubSubEventPublisher(ctx context.Context, projectID string, topic string) (*PubSubEventPublisherImpl, error) {
+	service, err := pubsub.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+
+	if !strings.HasPrefix(topic, "projects/") {
+		topic = fmt.Sprintf("projects/%s/topics/%s", projectID, topic)
+	}
+
+	return &PubSubEventPublisherImpl{
+		topics: service.Projects.Topics,
+		topic:  topic,
+	}, nil
+}
+
+func (p *PubSubEventPublisherImpl) Publish(ctx context.Context, event *model.Event) error {
+	body, err := json.Marshal(newEventPayload(event))
+	if err != nil {
+		return sharedErrors.NewInternalError("failed to encode event", err)
+	}
+
+	attributes := map[string]string{
+		"type":           string(event.Type),
+		"schema_version": eventSchemaVersion,
+	}
+	if event.CorrelationID != "" {
+		attributes["correlation_id"] = event.CorrelationID
+	}
+
+	_, err = p.topics.Publish(p.topic, &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{{
+			Data:       base64.StdEncoding.EncodeToString(body),
+			Attributes: attributes,
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return sharedErrors.NewInternalError("failed to publish event to Pub/Sub", err)
+	}
+
+	return nil
+}
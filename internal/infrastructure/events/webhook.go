@@ -0,0 +1,66 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/pkg/signing"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookEventPublisherImpl delivers events as JSON POST requests. When a
+// secret is configured, requests carry the same HMAC signature headers as
+// proxied requests (see package signing).
+type WebhookEventPublisherImpl struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookEventPublisher(webhookURL string, secret string) *WebhookEventPublisherImpl {
+	return &WebhookEventPublisherImpl{
+		url:    webhookURL,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (p *WebhookEventPublisherImpl) Publish(ctx context.Context, event *model.Event) error {
+	body, err := json.Marshal(newEventPayload(event))
+	if err != nil {
+		return sharedErrors.NewInternalError("failed to encode event", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return sharedErrors.NewInternalError("failed to create webhook request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(p.secret) > 0 {
+		target, _ := url.Parse(p.url)
+		timestamp, signature := signing.Sign(p.secret, req.Method, target.RequestURI(), body, time.Now())
+		req.Header.Set(signing.HeaderTimestamp, timestamp)
+		req.Header.Set(signing.HeaderSignature, signature)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return sharedErrors.NewInternalError("failed to deliver webhook", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return sharedErrors.NewInternalError(fmt.Sprintf("webhook rejected event with status %d", resp.StatusCode), nil)
+	}
+
+	return nil
+}
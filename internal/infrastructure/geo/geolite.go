@@ -0,0 +1,72 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// GeoLiteResolver resolves IP addresses from the MaxMind GeoLite2 (or
+// GeoIP2) City database in its binary MMDB format. The file is read into
+// memory once; lookups walk its search tree and decode only the matching
+// record.
+type GeoLiteResolver struct {
+	reader *mmdbReader
+}
+
+// NewGeoLiteResolver opens a City database, e.g. GeoLite2-City.mmdb
+func NewGeoLiteResolver(path string) (*GeoLiteResolver, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geolocation database: %w", err)
+	}
+
+	reader, err := newMMDBReader(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &GeoLiteResolver{reader: reader}, nil
+}
+
+// Resolve locates networks without a country of their own by the country
+// they are registered in
+func (r *GeoLiteResolver) Resolve(ctx context.Context, ip string) (*model.GeoLocation, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, nil
+	}
+
+	record, err := r.reader.lookup(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	location := model.GeoLocation{
+		City:    recordString(record, "city", "names", "en"),
+		Country: recordString(record, "country", "iso_code"),
+	}
+	if location.Country == "" {
+		location.Country = recordString(record, "registered_country", "iso_code")
+	}
+	if location.City == "" && location.Country == "" {
+		return nil, nil
+	}
+	return &location, nil
+}
+
+// recordString returns the string at path in a decoded record, or an empty
+// string when there is none
+func recordString(record interface{}, path ...string) string {
+	for _, key := range path {
+		fields, ok := record.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		record = fields[key]
+	}
+	value, _ := record.(string)
+	return value
+}
@@ -0,0 +1,262 @@
+package geo
+
+import (
+	"bytes"
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// testTreeNode is a node of the search tree written by buildTestDatabase.
+// Each side holds either a child node or a data offset plus one.
+type testTreeNode struct {
+	children [2]*testTreeNode
+	data     [2]uint
+}
+
+func (n *testTreeNode) insert(bits []byte, dataOffset uint) {
+	node := n
+	for _, bit := range bits[:len(bits)-1] {
+		if node.children[bit] == nil {
+			node.children[bit] = &testTreeNode{}
+		}
+		node = node.children[bit]
+	}
+	node.data[bits[len(bits)-1]] = dataOffset + 1
+}
+
+// prefixBits returns the bits of a prefix as they are looked up in an IPv6
+// tree, with IPv4 networks below 96 zero bits
+func prefixBits(prefix netip.Prefix) []byte {
+	ip := prefix.Addr().AsSlice()
+	length := prefix.Bits()
+	var bits []byte
+	if prefix.Addr().Is4() {
+		bits = make([]byte, 96)
+	}
+	for i := 0; i < length; i++ {
+		bits = append(bits, (ip[i/8]>>(7-i%8))&1)
+	}
+	return bits
+}
+
+// testEncoder writes MaxMind DB data section values
+type testEncoder struct {
+	bytes.Buffer
+}
+
+func (e *testEncoder) control(kind byte, size int) {
+	var sizeBits byte
+	var extra []byte
+	switch {
+	case size < 29:
+		sizeBits = byte(size)
+	case size < 285:
+		sizeBits, extra = 29, []byte{byte(size - 29)}
+	default:
+		sizeBits, extra = 30, []byte{byte((size - 285) >> 8), byte(size - 285)}
+	}
+
+	if kind < 8 {
+		e.WriteByte(kind<<5 | sizeBits)
+	} else {
+		e.WriteByte(sizeBits)
+		e.WriteByte(kind - 7)
+	}
+	e.Write(extra)
+}
+
+func (e *testEncoder) value(v interface{}) {
+	switch v := v.(type) {
+	case string:
+		e.control(mmdbString, len(v))
+		e.WriteString(v)
+	case uint32:
+		e.control(mmdbUint32, 4)
+		e.Write([]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+	case testPointer:
+		e.WriteByte(mmdbPointer<<5 | byte(v>>8)&0x7)
+		e.WriteByte(byte(v))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		e.control(mmdbMap, len(keys))
+		for _, key := range keys {
+			e.value(key)
+			e.value(v[key])
+		}
+	}
+}
+
+// testPointer is a pointer below 2048 into the data section
+type testPointer uint
+
+// buildTestDatabase writes an IPv6 City database with the given record
+// size, mapping each network to the record at its offset in data
+func buildTestDatabase(t *testing.T, recordSize uint, networks map[string]uint, data []byte) string {
+	t.Helper()
+
+	root := &testTreeNode{}
+	for network, offset := range networks {
+		root.insert(prefixBits(netip.MustParsePrefix(network)), offset)
+	}
+
+	var nodes []*testTreeNode
+	ids := make(map[*testTreeNode]uint)
+	var number func(node *testTreeNode)
+	number = func(node *testTreeNode) {
+		ids[node] = uint(len(nodes))
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil {
+				number(child)
+			}
+		}
+	}
+	number(root)
+	nodeCount := uint(len(nodes))
+
+	var tree []byte
+	for _, node := range nodes {
+		var records [2]uint
+		for side := range records {
+			switch {
+			case node.children[side] != nil:
+				records[side] = ids[node.children[side]]
+			case node.data[side] != 0:
+				records[side] = nodeCount + mmdbDataSeparator + node.data[side] - 1
+			default:
+				records[side] = nodeCount
+			}
+		}
+		tree = append(tree, encodeNode(recordSize, records[0], records[1])...)
+	}
+
+	metadata := &testEncoder{}
+	metadata.value(map[string]interface{}{
+		"binary_format_major_version": uint32(2),
+		"database_type":               "GeoLite2-City",
+		"ip_version":                  uint32(6),
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint32(recordSize),
+	})
+
+	file := append(tree, make([]byte, mmdbDataSeparator)...)
+	file = append(file, data...)
+	file = append(file, mmdbMetadataMarker...)
+	file = append(file, metadata.Bytes()...)
+
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func encodeNode(recordSize uint, left uint, right uint) []byte {
+	switch recordSize {
+	case 24:
+		return []byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)}
+	case 28:
+		return []byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24)<<4 | byte(right>>24)&0x0f, byte(right >> 16), byte(right >> 8), byte(right)}
+	default:
+		return []byte{byte(left >> 24), byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 24), byte(right >> 16), byte(right >> 8), byte(right)}
+	}
+}
+
+func TestGeoLiteResolverResolve(t *testing.T) {
+	longCity := strings.Repeat("Llanfairpwllgwyngyll", 3)
+
+	data := &testEncoder{}
+	// Records share the country through a pointer, as MaxMind databases do
+	turkey := uint(data.Len())
+	data.value(map[string]interface{}{"iso_code": "TR"})
+	istanbul := uint(data.Len())
+	data.value(map[string]interface{}{
+		"city":    map[string]interface{}{"names": map[string]interface{}{"en": "Istanbul"}},
+		"country": testPointer(turkey),
+	})
+	registeredOnly := uint(data.Len())
+	data.value(map[string]interface{}{"registered_country": testPointer(turkey)})
+	wales := uint(data.Len())
+	data.value(map[string]interface{}{
+		"city":    map[string]interface{}{"names": map[string]interface{}{"en": longCity}},
+		"country": map[string]interface{}{"iso_code": "GB"},
+	})
+
+	networks := map[string]uint{
+		"81.213.0.0/16":   istanbul,
+		"198.51.100.0/24": registeredOnly,
+		"2001:db8::/32":   wales,
+	}
+
+	tests := []struct {
+		ip   string
+		want *model.GeoLocation
+	}{
+		{ip: "81.213.1.2", want: &model.GeoLocation{City: "Istanbul", Country: "TR"}},
+		{ip: "::ffff:81.213.1.2", want: &model.GeoLocation{City: "Istanbul", Country: "TR"}},
+		{ip: "198.51.100.7", want: &model.GeoLocation{Country: "TR"}},
+		{ip: "2001:db8::1", want: &model.GeoLocation{City: longCity, Country: "GB"}},
+		{ip: "203.0.113.1"},
+		{ip: "2001:db9::1"},
+		{ip: "not-an-ip"},
+	}
+
+	for _, recordSize := range []uint{24, 28, 32} {
+		path := buildTestDatabase(t, recordSize, networks, data.Bytes())
+		resolver, err := NewGeoLiteResolver(path)
+		if err != nil {
+			t.Fatalf("NewGeoLiteResolver() with %d-bit records error = %v", recordSize, err)
+		}
+
+		for _, tt := range tests {
+			got, err := resolver.Resolve(context.Background(), tt.ip)
+			if err != nil {
+				t.Errorf("Resolve(%s) with %d-bit records error = %v", tt.ip, recordSize, err)
+				continue
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Resolve(%s) with %d-bit records = %v, want %v", tt.ip, recordSize, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestNewGeoLiteResolverRejectsInvalidDatabases(t *testing.T) {
+	valid, err := os.ReadFile(buildTestDatabase(t, 24, map[string]uint{"81.213.0.0/16": 0}, []byte{mmdbString<<5 | 2, 'T', 'R'}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	markerAt := bytes.LastIndex(valid, mmdbMetadataMarker)
+
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{name: "CSV file", file: []byte("network,geoname_id\n81.213.0.0/16,745044\n")},
+		{name: "truncated metadata", file: valid[:markerAt+len(mmdbMetadataMarker)+3]},
+		{name: "truncated search tree", file: valid[20:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "invalid.mmdb")
+			if err := os.WriteFile(path, tt.file, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewGeoLiteResolver(path); err == nil {
+				t.Error("NewGeoLiteResolver() accepted an invalid database")
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+)
+
+// mmdbMetadataMarker precedes the metadata section at the end of a MaxMind
+// DB file
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// mmdbDataSeparator is the size of the zeroed gap between the search tree
+// and the data section
+const mmdbDataSeparator = 16
+
+// mmdbMaxDepth bounds the nesting of decoded values so a corrupt database
+// cannot recurse without limit
+const mmdbMaxDepth = 32
+
+var errInvalidDatabase = errors.New("invalid MaxMind database")
+
+// mmdbReader looks up records in a MaxMind DB file held in memory, following
+// the MaxMind DB format specification version 2. Only the search tree is
+// walked per lookup; records are decoded on demand.
+type mmdbReader struct {
+	buffer     []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// data is the data section, which record pointers are relative to
+	data mmdbDecoder
+	// ipv4Start is the node IPv4 lookups start at in an IPv6 tree, reached
+	// by following 96 zero bits
+	ipv4Start uint
+}
+
+func newMMDBReader(buffer []byte) (*mmdbReader, error) {
+	markerAt := bytes.LastIndex(buffer, mmdbMetadataMarker)
+	if markerAt < 0 {
+		return nil, fmt.Errorf("%w: no metadata section", errInvalidDatabase)
+	}
+	metadata, _, err := mmdbDecoder{buffer: buffer[markerAt+len(mmdbMetadataMarker):]}.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	fields, ok := metadata.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is not a map", errInvalidDatabase)
+	}
+
+	r := &mmdbReader{
+		buffer:     buffer,
+		nodeCount:  metadataUint(fields, "node_count"),
+		recordSize: metadataUint(fields, "record_size"),
+		ipVersion:  metadataUint(fields, "ip_version"),
+	}
+	if version := metadataUint(fields, "binary_format_major_version"); version != 2 {
+		return nil, fmt.Errorf("%w: unsupported format version %d", errInvalidDatabase, version)
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", errInvalidDatabase, r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", errInvalidDatabase, r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+mmdbDataSeparator > uint(markerAt) {
+		return nil, fmt.Errorf("%w: search tree exceeds the file", errInvalidDatabase)
+	}
+	r.data = mmdbDecoder{buffer: buffer[treeSize+mmdbDataSeparator : markerAt]}
+
+	if r.ipVersion == 6 {
+		for i := 0; i < 96 && r.ipv4Start < r.nodeCount; i++ {
+			r.ipv4Start = r.readRecord(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the record for addr decoded into maps, slices and scalars,
+// or nil when the database has none
+func (r *mmdbReader) lookup(addr netip.Addr) (interface{}, error) {
+	addr = addr.Unmap()
+
+	var ip []byte
+	node := uint(0)
+	switch {
+	case addr.Is4() && r.ipVersion == 6:
+		ipv4 := addr.As4()
+		ip, node = ipv4[:], r.ipv4Start
+	case addr.Is4():
+		ipv4 := addr.As4()
+		ip = ipv4[:]
+	case r.ipVersion == 6:
+		ipv6 := addr.As16()
+		ip = ipv6[:]
+	default:
+		return nil, nil
+	}
+
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		bit := (ip[i/8] >> (7 - i%8)) & 1
+		node = r.readRecord(node, uint(bit))
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return nil, nil
+	case node < r.nodeCount:
+		return nil, fmt.Errorf("%w: search tree is deeper than the address", errInvalidDatabase)
+	}
+
+	offset := node - r.nodeCount - mmdbDataSeparator
+	record, _, err := r.data.decode(offset, 0)
+	return record, err
+}
+
+// readRecord returns the left (bit 0) or right (bit 1) record of a node
+func (r *mmdbReader) readRecord(node uint, bit uint) uint {
+	b := r.buffer[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		// The middle byte holds the high nibble of both records
+		if bit == 0 {
+			return uint(b[3]>>4)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+func metadataUint(fields map[string]interface{}, key string) uint {
+	value, _ := fields[key].(uint64)
+	return uint(value)
+}
+
+// MaxMind DB data section types
+const (
+	mmdbExtended  = 0
+	mmdbPointer   = 1
+	mmdbString    = 2
+	mmdbDouble    = 3
+	mmdbBytes     = 4
+	mmdbUint16    = 5
+	mmdbUint32    = 6
+	mmdbMap       = 7
+	mmdbInt32     = 8
+	mmdbUint64    = 9
+	mmdbUint128   = 10
+	mmdbArray     = 11
+	mmdbContainer = 12
+	mmdbEndMarker = 13
+	mmdbBool      = 14
+	mmdbFloat     = 15
+)
+
+// mmdbDecoder decodes values of a data section. Unsigned integers decode to
+// uint64 (uint128 to *big.Int), maps to map[string]interface{} and arrays
+// to []interface{}.
+type mmdbDecoder struct {
+	buffer []byte
+}
+
+// decode returns the value at offset and the offset just past it
+func (d mmdbDecoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > mmdbMaxDepth {
+		return nil, 0, fmt.Errorf("%w: values nested too deeply", errInvalidDatabase)
+	}
+	if offset >= uint(len(d.buffer)) {
+		return nil, 0, fmt.Errorf("%w: offset %d is outside the data section", errInvalidDatabase, offset)
+	}
+
+	control := d.buffer[offset]
+	offset++
+	kind := control >> 5
+
+	if kind == mmdbPointer {
+		pointer, next, err := d.pointer(control, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	if kind == mmdbExtended {
+		if offset >= uint(len(d.buffer)) {
+			return nil, 0, fmt.Errorf("%w: truncated type", errInvalidDatabase)
+		}
+		kind = 7 + d.buffer[offset]
+		offset++
+	}
+
+	size, offset, err := d.size(control, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch kind {
+	case mmdbMap:
+		return d.decodeMap(size, offset, depth)
+	case mmdbArray:
+		return d.decodeArray(size, offset, depth)
+	case mmdbBool:
+		return size != 0, offset, nil
+	case mmdbContainer, mmdbEndMarker:
+		return nil, 0, fmt.Errorf("%w: unexpected type %d", errInvalidDatabase, kind)
+	}
+
+	if offset+size > uint(len(d.buffer)) {
+		return nil, 0, fmt.Errorf("%w: value exceeds the data section", errInvalidDatabase)
+	}
+	b := d.buffer[offset : offset+size]
+	next := offset + size
+
+	switch kind {
+	case mmdbString:
+		return string(b), next, nil
+	case mmdbBytes:
+		return bytes.Clone(b), next, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("%w: double of %d bytes", errInvalidDatabase, size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("%w: float of %d bytes", errInvalidDatabase, size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), next, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("%w: integer of %d bytes", errInvalidDatabase, size)
+		}
+		var value uint64
+		for _, c := range b {
+			value = value<<8 | uint64(c)
+		}
+		return value, next, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("%w: int32 of %d bytes", errInvalidDatabase, size)
+		}
+		var value uint32
+		for _, c := range b {
+			value = value<<8 | uint32(c)
+		}
+		return int32(value), next, nil
+	case mmdbUint128:
+		return new(big.Int).SetBytes(b), next, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown type %d", errInvalidDatabase, kind)
+	}
+}
+
+// size reads the payload size encoded in a control byte and the bytes
+// following it
+func (d mmdbDecoder) size(control byte, offset uint) (uint, uint, error) {
+	size := uint(control & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+
+	extra := size - 28
+	if offset+extra > uint(len(d.buffer)) {
+		return 0, 0, fmt.Errorf("%w: truncated size", errInvalidDatabase)
+	}
+	var value uint
+	for _, c := range d.buffer[offset : offset+extra] {
+		value = value<<8 | uint(c)
+	}
+
+	switch size {
+	case 29:
+		size = 29 + value
+	case 30:
+		size = 285 + value
+	default:
+		size = 65821 + value
+	}
+	return size, offset + extra, nil
+}
+
+// pointer reads the data section offset a pointer refers to
+func (d mmdbDecoder) pointer(control byte, offset uint) (uint, uint, error) {
+	length := uint((control>>3)&0x3) + 1
+	if offset+length > uint(len(d.buffer)) {
+		return 0, 0, fmt.Errorf("%w: truncated pointer", errInvalidDatabase)
+	}
+
+	var pointer uint
+	if length < 4 {
+		pointer = uint(control & 0x7)
+	}
+	for _, c := range d.buffer[offset : offset+length] {
+		pointer = pointer<<8 | uint(c)
+	}
+
+	switch length {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+	return pointer, offset + length, nil
+}
+
+func (d mmdbDecoder) decodeMap(size uint, offset uint, depth int) (interface{}, uint, error) {
+	values := make(map[string]interface{}, min(size, 64))
+	for i := uint(0); i < size; i++ {
+		key, next, err := d.decode(offset, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: map key is not a string", errInvalidDatabase)
+		}
+
+		value, next, err := d.decode(next, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		values[name] = value
+		offset = next
+	}
+	return values, offset, nil
+}
+
+func (d mmdbDecoder) decodeArray(size uint, offset uint, depth int) (interface{}, uint, error) {
+	values := make([]interface{}, 0, min(size, 64))
+	for i := uint(0); i < size; i++ {
+		value, next, err := d.decode(offset, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		values = append(values, value)
+		offset = next
+	}
+	return values, offset, nil
+}
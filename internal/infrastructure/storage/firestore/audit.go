@@ -0,0 +1,36 @@
+package firestore
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+type FirestoreAuditRepositoryImpl struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreAuditRepository(client *firestore.Client, collection string) *FirestoreAuditRepositoryImpl {
+	return &FirestoreAuditRepositoryImpl{
+		client:     client,
+		collection: collection,
+	}
+}
+
+func (far *FirestoreAuditRepositoryImpl) Create(ctx context.Context, entry *model.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	doc := far.client.Collection(far.collection).NewDoc()
+	entry.EntryID = doc.ID
+
+	if _, err := doc.Set(ctx, AuditEntryToFirestoreMap(entry)); err != nil {
+		return MapFirestoreError(err)
+	}
+
+	return nil
+}
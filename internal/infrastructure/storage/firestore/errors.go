@@ -1,6 +1,7 @@
 package firestore
 
 import (
+	"context"
 	"errors"
 
 	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
@@ -18,6 +19,10 @@ func MapFirestoreError(err error) error {
 		return nil // No more documents
 	}
 
+	if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+		return sharedErrors.NewTimeoutError("Firestore operation timed out", err)
+	}
+
 	st, ok := status.FromError(err)
 	if !ok {
 		return sharedErrors.NewInternalError("Firestore Operation Failed", err)
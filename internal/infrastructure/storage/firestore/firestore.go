@@ -2,9 +2,14 @@ package firestore
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	"github.com/histopathai/auth-service/internal/domain/model"
+	sharedErrors "github.com/histopathai/auth-service/internal/shared/errors"
 	sharedQuery "github.com/histopathai/auth-service/internal/shared/query"
 	"google.golang.org/api/iterator"
 )
@@ -21,9 +26,11 @@ func NewFirestoreUserRepository(client *firestore.Client, collection string) *Fi
 	}
 }
 
+// Create fails with a conflict if a profile with the same user ID exists, so
+// concurrent registrations cannot overwrite each other
 func (fur *FirestoreUserRepositoryImpl) Create(ctx context.Context, entity *model.User) error {
 	data := UserToFirestoreMap(entity)
-	_, err := fur.client.Collection(fur.collection).Doc(entity.UserID).Set(ctx, data)
+	_, err := fur.client.Collection(fur.collection).Doc(entity.UserID).Create(ctx, data)
 	if err != nil {
 		return MapFirestoreError(err)
 	}
@@ -45,8 +52,19 @@ func (fur *FirestoreUserRepositoryImpl) GetByUserID(ctx context.Context, userID
 	return user, nil
 }
 
+// GetByEmail matches case-insensitively on email_lower. Users stored before
+// email_lower existed get it on their next update; until then they are found
+// by an exact match on email instead.
 func (fur *FirestoreUserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*model.User, error) {
-	query := fur.client.Collection(fur.collection).Where("email", "==", email).Limit(1)
+	user, err := fur.getByField(ctx, "email_lower", strings.ToLower(email))
+	if err != nil || user != nil {
+		return user, err
+	}
+	return fur.getByField(ctx, "email", email)
+}
+
+func (fur *FirestoreUserRepositoryImpl) getByField(ctx context.Context, field string, value string) (*model.User, error) {
+	query := fur.client.Collection(fur.collection).Where(field, "==", value).Limit(1)
 	iter := query.Documents(ctx)
 	defer iter.Stop()
 
@@ -66,10 +84,64 @@ func (fur *FirestoreUserRepositoryImpl) GetByEmail(ctx context.Context, email st
 	return user, nil
 }
 
+// Update reads the user in a transaction so that users stored before
+// email_lower existed are backfilled along with the update
 func (fur *FirestoreUserRepositoryImpl) Update(ctx context.Context, userID string, updates *model.UpdateUser) error {
+	ref := fur.client.Collection(fur.collection).Doc(userID)
 	updateData := UpdateUserToFirestoreUpdates(updates)
 
-	_, err := fur.client.Collection(fur.collection).Doc(userID).Update(ctx, updateData)
+	err := fur.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		return tx.Update(ref, backfillEmailLower(doc.Data(), updateData))
+	})
+	if err != nil {
+		return MapFirestoreError(err)
+	}
+	return nil
+}
+
+// backfillEmailLower adds email_lower to the updates of a user stored
+// without it, so GetByEmail finds the user case-insensitively
+func backfillEmailLower(data map[string]interface{}, updates []firestore.Update) []firestore.Update {
+	if _, ok := data["email_lower"]; ok {
+		return updates
+	}
+	email, _ := data["email"].(string)
+	if email == "" {
+		return updates
+	}
+	return append(updates, firestore.Update{Path: "email_lower", Value: strings.ToLower(email)})
+}
+
+// errUserModified aborts a conditional update whose user changed
+var errUserModified = errors.New("user modified")
+
+// UpdateIfUnmodified compares updated_at at microsecond precision, the
+// precision Firestore stores timestamps at, inside a transaction so no
+// write can land between the check and the update
+func (fur *FirestoreUserRepositoryImpl) UpdateIfUnmodified(ctx context.Context, userID string, updates *model.UpdateUser, updatedAt time.Time) error {
+	ref := fur.client.Collection(fur.collection).Doc(userID)
+	updateData := UpdateUserToFirestoreUpdates(updates)
+
+	err := fur.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		current, _ := doc.Data()["updated_at"].(time.Time)
+		if current.UnixMicro() != updatedAt.UnixMicro() {
+			return errUserModified
+		}
+		return tx.Update(ref, backfillEmailLower(doc.Data(), updateData))
+	})
+	if errors.Is(err, errUserModified) {
+		return sharedErrors.NewConflictError("user was modified since it was read", map[string]interface{}{
+			"userID": userID,
+		})
+	}
 	if err != nil {
 		return MapFirestoreError(err)
 	}
@@ -85,15 +157,109 @@ func (fur *FirestoreUserRepositoryImpl) Delete(ctx context.Context, userID strin
 	return nil
 }
 
+// Count uses a server-side aggregation query. Combining CreatedAfter with a
+// status or role filter requires a composite index.
+func (fur *FirestoreUserRepositoryImpl) Count(ctx context.Context, filter model.UserCountFilter) (int64, error) {
+	query := fur.client.Collection(fur.collection).Query
+	if filter.Status != "" {
+		query = query.Where("status", "==", string(filter.Status))
+	}
+	if filter.Role != "" {
+		query = query.Where("role", "==", string(filter.Role))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at", ">=", filter.CreatedAfter)
+	}
+
+	const alias = "count"
+	result, err := query.NewAggregationQuery().WithCount(alias).Get(ctx)
+	if err != nil {
+		return 0, MapFirestoreError(err)
+	}
+
+	value, ok := result[alias].(*firestorepb.Value)
+	if !ok {
+		return 0, nil
+	}
+	return value.GetIntegerValue(), nil
+}
+
+func (fur *FirestoreUserRepositoryImpl) HasAdmin(ctx context.Context) (bool, error) {
+	iter := fur.client.Collection(fur.collection).
+		Where("role", "==", string(model.RoleAdmin)).
+		Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+
+	_, err := iter.Next()
+	if err == iterator.Done {
+		return false, nil
+	}
+	if err != nil {
+		return false, MapFirestoreError(err)
+	}
+	return true, nil
+}
+
 func (fur *FirestoreUserRepositoryImpl) List(ctx context.Context, pagination *sharedQuery.Pagination) (*sharedQuery.Result[*model.User], error) {
 
-	query := fur.client.Collection(fur.collection).Query
+	// Every page is bounded so listing never loads the whole collection
+	limit := pagination.EffectiveLimit()
+	query := fur.client.Collection(fur.collection).Query.
+		Limit(limit + 1).
+		Offset(pagination.Offset)
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := make([]*model.User, 0, limit)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, MapFirestoreError(err)
+		}
+
+		entity, err := UserFromFirestoreDoc(doc)
+		if err != nil {
+			return nil, MapFirestoreError(err)
+		}
 
-	isLimited := pagination.Limit > 0
-	if isLimited {
-		query = query.Limit(pagination.Limit + 1).Offset(pagination.Offset)
+		results = append(results, entity)
+	}
+
+	hasMore := false
+	if len(results) > limit {
+		hasMore = true
+		results = results[:limit]
 	}
 
+	return &sharedQuery.Result[*model.User]{
+		Data:    results,
+		Limit:   limit,
+		Offset:  pagination.Offset,
+		HasMore: hasMore,
+	}, nil
+
+}
+
+// ListPending requires a composite index on (status, created_at)
+func (fur *FirestoreUserRepositoryImpl) ListPending(ctx context.Context, createdBefore time.Time, pagination *sharedQuery.Pagination) (*sharedQuery.Result[*model.User], error) {
+	query := fur.client.Collection(fur.collection).
+		Where("status", "==", string(model.StatusPending))
+	if !createdBefore.IsZero() {
+		// Users stored with a zero created_at have no known age and would
+		// otherwise fill every page of overdue users
+		query = query.Where("created_at", ">", time.Time{}).
+			Where("created_at", "<", createdBefore)
+	}
+	limit := pagination.EffectiveLimit()
+	query = query.OrderBy("created_at", firestore.Asc).
+		Limit(limit + 1).
+		Offset(pagination.Offset)
+
 	iter := query.Documents(ctx)
 	defer iter.Stop()
 
@@ -103,6 +269,9 @@ func (fur *FirestoreUserRepositoryImpl) List(ctx context.Context, pagination *sh
 		if err == iterator.Done {
 			break
 		}
+		if err != nil {
+			return nil, MapFirestoreError(err)
+		}
 
 		entity, err := UserFromFirestoreDoc(doc)
 		if err != nil {
@@ -113,16 +282,15 @@ func (fur *FirestoreUserRepositoryImpl) List(ctx context.Context, pagination *sh
 	}
 
 	hasMore := false
-	if isLimited && len(results) > pagination.Limit {
+	if len(results) > limit {
 		hasMore = true
-		results = results[:pagination.Limit]
+		results = results[:limit]
 	}
 
 	return &sharedQuery.Result[*model.User]{
 		Data:    results,
-		Limit:   pagination.Limit,
+		Limit:   limit,
 		Offset:  pagination.Offset,
 		HasMore: hasMore,
 	}, nil
-
 }
@@ -0,0 +1,40 @@
+package firestore
+
+import (
+	"testing"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestBackfillEmailLower(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		wantValue interface{}
+	}{
+		{name: "user without email_lower", data: map[string]interface{}{"email": "Jane.Doe@Example.com"}, wantValue: "jane.doe@example.com"},
+		{name: "user with email_lower", data: map[string]interface{}{"email": "Jane.Doe@Example.com", "email_lower": "jane.doe@example.com"}},
+		{name: "user without email", data: map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := []firestore.Update{{Path: "status", Value: "active"}}
+
+			got := backfillEmailLower(tt.data, updates)
+
+			var value interface{}
+			for _, update := range got {
+				if update.Path == "email_lower" {
+					value = update.Value
+				}
+			}
+			if value != tt.wantValue {
+				t.Errorf("email_lower update = %v, want %v", value, tt.wantValue)
+			}
+			if got[0].Path != "status" {
+				t.Error("backfillEmailLower() dropped the requested updates")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package firestore
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// invalidationRetention is written to expire_at so a Firestore TTL
+	// policy on that field can remove delivered invalidations
+	invalidationRetention = time.Hour
+	// invalidationLookback replays recent invalidations on subscribe to
+	// cover clock skew between replicas
+	invalidationLookback = time.Minute
+	// invalidationWindow is how long one subscription lasts. Its query
+	// matches every invalidation since it started, so it is replaced
+	// periodically by one starting later to keep the result set small.
+	invalidationWindow = 10 * time.Minute
+)
+
+// FirestoreUserInvalidationImpl broadcasts user cache invalidations through
+// a Firestore collection that every replica listens to
+type FirestoreUserInvalidationImpl struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreUserInvalidation(client *firestore.Client, collection string) *FirestoreUserInvalidationImpl {
+	return &FirestoreUserInvalidationImpl{
+		client:     client,
+		collection: collection,
+	}
+}
+
+func (fui *FirestoreUserInvalidationImpl) Broadcast(ctx context.Context, userID string) error {
+	now := time.Now()
+	_, _, err := fui.client.Collection(fui.collection).Add(ctx, map[string]interface{}{
+		"user_id":    userID,
+		"created_at": now,
+		"expire_at":  now.Add(invalidationRetention),
+	})
+	if err != nil {
+		return MapFirestoreError(err)
+	}
+	return nil
+}
+
+func (fui *FirestoreUserInvalidationImpl) Listen(ctx context.Context, onInvalidate func(userID string)) error {
+	for ctx.Err() == nil {
+		if err := fui.listenWindow(ctx, onInvalidate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listenWindow subscribes to invalidations created since shortly before now
+// until invalidationWindow has passed or ctx is cancelled. The lookback
+// overlaps the previous window, so none are missed between subscriptions;
+// replayed invalidations only drop a cache entry again.
+func (fui *FirestoreUserInvalidationImpl) listenWindow(ctx context.Context, onInvalidate func(userID string)) error {
+	windowCtx, cancel := context.WithTimeout(ctx, invalidationWindow)
+	defer cancel()
+
+	since := time.Now().Add(-invalidationLookback)
+	iter := fui.client.Collection(fui.collection).
+		Where("created_at", ">", since).
+		Snapshots(windowCtx)
+	defer iter.Stop()
+
+	for {
+		snapshot, err := iter.Next()
+		if err != nil {
+			if windowCtx.Err() != nil || status.Code(err) == codes.Canceled {
+				return nil
+			}
+			return MapFirestoreError(err)
+		}
+
+		for _, change := range snapshot.Changes {
+			if change.Kind != firestore.DocumentAdded {
+				continue
+			}
+			if userID, ok := change.Doc.Data()["user_id"].(string); ok && userID != "" {
+				onInvalidate(userID)
+			}
+		}
+	}
+}
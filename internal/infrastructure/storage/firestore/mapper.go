@@ -1,6 +1,7 @@
 package firestore
 
 import (
+	"strings"
 	"time"
 
 	"cloud.google.com/go/firestore"
@@ -11,6 +12,7 @@ func UserToFirestoreMap(user *model.User) map[string]interface{} {
 	return map[string]interface{}{
 		"user_id":        user.UserID,
 		"email":          user.Email,
+		"email_lower":    strings.ToLower(user.Email),
 		"display_name":   user.DisplayName,
 		"created_at":     user.CreatedAt,
 		"updated_at":     user.UpdatedAt,
@@ -21,6 +23,17 @@ func UserToFirestoreMap(user *model.User) map[string]interface{} {
 	}
 }
 
+func AuditEntryToFirestoreMap(entry *model.AuditEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"entry_id":       entry.EntryID,
+		"action":         string(entry.Action),
+		"actor_id":       entry.ActorID,
+		"target_user_id": entry.TargetUserID,
+		"details":        entry.Details,
+		"created_at":     entry.CreatedAt,
+	}
+}
+
 func UserFromFirestoreDoc(doc *firestore.DocumentSnapshot) (*model.User, error) {
 	var user model.User
 
@@ -42,6 +55,16 @@ func UserFromFirestoreDoc(doc *firestore.DocumentSnapshot) (*model.User, error)
 			user.AdminApproved = value.(bool)
 		case "approval_date":
 			user.ApprovalDate = value.(time.Time)
+		case "pending_promotion_by":
+			user.PendingPromotionBy = value.(string)
+		case "pending_promotion_at":
+			user.PendingPromotionAt = value.(time.Time)
+		case "ownership_transfer_status":
+			user.OwnershipTransferStatus = model.OwnershipTransferStatus(value.(string))
+		case "ownership_transfer_to":
+			user.OwnershipTransferTo = value.(string)
+		case "ownership_transfer_at":
+			user.OwnershipTransferAt = value.(time.Time)
 		}
 	}
 	user.UserID = doc.Ref.ID
@@ -66,6 +89,37 @@ func UpdateUserToFirestoreUpdates(update *model.UpdateUser) []firestore.Update {
 	if update.ApprovalDate != nil {
 		updates = append(updates, firestore.Update{Path: "approval_date", Value: *update.ApprovalDate})
 	}
+	if update.PendingPromotionBy != nil {
+		if *update.PendingPromotionBy == "" {
+			updates = append(updates,
+				firestore.Update{Path: "pending_promotion_by", Value: firestore.Delete},
+				firestore.Update{Path: "pending_promotion_at", Value: firestore.Delete},
+			)
+		} else {
+			updates = append(updates,
+				firestore.Update{Path: "pending_promotion_by", Value: *update.PendingPromotionBy},
+				firestore.Update{Path: "pending_promotion_at", Value: time.Now()},
+			)
+		}
+	}
+
+	if update.OwnershipTransferStatus != nil {
+		if *update.OwnershipTransferStatus == "" {
+			updates = append(updates,
+				firestore.Update{Path: "ownership_transfer_status", Value: firestore.Delete},
+				firestore.Update{Path: "ownership_transfer_to", Value: firestore.Delete},
+				firestore.Update{Path: "ownership_transfer_at", Value: firestore.Delete},
+			)
+		} else {
+			updates = append(updates,
+				firestore.Update{Path: "ownership_transfer_status", Value: string(*update.OwnershipTransferStatus)},
+				firestore.Update{Path: "ownership_transfer_at", Value: time.Now()},
+			)
+		}
+	}
+	if update.OwnershipTransferTo != nil {
+		updates = append(updates, firestore.Update{Path: "ownership_transfer_to", Value: *update.OwnershipTransferTo})
+	}
 
 	updates = append(updates, firestore.Update{Path: "updated_at", Value: time.Now()})
 
@@ -0,0 +1,71 @@
+package firestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FirestoreRateLimitStoreImpl keeps one counter document per key and window.
+// Documents carry expire_at so a Firestore TTL policy can remove old windows.
+type FirestoreRateLimitStoreImpl struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreRateLimitStore(client *firestore.Client, collection string) *FirestoreRateLimitStoreImpl {
+	return &FirestoreRateLimitStoreImpl{
+		client:     client,
+		collection: collection,
+	}
+}
+
+func (frl *FirestoreRateLimitStoreImpl) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now()
+	windowStart := now.Truncate(window)
+	windowEnd := windowStart.Add(window)
+
+	// Keys may contain characters that are not valid in document IDs
+	sum := sha256.Sum256([]byte(key))
+	docID := fmt.Sprintf("%s_%d", hex.EncodeToString(sum[:16]), windowStart.Unix())
+	ref := frl.client.Collection(frl.collection).Doc(docID)
+
+	allowed := false
+	err := frl.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var count int64
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if value, ok := doc.Data()["count"].(int64); ok {
+				count = value
+			}
+		}
+
+		if count >= int64(limit) {
+			allowed = false
+			return nil
+		}
+
+		allowed = true
+		return tx.Set(ref, map[string]interface{}{
+			"count":     count + 1,
+			"expire_at": windowEnd,
+		})
+	})
+	if err != nil {
+		return false, 0, MapFirestoreError(err)
+	}
+
+	if !allowed {
+		return false, windowEnd.Sub(now), nil
+	}
+	return true, 0, nil
+}
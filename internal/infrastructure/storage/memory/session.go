@@ -2,17 +2,20 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/internal/shared/metrics"
 )
 
 const (
 	DefaultMaxSessionsPerUser = 5
 	DefaultCleanupInterval    = 5 * time.Minute
+	DefaultCleanupBatchSize   = 1000
 )
 
 type inMemorySessionRepository struct {
@@ -20,18 +23,34 @@ type inMemorySessionRepository struct {
 	userSessions       map[string]map[string]bool
 	mutex              sync.RWMutex
 	cleanupOnce        sync.Once
+	closeOnce          sync.Once
+	stop               chan struct{}
 	maxSessionsPerUser int
+	cleanupInterval    time.Duration
+	cleanupBatchSize   int
 }
 
-func NewInMemorySessionRepository(maxSessionsPerUser int) *inMemorySessionRepository {
+// NewInMemorySessionRepository sweeps expired sessions every cleanupInterval,
+// deleting at most cleanupBatchSize of them per write lock. Zero values use
+// the defaults.
+func NewInMemorySessionRepository(maxSessionsPerUser int, cleanupInterval time.Duration, cleanupBatchSize int) *inMemorySessionRepository {
 	if maxSessionsPerUser <= 0 {
 		maxSessionsPerUser = DefaultMaxSessionsPerUser
 	}
+	if cleanupInterval <= 0 {
+		cleanupInterval = DefaultCleanupInterval
+	}
+	if cleanupBatchSize <= 0 {
+		cleanupBatchSize = DefaultCleanupBatchSize
+	}
 
 	repo := &inMemorySessionRepository{
 		sessions:           make(map[string]*model.Session),
 		userSessions:       make(map[string]map[string]bool),
+		stop:               make(chan struct{}),
 		maxSessionsPerUser: maxSessionsPerUser,
+		cleanupInterval:    cleanupInterval,
+		cleanupBatchSize:   cleanupBatchSize,
 	}
 
 	repo.cleanupOnce.Do(func() {
@@ -74,15 +93,12 @@ func (r *inMemorySessionRepository) Get(ctx context.Context, sessionID string) (
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	// Expiry is enforced by the session service, which may allow a grace period
 	session, exists := r.sessions[sessionID]
 	if !exists {
 		return nil, errors.NewNotFoundError("session_not_found")
 	}
 
-	if time.Now().After(session.ExpiresAt) {
-		return nil, errors.NewNotFoundError("session_expired")
-	}
-
 	return session, nil
 }
 
@@ -168,6 +184,30 @@ func (r *inMemorySessionRepository) ListByUser(ctx context.Context, userID strin
 	return sessions, nil
 }
 
+func (r *inMemorySessionRepository) ListByUserPaged(ctx context.Context, userID string, limit, offset int) ([]*model.Session, int, error) {
+	sessions, err := r.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].SessionID < sessions[j].SessionID
+		}
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+
+	total := len(sessions)
+	if offset >= total {
+		return []*model.Session{}, total, nil
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return sessions[offset:end], total, nil
+}
+
 func (r *inMemorySessionRepository) findOldestSessionUnsafe(userID string) string {
 	userSessions, exists := r.userSessions[userID]
 	if !exists || len(userSessions) == 0 {
@@ -198,27 +238,56 @@ func (r *inMemorySessionRepository) findOldestSessionUnsafe(userID string) strin
 }
 
 func (r *inMemorySessionRepository) cleanupExpiredSessions() {
-	ticker := time.NewTicker(DefaultCleanupInterval)
+	ticker := time.NewTicker(r.cleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		r.mutex.Lock()
-
-		now := time.Now()
-		toDelete := make([]string, 0)
-
-		for sessionID, session := range r.sessions {
-			if now.After(session.ExpiresAt) {
-				toDelete = append(toDelete, sessionID)
-			}
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			metrics.RecordSessionSweep(r.sweepExpiredSessions(time.Now()))
 		}
+	}
+}
 
-		for _, sessionID := range toDelete {
-			r.deleteSessionUnsafe(sessionID)
+// sweepExpiredSessions deletes sessions that expired before now and returns
+// how many were deleted. Expired sessions are found under the read lock, so
+// lookups carry on during the scan, and deleted in batches so writers wait
+// for at most one batch at a time.
+func (r *inMemorySessionRepository) sweepExpiredSessions(now time.Time) int {
+	r.mutex.RLock()
+	expired := make([]string, 0)
+	for sessionID, session := range r.sessions {
+		if now.After(session.ExpiresAt) {
+			expired = append(expired, sessionID)
 		}
+	}
+	r.mutex.RUnlock()
+
+	reaped := 0
+	for start := 0; start < len(expired); start += r.cleanupBatchSize {
+		end := min(start+r.cleanupBatchSize, len(expired))
 
+		r.mutex.Lock()
+		for _, sessionID := range expired[start:end] {
+			// The session may have been extended or deleted since the scan
+			if session, ok := r.sessions[sessionID]; ok && now.After(session.ExpiresAt) {
+				r.deleteSessionUnsafe(sessionID)
+				reaped++
+			}
+		}
 		r.mutex.Unlock()
 	}
+	return reaped
+}
+
+// Close stops the background cleanup goroutine. It is safe to call more than once.
+func (r *inMemorySessionRepository) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.stop)
+	})
+	return nil
 }
 
 func (r *inMemorySessionRepository) GetStats() map[string]interface{} {
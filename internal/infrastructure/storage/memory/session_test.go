@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+func newTestRepository(t *testing.T, maxSessionsPerUser int, cleanupBatchSize int) *inMemorySessionRepository {
+	t.Helper()
+	repo := NewInMemorySessionRepository(maxSessionsPerUser, time.Hour, cleanupBatchSize)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestCreateEvictsOldestBeyondMaxSessionsPerUser(t *testing.T) {
+	repo := newTestRepository(t, 2, 0)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, id := range []string{"oldest", "older", "newest"} {
+		_, err := repo.Create(ctx, &model.Session{
+			SessionID:  id,
+			UserID:     "user-1",
+			CreatedAt:  now.Add(time.Duration(i) * time.Minute),
+			LastUsedAt: now.Add(time.Duration(i) * time.Minute),
+			ExpiresAt:  now.Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if _, err := repo.Get(ctx, "oldest"); err == nil {
+		t.Error("oldest session was not evicted")
+	}
+	if sessions, _ := repo.ListByUser(ctx, "user-1"); len(sessions) != 2 {
+		t.Errorf("ListByUser() = %d sessions, want 2", len(sessions))
+	}
+}
+
+func TestSweepExpiredSessions(t *testing.T) {
+	// A batch size of one makes the sweep take the write lock per session
+	repo := newTestRepository(t, 10, 1)
+	ctx := context.Background()
+	now := time.Now()
+
+	sessions := []*model.Session{
+		{SessionID: "expired-1", UserID: "expired-user", ExpiresAt: now.Add(-time.Minute)},
+		{SessionID: "expired-2", UserID: "expired-user", ExpiresAt: now.Add(-time.Hour)},
+		{SessionID: "expired-3", UserID: "mixed-user", ExpiresAt: now.Add(-time.Minute)},
+		{SessionID: "active", UserID: "mixed-user", ExpiresAt: now.Add(time.Hour)},
+	}
+	for _, session := range sessions {
+		if _, err := repo.Create(ctx, session); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if reaped := repo.sweepExpiredSessions(now); reaped != 3 {
+		t.Errorf("sweepExpiredSessions() = %d, want 3", reaped)
+	}
+	if _, err := repo.Get(ctx, "active"); err != nil {
+		t.Errorf("active session was swept: %v", err)
+	}
+	if _, ok := repo.userSessions["expired-user"]; ok {
+		t.Error("user without sessions is still mapped after the sweep")
+	}
+	if got := len(repo.userSessions["mixed-user"]); got != 1 {
+		t.Errorf("mixed-user maps %d sessions, want 1", got)
+	}
+}
+
+func TestDeleteByUserLeavesNoEntries(t *testing.T) {
+	repo := newTestRepository(t, 10, 0)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := repo.Create(ctx, &model.Session{SessionID: id, UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if err := repo.DeleteByUser(ctx, "user-1"); err != nil {
+		t.Fatalf("DeleteByUser() error = %v", err)
+	}
+	if len(repo.sessions) != 0 || len(repo.userSessions) != 0 {
+		t.Errorf("repository holds %d sessions and %d users after DeleteByUser", len(repo.sessions), len(repo.userSessions))
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// accountStatusErrors maps each non-active status to the error type and
+// default message returned when such a user is turned away
+var accountStatusErrors = map[model.UserStatus]struct {
+	errType errors.ErrorType
+	message string
+}{
+	model.StatusPending: {
+		errType: errors.ErrorTypeAccountPendingApproval,
+		message: "Your account is awaiting approval",
+	},
+	model.StatusSuspended: {
+		errType: errors.ErrorTypeAccountSuspended,
+		message: "Your account has been suspended, please contact support",
+	},
+	model.StatusRejected: {
+		errType: errors.ErrorTypeAccountRejected,
+		message: "Your registration was not approved",
+	},
+}
+
+// AccountStatusError explains why a user with the given status may not use
+// the service. Configured messages replace the defaults. It returns nil for
+// statuses without a specific error, including active.
+func (s *AuthService) AccountStatusError(status model.UserStatus) *errors.Err {
+	statusErr, ok := accountStatusErrors[status]
+	if !ok {
+		return nil
+	}
+
+	message := statusErr.message
+	if configured := s.config.AccountStatusMessages[status]; configured != "" {
+		message = configured
+	}
+
+	return errors.NewAccountStatusError(statusErr.errType, message, map[string]interface{}{
+		"status": status,
+	})
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// GenerateActionLink creates an email action link for email. continueURL,
+// when given, must belong to one of the allowed origins so the link cannot
+// be used to redirect users to an arbitrary site.
+func (s *AuthService) GenerateActionLink(ctx context.Context, linkType model.ActionLinkType, email string, continueURL string) (string, error) {
+	if continueURL != "" && !s.isContinueURLAllowed(continueURL) {
+		return "", errors.NewValidationError("continue URL is not allowed", map[string]interface{}{
+			"continue_url": continueURL,
+		})
+	}
+
+	return s.authRepo.GenerateActionLink(ctx, linkType, email, continueURL)
+}
+
+// isContinueURLAllowed reports whether the URL is absolute and its scheme
+// and host match an allowed origin exactly
+func (s *AuthService) isContinueURLAllowed(continueURL string) bool {
+	target, err := url.Parse(continueURL)
+	if err != nil || target.Scheme == "" || target.Host == "" || target.User != nil {
+		return false
+	}
+	origin := strings.ToLower(target.Scheme + "://" + target.Host)
+
+	for _, allowed := range s.config.ActionLinkAllowedOrigins {
+		if strings.ToLower(strings.TrimSuffix(allowed, "/")) == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// SendVerificationEmail emails the user a link that verifies their email
+// address, continuing to continueURL once verified
+func (s *AuthService) SendVerificationEmail(ctx context.Context, userID string, continueURL string) error {
+	authInfo, err := s.authRepo.GetAuthInfo(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if authInfo.EmailVerified {
+		return errors.NewConflictError("email is already verified", nil)
+	}
+
+	link, err := s.GenerateActionLink(ctx, model.ActionLinkVerifyEmail, authInfo.Email, continueURL)
+	if err != nil {
+		return err
+	}
+
+	return s.sendActionLinkEmail(ctx, &model.EmailMessage{
+		To:      authInfo.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease verify your email address by opening this link:\n\n%s\n\n"+
+			"If you did not create an account, you can ignore this email.\n", authInfo.DisplayName, link),
+	})
+}
+
+// SendPasswordResetEmail emails a password reset link to email. Unknown
+// addresses are not reported, so the endpoint cannot be used to find out
+// which emails have accounts.
+func (s *AuthService) SendPasswordResetEmail(ctx context.Context, email string, continueURL string) error {
+	link, err := s.GenerateActionLink(ctx, model.ActionLinkPasswordReset, email, continueURL)
+	if errors.IsType(err, errors.ErrorTypeNotFound) {
+		s.logger.Debug("Password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.sendActionLinkEmail(ctx, &model.EmailMessage{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello,\n\nOpen this link to choose a new password:\n\n%s\n\n"+
+			"If you did not ask to reset your password, you can ignore this email.\n", link),
+	})
+}
+
+func (s *AuthService) sendActionLinkEmail(ctx context.Context, message *model.EmailMessage) error {
+	if err := s.emailSender.Send(ctx, message); err != nil {
+		return errors.NewInternalError("failed to send email", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+func TestGenerateActionLinkChecksContinueURL(t *testing.T) {
+	ts := newTestAuthService(AuthConfig{ActionLinkAllowedOrigins: []string{"https://app.example.com/"}})
+
+	tests := []struct {
+		name        string
+		continueURL string
+		wantErr     bool
+	}{
+		{name: "no continue URL", continueURL: ""},
+		{name: "allowed origin", continueURL: "https://app.example.com/welcome"},
+		{name: "allowed origin in another case", continueURL: "HTTPS://App.Example.com/welcome"},
+		{name: "other host", continueURL: "https://evil.example.com/welcome", wantErr: true},
+		{name: "other scheme", continueURL: "http://app.example.com/welcome", wantErr: true},
+		{name: "userinfo", continueURL: "https://app.example.com@evil.example.com/", wantErr: true},
+		{name: "relative URL", continueURL: "/welcome", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ts.GenerateActionLink(context.Background(), model.ActionLinkVerifyEmail, "user@example.com", tt.continueURL)
+			if tt.wantErr != errors.IsType(err, errors.ErrorTypeValidation) {
+				t.Errorf("GenerateActionLink() error = %v, want validation error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendVerificationEmail(t *testing.T) {
+	user := &model.User{UserID: "user-1", Email: "user@example.com", Status: model.StatusActive}
+	ts := newTestAuthService(AuthConfig{ActionLinkAllowedOrigins: []string{"https://app.example.com"}}, user)
+	ts.authRepo.addAccount("", &model.UserAuthInfo{UserID: user.UserID, Email: user.Email, EmailVerified: false})
+	ctx := context.Background()
+
+	if err := ts.SendVerificationEmail(ctx, user.UserID, "https://evil.example.com"); !errors.IsType(err, errors.ErrorTypeValidation) {
+		t.Fatalf("SendVerificationEmail() error = %v, want a validation error for a disallowed continue URL", err)
+	}
+	if err := ts.SendVerificationEmail(ctx, user.UserID, "https://app.example.com/welcome"); err != nil {
+		t.Fatalf("SendVerificationEmail() error = %v", err)
+	}
+
+	sent := ts.emails.sent()
+	if len(sent) != 1 || sent[0].To != user.Email || !strings.Contains(sent[0].Body, "verify_email") {
+		t.Fatalf("sent emails = %+v, want one verification link to %s", sent, user.Email)
+	}
+
+	ts.authRepo.addAccount("", &model.UserAuthInfo{UserID: user.UserID, Email: user.Email, EmailVerified: true})
+	if err := ts.SendVerificationEmail(ctx, user.UserID, ""); !errors.IsType(err, errors.ErrorTypeConflict) {
+		t.Errorf("SendVerificationEmail() error = %v for a verified email, want a conflict", err)
+	}
+}
+
+func TestSendPasswordResetEmailDoesNotRevealUnknownEmails(t *testing.T) {
+	ts := newTestAuthService(AuthConfig{})
+	ts.authRepo.addAccount("", &model.UserAuthInfo{UserID: "user-1", Email: "user@example.com"})
+	ctx := context.Background()
+
+	if err := ts.SendPasswordResetEmail(ctx, "nobody@example.com", ""); err != nil {
+		t.Fatalf("SendPasswordResetEmail() error = %v for an unknown email, want none", err)
+	}
+	if sent := ts.emails.sent(); len(sent) != 0 {
+		t.Fatalf("sent %d emails for an unknown email", len(sent))
+	}
+
+	if err := ts.SendPasswordResetEmail(ctx, "user@example.com", ""); err != nil {
+		t.Fatalf("SendPasswordResetEmail() error = %v", err)
+	}
+	if sent := ts.emails.sent(); len(sent) != 1 || sent[0].To != "user@example.com" {
+		t.Errorf("sent emails = %+v, want one reset link", sent)
+	}
+}
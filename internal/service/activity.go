@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/domain/repository"
+	"github.com/histopathai/auth-service/internal/shared/requestid"
+)
+
+// publishActivity emits a best-effort activity event tagged with the ID of
+// the request being served. Failures are logged and never fail the caller.
+func publishActivity(ctx context.Context, publisher repository.EventPublisher, logger *slog.Logger, eventType model.EventType, data map[string]interface{}) {
+	if publisher == nil {
+		return
+	}
+
+	event := &model.Event{
+		Type:          eventType,
+		CorrelationID: requestid.FromContext(ctx),
+		Data:          data,
+	}
+	if err := publisher.Publish(ctx, event); err != nil {
+		logger.Warn("Failed to publish activity event", "type", eventType, "error", err)
+	}
+}
@@ -2,28 +2,171 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/domain/repository"
 	"github.com/histopathai/auth-service/internal/shared/errors"
 	"github.com/histopathai/auth-service/internal/shared/query"
+	"github.com/histopathai/auth-service/pkg/featureflag"
+	"golang.org/x/sync/singleflight"
 )
 
+// invalidationResubscribeDelay is the pause before resubscribing to user
+// cache invalidations after the subscription fails
+const invalidationResubscribeDelay = 5 * time.Second
+
+// PendingApprovalAction is what happens to registrations left pending past the TTL
+type PendingApprovalAction string
+
+const (
+	PendingApprovalReject PendingApprovalAction = "reject"
+	PendingApprovalDelete PendingApprovalAction = "delete"
+)
+
+// DefaultApprovalRoles are the roles an approval may assign when none are
+// configured
+var DefaultApprovalRoles = []model.UserRole{model.RoleUser, model.RoleViewer}
+
+// AuthConfig holds policy settings for the auth service
+type AuthConfig struct {
+	RequireDualControlForAdmin bool
+	// RequireEmailVerification makes unverified registrations verify their email first
+	RequireEmailVerification bool
+	// EmailVerificationGracePeriod is how long after registering a user may
+	// keep signing in without verifying their email when verification is
+	// required; zero never blocks them
+	EmailVerificationGracePeriod time.Duration
+	// AllowedRegistrationDomains restricts self-registration to these email
+	// domains; "*.example.org" also matches subdomains. Empty allows all.
+	AllowedRegistrationDomains []string
+	// PendingApprovalTTL is how long a registration may await approval; zero disables expiry
+	PendingApprovalTTL    time.Duration
+	PendingApprovalAction PendingApprovalAction
+	// NotifyPendingApprovalExpiry emails users before their registration is expired
+	NotifyPendingApprovalExpiry bool
+	// NotifyRoleChange emails users when an admin changes their role
+	NotifyRoleChange bool
+	// UserCacheTTL caches user profiles looked up by ID; zero disables caching
+	UserCacheTTL  time.Duration
+	UserCacheSize int
+	// FeatureFlags overrides the settings above per user for gradual
+	// rollouts; nil applies the static settings to everyone
+	FeatureFlags *featureflag.Evaluator
+	// UserStatsCacheTTL caches aggregate user stats; zero recomputes them on every call
+	UserStatsCacheTTL time.Duration
+	// BootstrapAdminEmail is promoted to admin when it registers or logs in
+	// while no admin exists, so a fresh deployment can get its first admin
+	BootstrapAdminEmail string
+	// AccountStatusMessages overrides the message returned to users rejected
+	// for their account status
+	AccountStatusMessages map[model.UserStatus]string
+	// BulkWriteConcurrency bounds concurrent Firestore mutations in bulk
+	// operations such as expiring pending registrations; zero uses
+	// DefaultBulkWriteConcurrency
+	BulkWriteConcurrency int
+	// ActionLinkAllowedOrigins are the only origins email action links may
+	// continue to, guarding against open redirects
+	ActionLinkAllowedOrigins []string
+	// ApprovalRoles are the roles an approval may assign; empty uses
+	// DefaultApprovalRoles
+	ApprovalRoles []model.UserRole
+	// OwnershipTransfers is set when ownership transfer events reach other
+	// services and those services can acknowledge them; without it a
+	// transfer could never complete, so none may be requested
+	OwnershipTransfers bool
+}
+
 type AuthService struct {
-	authRepo repository.AuthRepository
-	userRepo repository.UserRepository
+	authRepo    repository.AuthRepository
+	userRepo    repository.UserRepository
+	auditRepo   repository.AuditRepository
+	emailSender repository.EmailSender
+	publisher   repository.EventPublisher
+	// activity receives best-effort analytics events such as failed logins
+	activity repository.EventPublisher
+	// invalidations propagates user cache invalidations to other replicas;
+	// nil keeps them local
+	invalidations  repository.UserInvalidationBroadcaster
+	userCache      *userCache
+	userLookups    *singleflight.Group
+	userStatsCache *userStatsCache
+	// bootstrapDone is set once an admin is known to exist
+	bootstrapDone *atomic.Bool
+	// userSessions removes the sessions of deleted users; nil leaves them
+	// to expire
+	userSessions UserSessionPurger
+	config       AuthConfig
+	logger       *slog.Logger
 }
 
-func NewAuthService(authrepo repository.AuthRepository, userRepo repository.UserRepository) *AuthService {
+// UserSessionPurger deletes all sessions of a user. SessionService
+// implements it; it is a separate interface because the session service
+// itself depends on AuthService.
+type UserSessionPurger interface {
+	PurgeUserSessions(ctx context.Context, userID string) (int, error)
+}
+
+// SetUserSessionPurger makes DeleteUser revoke the deleted user's sessions.
+// Copies of the service made before the call do not see it.
+func (s *AuthService) SetUserSessionPurger(purger UserSessionPurger) {
+	s.userSessions = purger
+}
+
+func NewAuthService(
+	authrepo repository.AuthRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	emailSender repository.EmailSender,
+	publisher repository.EventPublisher,
+	activity repository.EventPublisher,
+	invalidations repository.UserInvalidationBroadcaster,
+	config AuthConfig,
+	logger *slog.Logger,
+) *AuthService {
+	if config.BulkWriteConcurrency <= 0 {
+		config.BulkWriteConcurrency = DefaultBulkWriteConcurrency
+	}
+	if len(config.ApprovalRoles) == 0 {
+		config.ApprovalRoles = DefaultApprovalRoles
+	}
+
+	var cache *userCache
+	if config.UserCacheTTL > 0 && config.UserCacheSize > 0 {
+		cache = newUserCache(config.UserCacheTTL, config.UserCacheSize)
+	}
+
 	return &AuthService{
-		authRepo: authrepo,
-		userRepo: userRepo,
+		authRepo:       authrepo,
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		emailSender:    emailSender,
+		publisher:      publisher,
+		activity:       activity,
+		invalidations:  invalidations,
+		userCache:      cache,
+		userLookups:    &singleflight.Group{},
+		userStatsCache: &userStatsCache{},
+		bootstrapDone:  &atomic.Bool{},
+		config:         config,
+		logger:         logger,
 	}
 }
 
-func (s *AuthService) RegisterUser(ctx context.Context, register *model.ConfirmRegisterUser) (*model.User, error) {
+func (s *AuthService) RegisterUser(ctx context.Context, register *model.ConfirmRegisterUser) (*model.RegistrationResult, error) {
+
+	// 0. Reject disallowed domains before any external call
+	if !s.isRegistrationDomainAllowed(register.Email) {
+		return nil, errors.NewValidationError("registration is not allowed for this email domain", map[string]interface{}{
+			"email": register.Email,
+		})
+	}
 
 	// 1. Verify Firebase Auth
 	authInfo, err := s.authRepo.VerifyIDToken(ctx, register.Token)
@@ -46,40 +189,225 @@ func (s *AuthService) RegisterUser(ctx context.Context, register *model.ConfirmR
 		return nil, errors.NewConflictError("user with this email already exists", detail)
 	}
 
+	// The Firebase account may have changed its email since registering, so
+	// the email check alone does not rule out an existing profile
+	existingProfile, err := s.userRepo.GetByUserID(ctx, authInfo.UserID)
+	if err != nil && !errors.IsType(err, errors.ErrorTypeNotFound) {
+		return nil, errors.NewInternalError("failed to check existing user by ID", err)
+	}
+	if existingProfile != nil {
+		return nil, errors.NewConflictError("user is already registered", map[string]interface{}{
+			"email": register.Email,
+		})
+	}
+
 	// 2. Create user record in the database (initially pending)
+	now := time.Now()
 	user := &model.User{
 		UserID:      authInfo.UserID,
 		Email:       authInfo.Email,
 		DisplayName: register.DisplayName,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 		Status:      model.StatusPending,
 		Role:        model.RoleUnassigned,
 	}
+	if s.flagEnabled(FlagAutoApproveRegistration, user.UserID, false) {
+		user.Status = model.StatusActive
+		user.Role = model.RoleUser
+		user.AdminApproved = true
+		user.ApprovalDate = now
+	}
 
 	// 3. Save user record
 	if err := s.userRepo.Create(ctx, user); err != nil {
+		// A concurrent registration created the profile first; the Firebase
+		// user belongs to it and must not be rolled back
+		if errors.IsType(err, errors.ErrorTypeConflict) {
+			return nil, errors.NewConflictError("user is already registered", map[string]interface{}{
+				"email": register.Email,
+			})
+		}
 		s.authRepo.Delete(ctx, authInfo.UserID) // Rollback Firebase user creation
 		return nil, fmt.Errorf("failed to create user record: %w", err)
 	}
 
-	return user, nil
+	if err := s.bootstrapAdmin(ctx, user, authInfo.EmailVerified); err != nil {
+		return nil, err
+	}
+
+	return &model.RegistrationResult{
+		User:     user,
+		NextStep: s.registrationNextStep(user, authInfo.EmailVerified),
+	}, nil
 
 }
 
+// isRegistrationDomainAllowed matches the email's domain case-insensitively
+// against the allowed registration domains
+func (s *AuthService) isRegistrationDomainAllowed(email string) bool {
+	if len(s.config.AllowedRegistrationDomains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, allowed := range s.config.AllowedRegistrationDomains {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if domain == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registrationNextStep derives what a newly registered user must do next
+// from the registration policy and the user's resulting status
+func (s *AuthService) registrationNextStep(user *model.User, emailVerified bool) model.RegistrationNextStep {
+	if s.flagEnabled(FlagRequireEmailVerification, user.UserID, s.config.RequireEmailVerification) && !emailVerified {
+		return model.NextStepVerifyEmail
+	}
+	if user.Status == model.StatusPending {
+		return model.NextStepAwaitApproval
+	}
+	return model.NextStepReady
+}
+
 func (s *AuthService) VerifyToken(ctx context.Context, idToken string) (*model.User, error) {
+	user, _, err := s.VerifyTokenWithClaims(ctx, idToken)
+	return user, err
+}
+
+// VerifyTokenWithClaims is VerifyToken that also returns the verified
+// token's identity and claims
+func (s *AuthService) VerifyTokenWithClaims(ctx context.Context, idToken string) (*model.User, *model.UserAuthInfo, error) {
 
 	// 1. Verify ID Token with Firebase
 	authUser, err := s.authRepo.VerifyIDToken(ctx, idToken)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 2. Retrieve full user profile from Firestore
-	user, err := s.userRepo.GetByUserID(ctx, authUser.UserID)
+	user, err := s.GetUserByUserID(ctx, authUser.UserID)
+	if errors.IsType(err, errors.ErrorTypeNotFound) {
+		return nil, nil, errors.NewUserProfileNotFoundError("No user profile exists for this account, please complete registration", err)
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return user, nil
+	if err := s.bootstrapAdmin(ctx, user, authUser.EmailVerified); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkEmailVerification(user, authUser.EmailVerified); err != nil {
+		return nil, nil, err
+	}
+
+	return user, authUser, nil
+}
+
+// Login verifies the ID token a session is being created from and checks
+// that the account may use the service. Failures are published as
+// auth.login_failed events.
+func (s *AuthService) Login(ctx context.Context, idToken string) (*model.User, *model.UserAuthInfo, error) {
+	user, authInfo, err := s.VerifyTokenWithClaims(ctx, idToken)
+	if err == nil {
+		if statusErr := s.AccountStatusError(user.Status); statusErr != nil {
+			err = statusErr
+		}
+	}
+	if err == nil {
+		return user, authInfo, nil
+	}
+
+	reason := string(errors.ErrorTypeInternal)
+	var customErr *errors.Err
+	if stderrors.As(err, &customErr) {
+		reason = string(customErr.Type)
+	}
+	data := map[string]interface{}{"reason": reason}
+	if user != nil {
+		data["user_id"] = user.UserID
+	}
+	publishActivity(ctx, s.activity, s.logger, model.EventAuthLoginFailed, data)
+
+	return nil, nil, err
+}
+
+// bootstrapAdmin promotes user to an active, approved admin when their email
+// matches the configured bootstrap email and no admin exists yet. Once any
+// admin is seen the check is skipped for the lifetime of the process, so the
+// setting is safe to leave in place. user is updated in place on promotion.
+//
+// The email must be verified: anyone can sign up with an unverified address,
+// so matching it alone would hand the service to whoever registers first.
+func (s *AuthService) bootstrapAdmin(ctx context.Context, user *model.User, emailVerified bool) error {
+	if s.config.BootstrapAdminEmail == "" || s.bootstrapDone.Load() {
+		return nil
+	}
+	if !strings.EqualFold(user.Email, s.config.BootstrapAdminEmail) || user.Role == model.RoleAdmin {
+		return nil
+	}
+	if !emailVerified {
+		s.logger.Warn("Bootstrap admin email matched but is not verified; not promoting",
+			"user_id", user.UserID,
+		)
+		return nil
+	}
+
+	hasAdmin, err := s.userRepo.HasAdmin(ctx)
+	if err != nil {
+		return errors.NewInternalError("failed to check for existing admins", err)
+	}
+	if hasAdmin {
+		s.bootstrapDone.Store(true)
+		return nil
+	}
+
+	if err := s.SetUserRoleAndStatus(ctx, user.UserID, model.RoleAdmin, model.StatusActive, true); err != nil {
+		return err
+	}
+	s.bootstrapDone.Store(true)
+
+	user.Role = model.RoleAdmin
+	user.Status = model.StatusActive
+	user.AdminApproved = true
+
+	s.logger.Warn("BOOTSTRAP ADMIN CREATED: user promoted to admin because no admin existed; BOOTSTRAP_ADMIN_EMAIL is now inert and can be removed",
+		"user_id", user.UserID,
+		"email", user.Email,
+	)
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionAdminBootstrapped,
+		ActorID:      model.AuditActorSystem,
+		TargetUserID: user.UserID,
+		Details: map[string]interface{}{
+			"email": user.Email,
+		},
+	})
+	return nil
+}
+
+// IsEmailAvailable reports whether no Firebase account uses the given email
+func (s *AuthService) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
+	exists, err := s.authRepo.EmailExists(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
 }
 
 func (s *AuthService) ChangeUserPassword(ctx context.Context, userID string, newPassword string) error {
@@ -87,22 +415,166 @@ func (s *AuthService) ChangeUserPassword(ctx context.Context, userID string, new
 }
 
 func (s *AuthService) DeleteUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Deleting before other services reassign the user's data would orphan it
+	if user.OwnershipTransferStatus == model.OwnershipTransferPending {
+		detail := map[string]interface{}{
+			"userID":      userID,
+			"transferTo":  user.OwnershipTransferTo,
+			"requestedAt": user.OwnershipTransferAt,
+		}
+		return errors.NewConflictError("ownership transfer has not been acknowledged yet", detail)
+	}
+
 	if err := s.userRepo.Delete(ctx, userID); err != nil {
 		return errors.NewInternalError("failed to delete user from database", err)
 	}
+	s.invalidateUser(ctx, userID)
 
 	if err := s.authRepo.Delete(ctx, userID); err != nil {
 		return errors.NewInternalError(fmt.Sprintf("CRITICAL: User deleted from DB but FAILED to delete from Auth. GetByUserID: %s", userID), err)
 	}
 
+	// Sessions of a deleted user are already rejected, so a failure here
+	// only leaves them in the store until they expire
+	if s.userSessions != nil {
+		revoked, err := s.userSessions.PurgeUserSessions(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to revoke sessions of deleted user", "user_id", userID, "error", err)
+		} else if revoked > 0 {
+			s.logger.Info("Revoked sessions of deleted user", "user_id", userID, "revoked", revoked)
+		}
+	}
+
 	return nil
 }
 
+// GetUserByUserID returns a user profile, served from the user cache when enabled
 func (s *AuthService) GetUserByUserID(ctx context.Context, userID string) (*model.User, error) {
-	return s.userRepo.GetByUserID(ctx, userID)
+	if s.userCache != nil {
+		if user, ok := s.userCache.get(userID); ok {
+			return user, nil
+		}
+	}
+
+	// Concurrent lookups for the same user (e.g. a burst of tile requests)
+	// share a single repository read. The read is detached from the first
+	// caller's cancellation so one aborted request does not fail the others.
+	result, err, _ := s.userLookups.Do(userID, func() (interface{}, error) {
+		var epoch uint64
+		if s.userCache != nil {
+			epoch = s.userCache.currentEpoch()
+		}
+
+		user, err := s.userRepo.GetByUserID(context.WithoutCancel(ctx), userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// A user changed while the read was in flight may have been read
+		// before the change, so the result is only cached if none happened
+		if s.userCache != nil {
+			s.userCache.setIfCurrent(user, epoch)
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each caller gets its own copy of the shared result
+	user := *result.(*model.User)
+	return &user, nil
+}
+
+// RefreshUser drops the user's cached profile on every replica and reads it
+// fresh, so a role or status change applies to the user's sessions even if
+// the invalidation broadcast for the change was missed
+func (s *AuthService) RefreshUser(ctx context.Context, userID string) (*model.User, error) {
+	s.invalidateUser(ctx, userID)
+	return s.GetUserByUserID(ctx, userID)
+}
+
+// GetUserByEmail looks up a user by email, ignoring case
+func (s *AuthService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("user not found")
+	}
+	return user, nil
+}
+
+// InvalidateUser drops a cached user profile so the next lookup reads it fresh.
+// Every user mutation must call it. Lookups starting afterwards do not join
+// a read already in flight, which may have returned the old profile.
+func (s *AuthService) InvalidateUser(userID string) {
+	s.userLookups.Forget(userID)
+	if s.userCache != nil {
+		s.userCache.invalidate(userID)
+	}
+}
+
+// updateUser applies updates to a user and invalidates its cached profile
+func (s *AuthService) updateUser(ctx context.Context, userID string, updates *model.UpdateUser) error {
+	if err := s.userRepo.Update(ctx, userID, updates); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, userID)
+	return nil
 }
 
-func (s *AuthService) ApproveUser(ctx context.Context, userID string) error {
+// invalidateUser drops the cached profile on this replica and broadcasts the
+// invalidation so other replicas drop theirs too. Every user change,
+// including suspension and role or status changes, goes through here so it
+// takes effect before the cache TTL expires.
+func (s *AuthService) invalidateUser(ctx context.Context, userID string) {
+	s.InvalidateUser(userID)
+
+	if s.userCache == nil || s.invalidations == nil {
+		return
+	}
+	if err := s.invalidations.Broadcast(ctx, userID); err != nil {
+		s.logger.Error("Failed to broadcast user cache invalidation; other replicas may serve the stale profile until the cache TTL",
+			"user_id", userID,
+			"error", err,
+		)
+	}
+}
+
+// ListenForUserInvalidations applies invalidations broadcast by other
+// replicas until ctx is cancelled, resubscribing after failures
+func (s *AuthService) ListenForUserInvalidations(ctx context.Context) {
+	if s.userCache == nil || s.invalidations == nil {
+		return
+	}
+
+	for {
+		err := s.invalidations.Listen(ctx, s.InvalidateUser)
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("User cache invalidation listener stopped; resubscribing", "error", err)
+
+		select {
+		case <-time.After(invalidationResubscribeDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ApproveUser activates a user with role, or with their current role when
+// role is empty, unassigned users getting the user role. The role must be
+// one of the configured approval roles; admin is granted only through
+// PromoteUserToAdmin.
+func (s *AuthService) ApproveUser(ctx context.Context, userID string, role model.UserRole) error {
 
 	// 1. Retrieve the user by GetByUserID
 	user, err := s.userRepo.GetByUserID(ctx, userID)
@@ -119,9 +591,19 @@ func (s *AuthService) ApproveUser(ctx context.Context, userID string) error {
 		return errors.NewConflictError("user is already active and approved", detail)
 	}
 
-	targetRole := user.Role
-	if user.Role == model.RoleUnassigned {
-		targetRole = model.RoleUser
+	targetRole := role
+	if targetRole == "" {
+		targetRole = user.Role
+		if user.Role == model.RoleUnassigned {
+			targetRole = model.RoleUser
+		}
+	}
+	if !slices.Contains(s.config.ApprovalRoles, targetRole) {
+		return errors.NewValidationError("role cannot be assigned by approval", map[string]interface{}{
+			"userID":       userID,
+			"role":         targetRole,
+			"allowedRoles": s.config.ApprovalRoles,
+		})
 	}
 
 	// 3. Update user status to active, set role and approval date
@@ -160,6 +642,40 @@ func (s *AuthService) SuspendUser(ctx context.Context, userID string) error {
 	return nil
 }
 
+// VerifyUserEmail marks a user's email as verified on behalf of an admin,
+// for users who cannot receive the verification email. The user sees the
+// change once their ID token is refreshed.
+func (s *AuthService) VerifyUserEmail(ctx context.Context, userID string, adminID string) error {
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	authInfo, err := s.authRepo.GetAuthInfo(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if authInfo.EmailVerified {
+		return errors.NewConflictError("email is already verified", map[string]interface{}{
+			"userID": userID,
+		})
+	}
+
+	if err := s.authRepo.SetEmailVerified(ctx, userID, true); err != nil {
+		return errors.NewInternalError("failed to mark email as verified", err)
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionEmailVerifiedByAdmin,
+		ActorID:      adminID,
+		TargetUserID: userID,
+		Details: map[string]interface{}{
+			"email": user.Email,
+		},
+	})
+	return nil
+}
+
 func (s *AuthService) ActivateUser(ctx context.Context, userID string) error {
 
 	// 1. Retrieve the user by GetByUserID
@@ -185,10 +701,14 @@ func (s *AuthService) ActivateUser(ctx context.Context, userID string) error {
 	return nil
 }
 
-func (s *AuthService) PromoteUserToAdmin(ctx context.Context, userID string) error {
+// PromoteUserToAdmin grants the admin role to a user. When dual control is
+// enabled the promotion is only recorded as pending and must be confirmed by a
+// different admin via ConfirmAdminPromotion; the returned bool reports whether
+// the promotion is still pending.
+func (s *AuthService) PromoteUserToAdmin(ctx context.Context, userID string, requestedBy string) (bool, error) {
 	user, err := s.userRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// 2. Ensure user is activated
@@ -197,7 +717,7 @@ func (s *AuthService) PromoteUserToAdmin(ctx context.Context, userID string) err
 			"userID": userID,
 			"status": user.Status,
 		}
-		return errors.NewConflictError("user is not active and cannot be promoted to admin", detail)
+		return false, errors.NewConflictError("user is not active and cannot be promoted to admin", detail)
 	}
 
 	// 3. Check if user is already an admin
@@ -206,18 +726,265 @@ func (s *AuthService) PromoteUserToAdmin(ctx context.Context, userID string) err
 			"userID": userID,
 			"role":   user.Role,
 		}
-		return errors.NewConflictError("user is already an admin", detail)
+		return false, errors.NewConflictError("user is already an admin", detail)
 	}
 
-	// 4. Update user role to admin
+	// 4. With dual control, record a pending promotion instead of changing the role
+	if s.flagEnabled(FlagDualControlAdminPromotion, userID, s.config.RequireDualControlForAdmin) {
+		if user.PendingPromotionBy != "" {
+			detail := map[string]interface{}{
+				"userID":      userID,
+				"requestedBy": user.PendingPromotionBy,
+			}
+			return false, errors.NewConflictError("admin promotion is already pending confirmation", detail)
+		}
+
+		if err := s.updateUser(ctx, userID, &model.UpdateUser{PendingPromotionBy: &requestedBy}); err != nil {
+			return false, err
+		}
+
+		s.recordAudit(ctx, &model.AuditEntry{
+			Action:       model.AuditActionAdminPromotionRequested,
+			ActorID:      requestedBy,
+			TargetUserID: userID,
+		})
+		return true, nil
+	}
+
+	// 5. Update user role to admin
 	err = s.SetUserRoleAndStatus(ctx, userID, model.RoleAdmin, user.Status, user.AdminApproved)
 	if err != nil {
+		return false, err
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionAdminPromoted,
+		ActorID:      requestedBy,
+		TargetUserID: userID,
+	})
+	return false, nil
+}
+
+// ConfirmAdminPromotion applies a pending admin promotion. The confirming
+// admin must differ from the admin who requested the promotion.
+func (s *AuthService) ConfirmAdminPromotion(ctx context.Context, userID string, confirmedBy string) error {
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.PendingPromotionBy == "" {
+		detail := map[string]interface{}{
+			"userID": userID,
+		}
+		return errors.NewConflictError("user has no pending admin promotion", detail)
+	}
+
+	if user.PendingPromotionBy == confirmedBy {
+		return errors.NewForbiddenError("admin promotion must be confirmed by a different admin")
+	}
+
+	if user.Status != model.StatusActive {
+		detail := map[string]interface{}{
+			"userID": userID,
+			"status": user.Status,
+		}
+		return errors.NewConflictError("user is not active and cannot be promoted to admin", detail)
+	}
+
+	// Goes through SetUserRoleAndStatus so the token claims follow the new
+	// role, with rollback, and the user is told about the role change
+	if err := s.SetUserRoleAndStatus(ctx, userID, model.RoleAdmin, user.Status, user.AdminApproved); err != nil {
 		return err
 	}
+	clearPending := ""
+	if err := s.updateUser(ctx, userID, &model.UpdateUser{PendingPromotionBy: &clearPending}); err != nil {
+		// The promotion itself is done; a leftover marker only makes a
+		// repeated confirmation fail with a conflict
+		s.logger.Warn("Failed to clear pending admin promotion", "user_id", userID, "error", err)
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionAdminPromotionConfirmed,
+		ActorID:      confirmedBy,
+		TargetUserID: userID,
+		Details: map[string]interface{}{
+			"requested_by": user.PendingPromotionBy,
+			"confirmed_by": confirmedBy,
+			"requested_at": user.PendingPromotionAt,
+		},
+	})
 	return nil
 }
 
+// RequestOwnershipTransfer asks other services to reassign everything owned
+// by userID to targetUserID. The user cannot be deleted until the transfer is
+// acknowledged through AcknowledgeOwnershipTransfer.
+func (s *AuthService) RequestOwnershipTransfer(ctx context.Context, userID string, targetUserID string, requestedBy string) error {
+	if !s.config.OwnershipTransfers {
+		return errors.NewConflictError("ownership transfers require an event webhook and internal service accounts to be configured", nil)
+	}
+	if userID == targetUserID {
+		return errors.NewValidationError("cannot transfer ownership to the same user", nil)
+	}
+
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.OwnershipTransferStatus == model.OwnershipTransferPending {
+		detail := map[string]interface{}{
+			"userID":     userID,
+			"transferTo": user.OwnershipTransferTo,
+		}
+		return errors.NewConflictError("ownership transfer is already pending", detail)
+	}
+
+	target, err := s.userRepo.GetByUserID(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+	if target.Status != model.StatusActive {
+		detail := map[string]interface{}{
+			"targetUserID": targetUserID,
+			"status":       target.Status,
+		}
+		return errors.NewConflictError("ownership can only be transferred to an active user", detail)
+	}
+
+	status := model.OwnershipTransferPending
+	if err := s.updateUser(ctx, userID, &model.UpdateUser{
+		OwnershipTransferStatus: &status,
+		OwnershipTransferTo:     &targetUserID,
+	}); err != nil {
+		return err
+	}
+
+	event := &model.Event{
+		Type: model.EventUserOwnershipTransfer,
+		Data: map[string]interface{}{
+			"from_user_id": userID,
+			"to_user_id":   targetUserID,
+			"requested_by": requestedBy,
+		},
+	}
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		// Roll back so the transfer can be retried
+		cleared := model.OwnershipTransferStatus("")
+		if rollbackErr := s.updateUser(ctx, userID, &model.UpdateUser{OwnershipTransferStatus: &cleared}); rollbackErr != nil {
+			s.logger.Error("failed to roll back ownership transfer", "user_id", userID, "error", rollbackErr)
+		}
+		return errors.NewInternalError("failed to publish ownership transfer event", err)
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionOwnershipTransferStart,
+		ActorID:      requestedBy,
+		TargetUserID: userID,
+		Details: map[string]interface{}{
+			"to_user_id": targetUserID,
+		},
+	})
+	return nil
+}
+
+// AcknowledgeOwnershipTransfer records that other services finished
+// reassigning the user's data to targetUserID
+func (s *AuthService) AcknowledgeOwnershipTransfer(ctx context.Context, userID string, targetUserID string, acknowledgedBy string) error {
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.OwnershipTransferStatus != model.OwnershipTransferPending {
+		detail := map[string]interface{}{
+			"userID": userID,
+			"status": user.OwnershipTransferStatus,
+		}
+		return errors.NewConflictError("user has no pending ownership transfer", detail)
+	}
+
+	if user.OwnershipTransferTo != targetUserID {
+		detail := map[string]interface{}{
+			"userID":     userID,
+			"transferTo": user.OwnershipTransferTo,
+		}
+		return errors.NewConflictError("acknowledged target does not match the pending transfer", detail)
+	}
+
+	status := model.OwnershipTransferAcknowledged
+	if err := s.updateUser(ctx, userID, &model.UpdateUser{OwnershipTransferStatus: &status}); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionOwnershipTransferAck,
+		ActorID:      acknowledgedBy,
+		TargetUserID: userID,
+		Details: map[string]interface{}{
+			"to_user_id": targetUserID,
+		},
+	})
+	return nil
+}
+
+// CancelOwnershipTransfer abandons a pending ownership transfer, e.g. one
+// the other services never acknowledged, so the user can be deleted or the
+// transfer requested again. Other services are told so they stop reassigning.
+func (s *AuthService) CancelOwnershipTransfer(ctx context.Context, userID string, cancelledBy string) error {
+	user, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.OwnershipTransferStatus != model.OwnershipTransferPending {
+		detail := map[string]interface{}{
+			"userID": userID,
+			"status": user.OwnershipTransferStatus,
+		}
+		return errors.NewConflictError("user has no pending ownership transfer", detail)
+	}
+
+	cleared := model.OwnershipTransferStatus("")
+	if err := s.updateUser(ctx, userID, &model.UpdateUser{OwnershipTransferStatus: &cleared}); err != nil {
+		return err
+	}
+
+	event := &model.Event{
+		Type: model.EventUserOwnershipTransferCancelled,
+		Data: map[string]interface{}{
+			"from_user_id": userID,
+			"to_user_id":   user.OwnershipTransferTo,
+			"cancelled_by": cancelledBy,
+		},
+	}
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		s.logger.Error("failed to publish ownership transfer cancellation", "user_id", userID, "error", err)
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionOwnershipTransferCancel,
+		ActorID:      cancelledBy,
+		TargetUserID: userID,
+		Details: map[string]interface{}{
+			"to_user_id":   user.OwnershipTransferTo,
+			"requested_at": user.OwnershipTransferAt,
+		},
+	})
+	return nil
+}
+
+// SetUserRoleAndStatus updates the user in Firestore and then mirrors role
+// and status into Firebase custom claims. If the claims cannot be synced the
+// Firestore update is rolled back. If the rollback fails too, the two systems
+// disagree: an OutOfSync error is returned and a reconciliation audit entry
+// recorded. Repeating the call re-syncs both.
 func (s *AuthService) SetUserRoleAndStatus(ctx context.Context, userID string, role model.UserRole, status model.UserStatus, adminApproved bool) error {
+	previous, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
 
 	updates := &model.UpdateUser{
 		Role:          &role,
@@ -231,14 +998,148 @@ func (s *AuthService) SetUserRoleAndStatus(ctx context.Context, userID string, r
 		updates.ApprovalDate = nil
 	}
 
-	err := s.userRepo.Update(ctx, userID, updates)
-	if err != nil {
+	if err := s.updateUser(ctx, userID, updates); err != nil {
 		return err
 	}
 
-	return nil
+	claimsErr := s.syncClaims(ctx, userID, role, status)
+	if claimsErr == nil {
+		s.notifyRoleChange(ctx, previous, role)
+		return nil
+	}
+
+	s.logger.Warn("Failed to sync custom claims; rolling back user update",
+		"user_id", userID,
+		"role", role,
+		"status", status,
+		"error", claimsErr,
+	)
+
+	rollback := &model.UpdateUser{
+		Role:          &previous.Role,
+		Status:        &previous.Status,
+		AdminApproved: &previous.AdminApproved,
+		ApprovalDate:  &previous.ApprovalDate,
+	}
+	if rollbackErr := s.updateUser(ctx, userID, rollback); rollbackErr != nil {
+		details := map[string]interface{}{
+			"userID":         userID,
+			"role":           role,
+			"status":         status,
+			"claimsError":    claimsErr.Error(),
+			"rollbackError":  rollbackErr.Error(),
+			"previousRole":   previous.Role,
+			"previousStatus": previous.Status,
+		}
+		s.logger.Error("User profile and custom claims are out of sync", "user_id", userID, "details", details)
+		s.recordAudit(ctx, &model.AuditEntry{
+			Action:       model.AuditActionClaimsOutOfSync,
+			ActorID:      model.AuditActorSystem,
+			TargetUserID: userID,
+			Details:      details,
+		})
+		return errors.NewOutOfSyncError("user was updated but token claims could not be synced; retry the operation", map[string]interface{}{
+			"userID": userID,
+		}, claimsErr)
+	}
+
+	return errors.NewInternalError("failed to sync user claims; the change was rolled back", claimsErr)
 }
 
 func (s *AuthService) ListUsers(ctx context.Context, pagination *query.Pagination) (*query.Result[*model.User], error) {
 	return s.userRepo.List(ctx, pagination)
 }
+
+// ListPendingUsers lists users awaiting approval. With overdue set, only
+// users registered longer ago than the pending approval TTL are returned.
+func (s *AuthService) ListPendingUsers(ctx context.Context, overdue bool, pagination *query.Pagination) (*query.Result[*model.User], error) {
+	var createdBefore time.Time
+	if overdue {
+		if s.config.PendingApprovalTTL <= 0 {
+			return nil, errors.NewValidationError("pending approval expiry is not configured", nil)
+		}
+		createdBefore = time.Now().Add(-s.config.PendingApprovalTTL)
+	}
+
+	return s.userRepo.ListPending(ctx, createdBefore, pagination)
+}
+
+// ExpirePendingUsers rejects or deletes up to limit users whose registration
+// has been pending longer than the TTL and returns how many were processed
+func (s *AuthService) ExpirePendingUsers(ctx context.Context, limit int) (int, error) {
+	if s.config.PendingApprovalTTL <= 0 {
+		return 0, nil
+	}
+
+	result, err := s.ListPendingUsers(ctx, true, &query.Pagination{Limit: limit})
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	errs := runBulk(ctx, result.Data, s.config.BulkWriteConcurrency, s.expirePendingUser)
+	for i, err := range errs {
+		if err != nil {
+			s.logger.Error("failed to expire pending user", "user_id", result.Data[i].UserID, "error", err)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (s *AuthService) expirePendingUser(ctx context.Context, user *model.User) error {
+	action := model.AuditActionUserAutoRejected
+	// A rejected user keeps their Firebase account, so registering again
+	// with the same email would fail; only deletion frees it
+	nextStep := "If you still need access, please contact an administrator."
+	if s.config.PendingApprovalAction == PendingApprovalDelete {
+		if err := s.DeleteUser(ctx, user.UserID); err != nil {
+			return err
+		}
+		action = model.AuditActionUserAutoDeleted
+		nextStep = "You are welcome to register again."
+	} else {
+		if err := s.SetUserRoleAndStatus(ctx, user.UserID, user.Role, model.StatusRejected, false); err != nil {
+			return err
+		}
+	}
+
+	if s.config.NotifyPendingApprovalExpiry && user.Email != "" {
+		message := &model.EmailMessage{
+			To:      user.Email,
+			Subject: "Your registration has expired",
+			Body: fmt.Sprintf("Hello %s,\n\nYour registration was not approved within %s and has been closed. %s\n",
+				user.DisplayName, s.config.PendingApprovalTTL, nextStep),
+		}
+		if err := s.emailSender.Send(ctx, message); err != nil {
+			s.logger.Warn("failed to send registration expiry email", "user_id", user.UserID, "error", err)
+		}
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       action,
+		ActorID:      model.AuditActorSystem,
+		TargetUserID: user.UserID,
+		Details: map[string]interface{}{
+			"email":         user.Email,
+			"registered_at": user.CreatedAt,
+			"ttl":           s.config.PendingApprovalTTL.String(),
+		},
+	})
+	return nil
+}
+
+// recordAudit persists an audit entry. Failures are logged rather than
+// returned so that an audit outage does not mask a completed user change.
+func (s *AuthService) recordAudit(ctx context.Context, entry *model.AuditEntry) {
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		s.logger.Error("failed to record audit entry",
+			"action", entry.Action,
+			"actor_id", entry.ActorID,
+			"target_user_id", entry.TargetUserID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+func TestBootstrapAdminRequiresVerifiedEmail(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified bool
+		wantRole      model.UserRole
+		wantStatus    model.UserStatus
+	}{
+		{"unverified email is not promoted", false, model.RoleUnassigned, model.StatusPending},
+		{"verified email is promoted", true, model.RoleAdmin, model.StatusActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &model.User{
+				UserID:    "first",
+				Email:     "Root@Example.com",
+				CreatedAt: time.Now(),
+				Status:    model.StatusPending,
+				Role:      model.RoleUnassigned,
+			}
+			ts := newTestAuthService(AuthConfig{BootstrapAdminEmail: "root@example.com"}, user)
+			ts.authRepo.addAccount("token", &model.UserAuthInfo{
+				UserID:        user.UserID,
+				Email:         user.Email,
+				EmailVerified: tt.emailVerified,
+			})
+
+			got, err := ts.VerifyToken(context.Background(), "token")
+			if err != nil {
+				t.Fatalf("VerifyToken() error = %v", err)
+			}
+			if got.Role != tt.wantRole || got.Status != tt.wantStatus {
+				t.Errorf("VerifyToken() user = %s/%s, want %s/%s", got.Role, got.Status, tt.wantRole, tt.wantStatus)
+			}
+
+			stored := ts.userRepo.get(user.UserID)
+			if stored.Role != tt.wantRole || stored.Status != tt.wantStatus {
+				t.Errorf("stored user = %s/%s, want %s/%s", stored.Role, stored.Status, tt.wantRole, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRegisterUserDoesNotBootstrapUnverifiedEmail(t *testing.T) {
+	ts := newTestAuthService(AuthConfig{BootstrapAdminEmail: "root@example.com"})
+	ts.authRepo.addAccount("token", &model.UserAuthInfo{
+		UserID:        "squatter",
+		Email:         "root@example.com",
+		EmailVerified: false,
+	})
+
+	result, err := ts.RegisterUser(context.Background(), &model.ConfirmRegisterUser{
+		Token:       "token",
+		Email:       "root@example.com",
+		DisplayName: "Squatter",
+	})
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if result.User.Role == model.RoleAdmin {
+		t.Fatal("RegisterUser() promoted an unverified bootstrap email to admin")
+	}
+	if hasAdmin, _ := ts.userRepo.HasAdmin(context.Background()); hasAdmin {
+		t.Fatal("an admin exists after registering an unverified bootstrap email")
+	}
+}
+
+// waitForEmail waits for the asynchronously sent email with subject
+func waitForEmail(t *testing.T, emails *fakeEmailSender, subject string) *model.EmailMessage {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, message := range emails.sent() {
+			if message.Subject == subject {
+				return message
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no email with subject %q was sent", subject)
+	return nil
+}
+
+func TestConfirmAdminPromotionSyncsClaimsAndNotifies(t *testing.T) {
+	user := &model.User{UserID: "target", Email: "target@example.com", Status: model.StatusActive, Role: model.RoleUser, AdminApproved: true}
+	ts := newTestAuthService(AuthConfig{RequireDualControlForAdmin: true, NotifyRoleChange: true}, user)
+	ctx := context.Background()
+
+	pending, err := ts.PromoteUserToAdmin(ctx, user.UserID, "admin-1")
+	if err != nil || !pending {
+		t.Fatalf("PromoteUserToAdmin() = %v, %v, want a pending promotion", pending, err)
+	}
+	if err := ts.ConfirmAdminPromotion(ctx, user.UserID, "admin-2"); err != nil {
+		t.Fatalf("ConfirmAdminPromotion() error = %v", err)
+	}
+
+	stored := ts.userRepo.get(user.UserID)
+	if stored.Role != model.RoleAdmin || stored.PendingPromotionBy != "" {
+		t.Errorf("stored user role = %s, pending = %q, want admin with no pending promotion", stored.Role, stored.PendingPromotionBy)
+	}
+	if got := ts.authRepo.claims(user.UserID)[ClaimRole]; got != string(model.RoleAdmin) {
+		t.Errorf("role claim = %v, want admin", got)
+	}
+	waitForEmail(t, ts.emails, "Your account role has changed")
+}
+
+func TestConfirmAdminPromotionRollsBackWhenClaimsFail(t *testing.T) {
+	user := &model.User{UserID: "target", Email: "target@example.com", Status: model.StatusActive, Role: model.RoleUser, AdminApproved: true}
+	ts := newTestAuthService(AuthConfig{RequireDualControlForAdmin: true}, user)
+	ctx := context.Background()
+
+	if _, err := ts.PromoteUserToAdmin(ctx, user.UserID, "admin-1"); err != nil {
+		t.Fatalf("PromoteUserToAdmin() error = %v", err)
+	}
+	ts.authRepo.claimsErr = stderrors.New("claims unavailable")
+
+	if err := ts.ConfirmAdminPromotion(ctx, user.UserID, "admin-2"); err == nil {
+		t.Fatal("ConfirmAdminPromotion() succeeded although claims could not be synced")
+	}
+	stored := ts.userRepo.get(user.UserID)
+	if stored.Role != model.RoleUser || stored.PendingPromotionBy != "admin-1" {
+		t.Errorf("stored user role = %s, pending = %q, want the promotion still pending", stored.Role, stored.PendingPromotionBy)
+	}
+}
+
+func TestExpirePendingUsersSyncsRejectedStatusClaim(t *testing.T) {
+	user := &model.User{UserID: "pending", Email: "pending@example.com", Status: model.StatusPending, Role: model.RoleUnassigned, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	ts := newTestAuthService(AuthConfig{PendingApprovalTTL: 24 * time.Hour, PendingApprovalAction: PendingApprovalReject}, user)
+
+	expired, err := ts.ExpirePendingUsers(context.Background(), 10)
+	if err != nil || expired != 1 {
+		t.Fatalf("ExpirePendingUsers() = %d, %v, want 1 expired", expired, err)
+	}
+	if got := ts.userRepo.get(user.UserID).Status; got != model.StatusRejected {
+		t.Errorf("stored status = %s, want rejected", got)
+	}
+	if got := ts.authRepo.claims(user.UserID)[ClaimStatus]; got != string(model.StatusRejected) {
+		t.Errorf("status claim = %v, want rejected", got)
+	}
+}
+
+func TestExpirePendingUsersSkipsUsersWithoutCreatedAt(t *testing.T) {
+	unknownAge := &model.User{UserID: "unknown-age", Status: model.StatusPending, Role: model.RoleUnassigned}
+	overdue := &model.User{UserID: "overdue", Status: model.StatusPending, Role: model.RoleUnassigned, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	ts := newTestAuthService(AuthConfig{PendingApprovalTTL: 24 * time.Hour, PendingApprovalAction: PendingApprovalReject}, unknownAge, overdue)
+
+	// A page of one must not be taken up by the user of unknown age
+	expired, err := ts.ExpirePendingUsers(context.Background(), 1)
+	if err != nil || expired != 1 {
+		t.Fatalf("ExpirePendingUsers() = %d, %v, want 1 expired", expired, err)
+	}
+	if got := ts.userRepo.get(overdue.UserID).Status; got != model.StatusRejected {
+		t.Errorf("overdue user status = %s, want rejected", got)
+	}
+	if got := ts.userRepo.get(unknownAge.UserID).Status; got != model.StatusPending {
+		t.Errorf("user without created_at status = %s, want pending", got)
+	}
+}
+
+func TestExpirePendingUsersEmailMatchesAction(t *testing.T) {
+	tests := []struct {
+		action      PendingApprovalAction
+		wantPhrase  string
+		wantAccount bool
+	}{
+		{PendingApprovalReject, "contact an administrator", true},
+		{PendingApprovalDelete, "register again", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			user := &model.User{UserID: "pending", Email: "pending@example.com", Status: model.StatusPending, Role: model.RoleUnassigned, CreatedAt: time.Now().Add(-48 * time.Hour)}
+			ts := newTestAuthService(AuthConfig{PendingApprovalTTL: 24 * time.Hour, PendingApprovalAction: tt.action, NotifyPendingApprovalExpiry: true}, user)
+			ts.authRepo.addAccount("", &model.UserAuthInfo{UserID: user.UserID, Email: user.Email})
+
+			if expired, err := ts.ExpirePendingUsers(context.Background(), 10); err != nil || expired != 1 {
+				t.Fatalf("ExpirePendingUsers() = %d, %v, want 1 expired", expired, err)
+			}
+
+			message := waitForEmail(t, ts.emails, "Your registration has expired")
+			if !strings.Contains(message.Body, tt.wantPhrase) {
+				t.Errorf("email body = %q, want it to mention %q", message.Body, tt.wantPhrase)
+			}
+			if _, err := ts.authRepo.GetAuthInfo(context.Background(), user.UserID); (err == nil) != tt.wantAccount {
+				t.Errorf("Firebase account exists = %v, want %v", err == nil, tt.wantAccount)
+			}
+		})
+	}
+}
+
+// staleUserRepo misses existing profiles on reads, like a registration that
+// raced another one for the same account
+type staleUserRepo struct {
+	*fakeUserRepo
+}
+
+func (r staleUserRepo) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	return nil, errors.NewNotFoundError("user not found")
+}
+
+func (r staleUserRepo) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return nil, nil
+}
+
+func TestRegisterUserRejectsDuplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *model.User
+		stale    bool
+	}{
+		{name: "profile with the same email", existing: &model.User{UserID: "uid-1", Email: "jane@example.com"}},
+		{name: "profile of the account under its old email", existing: &model.User{UserID: "uid-1", Email: "old@example.com"}},
+		{name: "profile created by a concurrent registration", existing: &model.User{UserID: "uid-1", Email: "jane@example.com"}, stale: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestAuthService(AuthConfig{}, tt.existing)
+			ts.authRepo.addAccount("token", &model.UserAuthInfo{UserID: "uid-1", Email: "jane@example.com"})
+			if tt.stale {
+				ts.AuthService.userRepo = staleUserRepo{ts.userRepo}
+			}
+
+			_, err := ts.RegisterUser(context.Background(), &model.ConfirmRegisterUser{Token: "token", Email: "jane@example.com", DisplayName: "Jane"})
+			if !errors.IsType(err, errors.ErrorTypeConflict) {
+				t.Fatalf("RegisterUser() error = %v, want Conflict", err)
+			}
+			if len(ts.authRepo.deleted) != 0 {
+				t.Errorf("Firebase users deleted = %v, want the existing account kept", ts.authRepo.deleted)
+			}
+		})
+	}
+}
+
+func TestRegisterUserCreatesMissingProfile(t *testing.T) {
+	// The Firebase account exists but registration never stored a profile
+	ts := newTestAuthService(AuthConfig{})
+	ts.authRepo.addAccount("token", &model.UserAuthInfo{UserID: "uid-1", Email: "jane@example.com"})
+
+	result, err := ts.RegisterUser(context.Background(), &model.ConfirmRegisterUser{Token: "token", Email: "jane@example.com", DisplayName: "Jane"})
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if result.User.UserID != "uid-1" {
+		t.Errorf("registered user = %s, want the existing Firebase account", result.User.UserID)
+	}
+	if stored := ts.userRepo.get("uid-1"); stored == nil || stored.Status != model.StatusPending {
+		t.Errorf("stored profile = %+v, want a pending profile", stored)
+	}
+}
+
+func TestApproveUserEnforcesApprovalRoles(t *testing.T) {
+	tests := []struct {
+		name          string
+		approvalRoles []model.UserRole
+		currentRole   model.UserRole
+		role          model.UserRole
+		wantRole      model.UserRole
+	}{
+		{name: "user", role: model.RoleUser, wantRole: model.RoleUser},
+		{name: "viewer", role: model.RoleViewer, wantRole: model.RoleViewer},
+		{name: "admin is rejected", role: model.RoleAdmin},
+		{name: "unassigned is rejected", role: model.RoleUnassigned},
+		{name: "unknown role is rejected", role: model.UserRole("owner")},
+		{name: "empty role gives unassigned users the user role", role: "", wantRole: model.RoleUser},
+		{name: "empty role keeps the current role", currentRole: model.RoleViewer, role: "", wantRole: model.RoleViewer},
+		{name: "empty role does not keep a current admin role", currentRole: model.RoleAdmin, role: ""},
+		{name: "role outside a configured allowlist is rejected", approvalRoles: []model.UserRole{model.RoleViewer}, role: model.RoleUser},
+		{name: "empty role outside a configured allowlist is rejected", approvalRoles: []model.UserRole{model.RoleViewer}, role: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentRole := tt.currentRole
+			if currentRole == "" {
+				currentRole = model.RoleUnassigned
+			}
+			user := &model.User{UserID: "pending", Email: "pending@example.com", Status: model.StatusPending, Role: currentRole}
+			ts := newTestAuthService(AuthConfig{ApprovalRoles: tt.approvalRoles}, user)
+
+			err := ts.ApproveUser(context.Background(), user.UserID, tt.role)
+
+			stored := ts.userRepo.get(user.UserID)
+			if tt.wantRole == "" {
+				if !errors.IsType(err, errors.ErrorTypeValidation) {
+					t.Fatalf("ApproveUser() error = %v, want a validation error", err)
+				}
+				if stored.Status != model.StatusPending || stored.Role != currentRole || stored.AdminApproved {
+					t.Errorf("stored user = %s/%s approved=%v, want it unchanged", stored.Role, stored.Status, stored.AdminApproved)
+				}
+				if claims := ts.authRepo.claims(user.UserID); claims[ClaimRole] != nil {
+					t.Errorf("role claim = %v, want none", claims[ClaimRole])
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ApproveUser() error = %v", err)
+			}
+			if stored.Role != tt.wantRole || stored.Status != model.StatusActive || !stored.AdminApproved {
+				t.Errorf("stored user = %s/%s approved=%v, want %s/active approved", stored.Role, stored.Status, stored.AdminApproved, tt.wantRole)
+			}
+			if got := ts.authRepo.claims(user.UserID)[ClaimRole]; got != string(tt.wantRole) {
+				t.Errorf("role claim = %v, want %s", got, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestDeleteUserPurgesSessions(t *testing.T) {
+	user := &model.User{UserID: "user-1", Email: "user@example.com", Status: model.StatusActive, Role: model.RoleUser}
+	other := &model.User{UserID: "user-2", Email: "other@example.com", Status: model.StatusActive, Role: model.RoleUser}
+	ts := newTestAuthService(AuthConfig{}, user, other)
+	sessions, repo := newTestSessionService(t, SessionConfig{})
+	ts.SetUserSessionPurger(sessions)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := sessions.CreateSession(ctx, user, CreateSessionOptions{}); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+	}
+	// An expired session the sweeper has not reached yet
+	if _, err := repo.Create(ctx, &model.Session{UserID: user.UserID, ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	otherSession, err := sessions.CreateSession(ctx, other, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if err := ts.DeleteUser(ctx, user.UserID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	if remaining, err := repo.ListByUser(ctx, user.UserID); err != nil || len(remaining) != 0 {
+		t.Errorf("ListByUser() = %d sessions, %v after deleting the user", len(remaining), err)
+	}
+	stats := repo.GetStats()
+	if stats["total_sessions"] != 1 || stats["total_users"] != 1 {
+		t.Errorf("store holds %v sessions of %v users, want only the other user's session", stats["total_sessions"], stats["total_users"])
+	}
+	if _, err := repo.Get(ctx, otherSession.SessionID); err != nil {
+		t.Errorf("another user's session was removed: %v", err)
+	}
+}
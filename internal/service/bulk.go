@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultBulkWriteConcurrency bounds concurrent Firestore mutations in bulk
+// operations when no limit is configured
+const DefaultBulkWriteConcurrency = 4
+
+// runBulk calls fn for every item with at most limit calls in flight. A
+// failing item does not stop the others; the returned slice holds each
+// item's error at the item's index.
+func runBulk[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error) []error {
+	errs := make([]error, len(items))
+
+	var g errgroup.Group
+	g.SetLimit(max(limit, 1))
+	for i, item := range items {
+		g.Go(func() error {
+			errs[i] = fn(ctx, item)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return errs
+}
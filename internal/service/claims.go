@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// Custom claims mirrored into Firebase ID tokens so other services can read
+// a user's role and status from the token. Claims reach clients when their
+// token is next refreshed.
+const (
+	ClaimRole   = "role"
+	ClaimStatus = "status"
+)
+
+const (
+	claimsSyncAttempts = 3
+	claimsSyncBackoff  = 200 * time.Millisecond
+)
+
+// syncClaims writes the role and status claims, retrying transient failures.
+// SetCustomClaims replaces the whole claim set, so the user's other custom
+// claims, such as a token scope claim, are read first and written back.
+func (s *AuthService) syncClaims(ctx context.Context, userID string, role model.UserRole, status model.UserStatus) error {
+	var err error
+	for attempt := 1; attempt <= claimsSyncAttempts; attempt++ {
+		if err = s.mergeClaims(ctx, userID, role, status); err == nil {
+			return nil
+		}
+		if attempt == claimsSyncAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(claimsSyncBackoff * time.Duration(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+	return err
+}
+
+// mergeClaims sets the role and status claims on top of the user's current
+// custom claims
+func (s *AuthService) mergeClaims(ctx context.Context, userID string, role model.UserRole, status model.UserStatus) error {
+	authInfo, err := s.authRepo.GetAuthInfo(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	claims := make(map[string]interface{}, len(authInfo.Claims)+2)
+	for key, value := range authInfo.Claims {
+		claims[key] = value
+	}
+	claims[ClaimRole] = string(role)
+	claims[ClaimStatus] = string(status)
+
+	return s.authRepo.SetCustomClaims(ctx, userID, claims)
+}
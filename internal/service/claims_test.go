@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+func TestSyncClaimsKeepsOtherCustomClaims(t *testing.T) {
+	user := &model.User{UserID: "user-1", Email: "user@example.com", Status: model.StatusActive, Role: model.RoleUser}
+	ts := newTestAuthService(AuthConfig{}, user)
+	ts.authRepo.addAccount("", &model.UserAuthInfo{
+		UserID: user.UserID,
+		Email:  user.Email,
+		Claims: map[string]interface{}{
+			"app":       "viewer",
+			ClaimRole:   string(model.RoleUser),
+			ClaimStatus: string(model.StatusActive),
+		},
+	})
+
+	if err := ts.SetUserRoleAndStatus(context.Background(), user.UserID, model.RoleViewer, model.StatusSuspended, true); err != nil {
+		t.Fatalf("SetUserRoleAndStatus() error = %v", err)
+	}
+
+	claims := ts.authRepo.claims(user.UserID)
+	want := map[string]interface{}{
+		"app":       "viewer",
+		ClaimRole:   string(model.RoleViewer),
+		ClaimStatus: string(model.StatusSuspended),
+	}
+	if len(claims) != len(want) {
+		t.Fatalf("claims = %v, want %v", claims, want)
+	}
+	for key, value := range want {
+		if claims[key] != value {
+			t.Errorf("claim %q = %v, want %v", key, claims[key], value)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// EmailVerificationDeadline returns when a user with an unverified email
+// stops being able to sign in. ok is false when the user has nothing to
+// verify: their email is verified, verification is not required for them,
+// no grace period is configured, or their registration time is unknown.
+func (s *AuthService) EmailVerificationDeadline(user *model.User, emailVerified bool) (deadline time.Time, ok bool) {
+	if emailVerified || s.config.EmailVerificationGracePeriod <= 0 || user.CreatedAt.IsZero() {
+		return time.Time{}, false
+	}
+	if !s.flagEnabled(FlagRequireEmailVerification, user.UserID, s.config.RequireEmailVerification) {
+		return time.Time{}, false
+	}
+	return user.CreatedAt.Add(s.config.EmailVerificationGracePeriod), true
+}
+
+// checkEmailVerification rejects users whose verification grace period has
+// run out
+func (s *AuthService) checkEmailVerification(user *model.User, emailVerified bool) error {
+	deadline, ok := s.EmailVerificationDeadline(user, emailVerified)
+	if !ok || time.Now().Before(deadline) {
+		return nil
+	}
+	return errors.NewEmailNotVerifiedError("Please verify your email address to continue", map[string]interface{}{
+		"verify_by": deadline,
+	})
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/internal/shared/query"
+)
+
+// discardLogger drops everything the service logs during tests
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeAuthRepo stands in for Firebase Auth. Tokens map ID tokens to the
+// identity they verify as; accounts are keyed by UID.
+type fakeAuthRepo struct {
+	mu       sync.Mutex
+	tokens   map[string]*model.UserAuthInfo
+	accounts map[string]*model.UserAuthInfo
+	deleted  []string
+	// claimsErr, when set, fails every SetCustomClaims call
+	claimsErr error
+	// deleteErr, when set, fails every Delete call
+	deleteErr error
+	nextUID   int
+}
+
+func newFakeAuthRepo() *fakeAuthRepo {
+	return &fakeAuthRepo{
+		tokens:   make(map[string]*model.UserAuthInfo),
+		accounts: make(map[string]*model.UserAuthInfo),
+	}
+}
+
+// addAccount registers a Firebase account and an ID token for it
+func (r *fakeAuthRepo) addAccount(token string, info *model.UserAuthInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info.Claims == nil {
+		info.Claims = make(map[string]interface{})
+	}
+	r.accounts[info.UserID] = info
+	if token != "" {
+		r.tokens[token] = info
+	}
+}
+
+func (r *fakeAuthRepo) claims(userID string) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	account, ok := r.accounts[userID]
+	if !ok {
+		return nil
+	}
+	return account.Claims
+}
+
+func (r *fakeAuthRepo) VerifyIDToken(ctx context.Context, idToken string) (*model.UserAuthInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.tokens[idToken]
+	if !ok {
+		return nil, errors.NewUnauthorizedError("Invalid or expired token")
+	}
+	copied := *info
+	return &copied, nil
+}
+
+func (r *fakeAuthRepo) CreateUser(ctx context.Context, email string, displayName string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, account := range r.accounts {
+		if strings.EqualFold(account.Email, email) {
+			return "", errors.NewConflictError("email already exists", nil)
+		}
+	}
+	r.nextUID++
+	uid := "uid-" + strconv.Itoa(r.nextUID)
+	r.accounts[uid] = &model.UserAuthInfo{UserID: uid, Email: email, DisplayName: displayName, Claims: map[string]interface{}{}}
+	return uid, nil
+}
+
+func (r *fakeAuthRepo) ChangePassword(ctx context.Context, userID string, newPassword string) error {
+	return nil
+}
+
+func (r *fakeAuthRepo) Delete(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	delete(r.accounts, userID)
+	r.deleted = append(r.deleted, userID)
+	return nil
+}
+
+func (r *fakeAuthRepo) GetAuthInfo(ctx context.Context, userID string) (*model.UserAuthInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	account, ok := r.accounts[userID]
+	if !ok {
+		return nil, errors.NewNotFoundError("user not found")
+	}
+	copied := *account
+	copied.Claims = make(map[string]interface{}, len(account.Claims))
+	for key, value := range account.Claims {
+		copied.Claims[key] = value
+	}
+	return &copied, nil
+}
+
+func (r *fakeAuthRepo) EmailExists(ctx context.Context, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, account := range r.accounts {
+		if strings.EqualFold(account.Email, email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeAuthRepo) SetCustomClaims(ctx context.Context, userID string, claims map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.claimsErr != nil {
+		return r.claimsErr
+	}
+	account, ok := r.accounts[userID]
+	if !ok {
+		account = &model.UserAuthInfo{UserID: userID}
+		r.accounts[userID] = account
+	}
+	account.Claims = claims
+	return nil
+}
+
+func (r *fakeAuthRepo) SetEmailVerified(ctx context.Context, userID string, verified bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if account, ok := r.accounts[userID]; ok {
+		account.EmailVerified = verified
+	}
+	return nil
+}
+
+func (r *fakeAuthRepo) GenerateActionLink(ctx context.Context, linkType model.ActionLinkType, email string, continueURL string) (string, error) {
+	if exists, _ := r.EmailExists(ctx, email); !exists {
+		return "", errors.NewNotFoundError("user not found")
+	}
+	return "https://auth.example.com/action?mode=" + string(linkType) + "&continueUrl=" + continueURL, nil
+}
+
+// fakeUserRepo is an in-memory UserRepository applying updates the way the
+// Firestore repository does
+type fakeUserRepo struct {
+	mu    sync.Mutex
+	users map[string]*model.User
+	// deleteErr, when set, fails every Delete call
+	deleteErr error
+}
+
+func newFakeUserRepo(users ...*model.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: make(map[string]*model.User)}
+	for _, user := range users {
+		copied := *user
+		r.users[user.UserID] = &copied
+	}
+	return r
+}
+
+func (r *fakeUserRepo) get(userID string) *model.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return nil
+	}
+	copied := *user
+	return &copied
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.UserID]; ok {
+		return errors.NewConflictError("user already exists", nil)
+	}
+	copied := *user
+	r.users[user.UserID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	if user := r.get(userID); user != nil {
+		return user, nil
+	}
+	return nil, errors.NewNotFoundError("user not found")
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, userID string, updates *model.UpdateUser) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return errors.NewNotFoundError("user not found")
+	}
+	applyUserUpdates(user, updates)
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateIfUnmodified(ctx context.Context, userID string, updates *model.UpdateUser, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return errors.NewNotFoundError("user not found")
+	}
+	if user.UpdatedAt.UnixMicro() != updatedAt.UnixMicro() {
+		return errors.NewConflictError("user was modified since it was read", nil)
+	}
+	applyUserUpdates(user, updates)
+	return nil
+}
+
+func applyUserUpdates(user *model.User, updates *model.UpdateUser) {
+	now := time.Now()
+	if updates.DisplayName != nil {
+		user.DisplayName = *updates.DisplayName
+	}
+	if updates.Status != nil {
+		user.Status = *updates.Status
+	}
+	if updates.Role != nil {
+		user.Role = *updates.Role
+	}
+	if updates.AdminApproved != nil {
+		user.AdminApproved = *updates.AdminApproved
+	}
+	if updates.ApprovalDate != nil {
+		user.ApprovalDate = *updates.ApprovalDate
+	}
+	if updates.PendingPromotionBy != nil {
+		user.PendingPromotionBy = *updates.PendingPromotionBy
+		user.PendingPromotionAt = now
+		if *updates.PendingPromotionBy == "" {
+			user.PendingPromotionAt = time.Time{}
+		}
+	}
+	if updates.OwnershipTransferStatus != nil {
+		user.OwnershipTransferStatus = *updates.OwnershipTransferStatus
+		user.OwnershipTransferAt = now
+		if *updates.OwnershipTransferStatus == "" {
+			user.OwnershipTransferTo = ""
+			user.OwnershipTransferAt = time.Time{}
+		}
+	}
+	if updates.OwnershipTransferTo != nil {
+		user.OwnershipTransferTo = *updates.OwnershipTransferTo
+	}
+	user.UpdatedAt = now
+}
+
+func (r *fakeUserRepo) Delete(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	delete(r.users, userID)
+	return nil
+}
+
+func (r *fakeUserRepo) Count(ctx context.Context, filter model.UserCountFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, user := range r.users {
+		if filter.Status != "" && user.Status != filter.Status {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if !filter.CreatedAfter.IsZero() && user.CreatedAt.Before(filter.CreatedAfter) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (r *fakeUserRepo) HasAdmin(ctx context.Context) (bool, error) {
+	count, err := r.Count(ctx, model.UserCountFilter{Role: model.RoleAdmin})
+	return count > 0, err
+}
+
+func (r *fakeUserRepo) sorted(keep func(*model.User) bool) []*model.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]*model.User, 0, len(r.users))
+	for _, user := range r.users {
+		if keep(user) {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].UserID < users[j].UserID
+		}
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+	return users
+}
+
+func page(users []*model.User, pagination *query.Pagination) *query.Result[*model.User] {
+	limit := pagination.EffectiveLimit()
+	start := min(pagination.Offset, len(users))
+	end := min(start+limit, len(users))
+	return &query.Result[*model.User]{
+		Data:    users[start:end],
+		Limit:   limit,
+		Offset:  pagination.Offset,
+		HasMore: end < len(users),
+	}
+}
+
+func (r *fakeUserRepo) List(ctx context.Context, pagination *query.Pagination) (*query.Result[*model.User], error) {
+	return page(r.sorted(func(*model.User) bool { return true }), pagination), nil
+}
+
+// ListPending mirrors the Firestore query, which cannot match documents
+// without created_at
+func (r *fakeUserRepo) ListPending(ctx context.Context, createdBefore time.Time, pagination *query.Pagination) (*query.Result[*model.User], error) {
+	users := r.sorted(func(user *model.User) bool {
+		if user.Status != model.StatusPending {
+			return false
+		}
+		return createdBefore.IsZero() || (!user.CreatedAt.IsZero() && user.CreatedAt.Before(createdBefore))
+	})
+	return page(users, pagination), nil
+}
+
+// fakeAuditRepo records audit entries
+type fakeAuditRepo struct {
+	mu      sync.Mutex
+	entries []*model.AuditEntry
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, entry *model.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// fakeEmailSender records sent emails
+type fakeEmailSender struct {
+	mu       sync.Mutex
+	messages []*model.EmailMessage
+}
+
+func (s *fakeEmailSender) Send(ctx context.Context, message *model.EmailMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func (s *fakeEmailSender) sent() []*model.EmailMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*model.EmailMessage(nil), s.messages...)
+}
+
+// fakePublisher records published events
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []*model.Event
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event *model.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *fakePublisher) published(eventType model.EventType) []*model.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var events []*model.Event
+	for _, event := range p.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// testAuthService bundles an AuthService with the fakes behind it
+type testAuthService struct {
+	*AuthService
+	authRepo  *fakeAuthRepo
+	userRepo  *fakeUserRepo
+	auditRepo *fakeAuditRepo
+	emails    *fakeEmailSender
+	events    *fakePublisher
+	activity  *fakePublisher
+}
+
+func newTestAuthService(config AuthConfig, users ...*model.User) *testAuthService {
+	ts := &testAuthService{
+		authRepo:  newFakeAuthRepo(),
+		userRepo:  newFakeUserRepo(users...),
+		auditRepo: &fakeAuditRepo{},
+		emails:    &fakeEmailSender{},
+		events:    &fakePublisher{},
+		activity:  &fakePublisher{},
+	}
+	for _, user := range users {
+		ts.authRepo.addAccount("", &model.UserAuthInfo{UserID: user.UserID, Email: user.Email, EmailVerified: true})
+	}
+	ts.AuthService = NewAuthService(ts.authRepo, ts.userRepo, ts.auditRepo, ts.emails, ts.events, ts.activity, nil, config, discardLogger())
+	return ts
+}
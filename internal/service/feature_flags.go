@@ -0,0 +1,64 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/histopathai/auth-service/pkg/featureflag"
+)
+
+// Feature flags consulted by the auth service. Each falls back to the
+// matching static AuthConfig setting when it is not defined.
+const (
+	FlagRequireEmailVerification  = "require_email_verification"
+	FlagDualControlAdminPromotion = "dual_control_admin_promotion"
+	FlagAutoApproveRegistration   = "auto_approve_registration"
+)
+
+// FeatureFlagState describes a flag as currently configured
+type FeatureFlagState struct {
+	Name string
+	// Configured is false when the flag is not defined and Default applies
+	Configured bool
+	Default    bool
+	Rule       featureflag.Rule
+}
+
+// flagEnabled evaluates a flag for a user, falling back to fallback when
+// feature flags are not configured or the flag is not defined
+func (s *AuthService) flagEnabled(name string, userID string, fallback bool) bool {
+	return s.config.FeatureFlags.Enabled(name, userID, fallback)
+}
+
+// FeatureFlags lists the flags the service knows about and any other
+// configured flags, ordered by name
+func (s *AuthService) FeatureFlags() []FeatureFlagState {
+	defaults := map[string]bool{
+		FlagRequireEmailVerification:  s.config.RequireEmailVerification,
+		FlagDualControlAdminPromotion: s.config.RequireDualControlForAdmin,
+		FlagAutoApproveRegistration:   false,
+	}
+	rules := s.config.FeatureFlags.Rules()
+
+	names := make([]string, 0, len(defaults)+len(rules))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	for name := range rules {
+		if _, known := defaults[name]; !known {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	states := make([]FeatureFlagState, 0, len(names))
+	for _, name := range names {
+		rule, configured := rules[name]
+		states = append(states, FeatureFlagState{
+			Name:       name,
+			Configured: configured,
+			Default:    defaults[name],
+			Rule:       rule,
+		})
+	}
+	return states
+}
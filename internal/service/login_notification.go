@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/domain/repository"
+)
+
+const loginNotificationTimeout = 30 * time.Second
+
+// LoginNotificationConfig holds settings for new-device sign-in emails
+type LoginNotificationConfig struct {
+	Enabled bool
+	// DebounceWindow suppresses repeat emails for the same user and device
+	DebounceWindow time.Duration
+	// RevokeURL is the page where users review and revoke their sessions;
+	// the new session's ID is appended as the session_id query parameter
+	RevokeURL string
+}
+
+// LoginNotifier emails users when they sign in from a device that does not
+// appear in their session history
+type LoginNotifier struct {
+	emailSender repository.EmailSender
+	// locator adds the approximate location to emails; nil leaves it out
+	locator *SessionLocator
+	config  LoginNotificationConfig
+	logger  *slog.Logger
+
+	mu           sync.Mutex
+	lastNotified map[string]time.Time
+}
+
+func NewLoginNotifier(emailSender repository.EmailSender, config LoginNotificationConfig, logger *slog.Logger) *LoginNotifier {
+	return &LoginNotifier{
+		emailSender:  emailSender,
+		config:       config,
+		logger:       logger,
+		lastNotified: make(map[string]time.Time),
+	}
+}
+
+// DeviceFingerprint derives a stable identifier for a client from its user
+// agent and IP address
+func DeviceFingerprint(userAgent string, ipAddress string) string {
+	if userAgent == "" && ipAddress == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(userAgent + "|" + ipAddress))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NotifyIfNewDevice sends a sign-in email in the background when session was
+// created from a device not seen in history. Users without any session
+// history are not notified since there is nothing to compare against.
+func (n *LoginNotifier) NotifyIfNewDevice(ctx context.Context, user *model.User, session *model.Session, history []*model.Session) {
+	if !n.config.Enabled || user.Email == "" || len(history) == 0 {
+		return
+	}
+
+	fingerprint, _ := session.Metadata[MetadataKeyDeviceFingerprint].(string)
+	if fingerprint == "" {
+		return
+	}
+
+	for _, previous := range history {
+		if known, _ := previous.Metadata[MetadataKeyDeviceFingerprint].(string); known == fingerprint {
+			return
+		}
+	}
+
+	if !n.shouldNotify(user.UserID+":"+fingerprint, time.Now()) {
+		return
+	}
+
+	message := n.buildMessage(ctx, user, session)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), loginNotificationTimeout)
+		defer cancel()
+
+		if err := n.emailSender.Send(ctx, message); err != nil {
+			n.logger.Error("Failed to send new device sign-in email", "user_id", user.UserID, "error", err)
+		}
+	}()
+}
+
+// shouldNotify records a notification for key and reports whether it falls
+// outside the debounce window of the previous one
+func (n *LoginNotifier) shouldNotify(key string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if last, ok := n.lastNotified[key]; ok && now.Sub(last) < n.config.DebounceWindow {
+		return false
+	}
+
+	// Drop stale entries so the map does not grow without bound
+	for k, last := range n.lastNotified {
+		if now.Sub(last) >= n.config.DebounceWindow {
+			delete(n.lastNotified, k)
+		}
+	}
+
+	n.lastNotified[key] = now
+	return true
+}
+
+func (n *LoginNotifier) buildMessage(ctx context.Context, user *model.User, session *model.Session) *model.EmailMessage {
+	userAgent, _ := session.Metadata[MetadataKeyUserAgent].(string)
+	ipAddress, _ := session.Metadata[MetadataKeyIPAddress].(string)
+	if userAgent == "" {
+		userAgent = "unknown"
+	}
+	if ipAddress == "" {
+		ipAddress = "unknown"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", user.DisplayName)
+	body.WriteString("A new sign-in to your account was detected from a device we have not seen before.\n\n")
+	fmt.Fprintf(&body, "Time: %s\n", session.CreatedAt.UTC().Format(time.RFC1123))
+	fmt.Fprintf(&body, "Device: %s\n", userAgent)
+	fmt.Fprintf(&body, "IP address: %s\n", ipAddress)
+	if n.locator != nil {
+		if location := n.locator.Locate(ctx, session.Metadata); location != "" {
+			fmt.Fprintf(&body, "Approximate location: %s\n", location)
+		}
+	}
+	body.WriteString("\n")
+	body.WriteString("If this was you, no action is needed.\n")
+	if n.config.RevokeURL != "" {
+		fmt.Fprintf(&body, "If this was not you, revoke the session here: %s\n", n.revokeLink(session.SessionID))
+	}
+
+	return &model.EmailMessage{
+		To:      user.Email,
+		Subject: "New sign-in to your account",
+		Body:    body.String(),
+	}
+}
+
+func (n *LoginNotifier) revokeLink(sessionID string) string {
+	separator := "?"
+	if strings.Contains(n.config.RevokeURL, "?") {
+		separator = "&"
+	}
+	return n.config.RevokeURL + separator + "session_id=" + url.QueryEscape(sessionID)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/infrastructure/storage/memory"
+)
+
+// fakeGeoResolver locates IP addresses from a fixed map
+type fakeGeoResolver map[string]*model.GeoLocation
+
+func (r fakeGeoResolver) Resolve(ctx context.Context, ip string) (*model.GeoLocation, error) {
+	return r[ip], nil
+}
+
+func TestNewDeviceEmailIncludesLocation(t *testing.T) {
+	repo := memory.NewInMemorySessionRepository(100, time.Hour, 0)
+	t.Cleanup(func() { repo.Close() })
+	emails := &fakeEmailSender{}
+	sessions := NewSessionService(repo, emails, &fakePublisher{}, AuthService{}, SessionConfig{
+		LoginNotification: LoginNotificationConfig{Enabled: true, DebounceWindow: time.Hour},
+	}, discardLogger())
+	sessions.SetSessionLocator(NewSessionLocator(fakeGeoResolver{
+		"81.213.1.2": {City: "Istanbul", Country: "TR"},
+	}, time.Hour, 10, discardLogger()))
+
+	user := &model.User{UserID: "user-1", Email: "user@example.com", DisplayName: "User"}
+	ctx := context.Background()
+	if _, err := sessions.CreateSession(ctx, user, CreateSessionOptions{UserAgent: "laptop", IPAddress: "198.51.100.7"}); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if _, err := sessions.CreateSession(ctx, user, CreateSessionOptions{UserAgent: "phone", IPAddress: "81.213.1.2"}); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	message := waitForEmail(t, emails, "New sign-in to your account")
+	if !strings.Contains(message.Body, "IP address: 81.213.1.2\nApproximate location: Istanbul, TR\n") {
+		t.Errorf("email body does not give the location:\n%s", message.Body)
+	}
+}
+
+func TestNewDeviceEmailWithoutKnownLocation(t *testing.T) {
+	notifier := NewLoginNotifier(&fakeEmailSender{}, LoginNotificationConfig{Enabled: true}, discardLogger())
+	notifier.locator = NewSessionLocator(fakeGeoResolver{}, time.Hour, 10, discardLogger())
+	session := &model.Session{Metadata: map[string]interface{}{MetadataKeyIPAddress: "203.0.113.1"}}
+
+	message := notifier.buildMessage(context.Background(), &model.User{Email: "user@example.com"}, session)
+	if strings.Contains(message.Body, "Approximate location") {
+		t.Errorf("email body gives a location for an unknown address:\n%s", message.Body)
+	}
+}
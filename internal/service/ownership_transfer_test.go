@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+func newOwnershipTransferUsers() (*model.User, *model.User) {
+	from := &model.User{UserID: "from", Email: "from@example.com", Status: model.StatusActive, Role: model.RoleUser}
+	to := &model.User{UserID: "to", Email: "to@example.com", Status: model.StatusActive, Role: model.RoleUser}
+	return from, to
+}
+
+func TestRequestOwnershipTransferRequiresAcknowledgementPath(t *testing.T) {
+	from, to := newOwnershipTransferUsers()
+	ts := newTestAuthService(AuthConfig{}, from, to)
+
+	err := ts.RequestOwnershipTransfer(context.Background(), from.UserID, to.UserID, "admin-1")
+	if !errors.IsType(err, errors.ErrorTypeConflict) {
+		t.Fatalf("RequestOwnershipTransfer() error = %v, want a conflict", err)
+	}
+	if status := ts.userRepo.get(from.UserID).OwnershipTransferStatus; status != "" {
+		t.Errorf("transfer status = %q, want none", status)
+	}
+	if events := ts.events.published(model.EventUserOwnershipTransfer); len(events) != 0 {
+		t.Errorf("published %d transfer events, want none", len(events))
+	}
+}
+
+func TestCancelOwnershipTransferAllowsDeletion(t *testing.T) {
+	from, to := newOwnershipTransferUsers()
+	ts := newTestAuthService(AuthConfig{OwnershipTransfers: true}, from, to)
+	ctx := context.Background()
+
+	if err := ts.RequestOwnershipTransfer(ctx, from.UserID, to.UserID, "admin-1"); err != nil {
+		t.Fatalf("RequestOwnershipTransfer() error = %v", err)
+	}
+	if err := ts.DeleteUser(ctx, from.UserID); !errors.IsType(err, errors.ErrorTypeConflict) {
+		t.Fatalf("DeleteUser() error = %v, want a conflict while the transfer is pending", err)
+	}
+
+	if err := ts.CancelOwnershipTransfer(ctx, from.UserID, "admin-1"); err != nil {
+		t.Fatalf("CancelOwnershipTransfer() error = %v", err)
+	}
+	stored := ts.userRepo.get(from.UserID)
+	if stored.OwnershipTransferStatus != "" || stored.OwnershipTransferTo != "" {
+		t.Errorf("transfer = %q to %q after cancelling, want none", stored.OwnershipTransferStatus, stored.OwnershipTransferTo)
+	}
+	if events := ts.events.published(model.EventUserOwnershipTransferCancelled); len(events) != 1 {
+		t.Errorf("published %d cancellation events, want 1", len(events))
+	}
+
+	if err := ts.CancelOwnershipTransfer(ctx, from.UserID, "admin-1"); !errors.IsType(err, errors.ErrorTypeConflict) {
+		t.Errorf("second CancelOwnershipTransfer() error = %v, want a conflict", err)
+	}
+	if err := ts.DeleteUser(ctx, from.UserID); err != nil {
+		t.Errorf("DeleteUser() error = %v after cancelling the transfer", err)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	pendingApprovalBatchSize       = 100
+	defaultPendingApprovalInterval = time.Hour
+)
+
+// PendingApprovalReconciler periodically expires registrations that have
+// been pending longer than the configured TTL
+type PendingApprovalReconciler struct {
+	authService *AuthService
+	interval    time.Duration
+	logger      *slog.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewPendingApprovalReconciler(authService *AuthService, interval time.Duration, logger *slog.Logger) *PendingApprovalReconciler {
+	if interval <= 0 {
+		interval = defaultPendingApprovalInterval
+	}
+
+	return &PendingApprovalReconciler{
+		authService: authService,
+		interval:    interval,
+		logger:      logger,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start runs the reconciler in the background until Close is called
+func (r *PendingApprovalReconciler) Start() {
+	go r.run()
+}
+
+// Close stops the reconciler. It is safe to call more than once.
+func (r *PendingApprovalReconciler) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *PendingApprovalReconciler) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.reconcile()
+		}
+	}
+}
+
+func (r *PendingApprovalReconciler) reconcile() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+	defer cancel()
+
+	expired, err := r.authService.ExpirePendingUsers(ctx, pendingApprovalBatchSize)
+	if err != nil {
+		r.logger.Error("Pending approval reconciliation failed", "error", err)
+		return
+	}
+	if expired > 0 {
+		r.logger.Info("Expired stale pending registrations", "count", expired)
+	}
+}
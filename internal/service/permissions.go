@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// GetUserPermissions computes what a user may do from their role and
+// status. Every authenticated route requires an active account, so users
+// who are not active have none of their role's permissions.
+func (s *AuthService) GetUserPermissions(ctx context.Context, userID string) (*model.UserPermissions, error) {
+	user, err := s.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := &model.UserPermissions{
+		UserID:          user.UserID,
+		Role:            user.Role,
+		Status:          user.Status,
+		RolePermissions: user.Role.Permissions(),
+		Permissions:     []model.Permission{},
+	}
+	if user.Status == model.StatusActive {
+		permissions.Permissions = user.Role.Permissions()
+	} else if statusErr := s.AccountStatusError(user.Status); statusErr != nil {
+		permissions.Reason = statusErr.Message
+	}
+	return permissions, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+func TestGetUserPermissions(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *model.User
+		wantAdmin  bool
+		wantActive bool
+	}{
+		{name: "active admin", user: &model.User{UserID: "admin", Role: model.RoleAdmin, Status: model.StatusActive}, wantAdmin: true, wantActive: true},
+		{name: "active viewer", user: &model.User{UserID: "viewer", Role: model.RoleViewer, Status: model.StatusActive}, wantActive: true},
+		{name: "suspended admin", user: &model.User{UserID: "suspended", Role: model.RoleAdmin, Status: model.StatusSuspended}, wantAdmin: true},
+		{name: "pending user", user: &model.User{UserID: "pending", Role: model.RoleUnassigned, Status: model.StatusPending}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestAuthService(AuthConfig{}, tt.user)
+
+			got, err := ts.GetUserPermissions(context.Background(), tt.user.UserID)
+			if err != nil {
+				t.Fatalf("GetUserPermissions() error = %v", err)
+			}
+			if got.Role != tt.user.Role || got.Status != tt.user.Status {
+				t.Errorf("role and status = %s/%s, want %s/%s", got.Role, got.Status, tt.user.Role, tt.user.Status)
+			}
+			if isAdmin := slices.Contains(got.RolePermissions, model.PermissionAdminUsers); isAdmin != tt.wantAdmin {
+				t.Errorf("role permissions = %v, want admin permissions %v", got.RolePermissions, tt.wantAdmin)
+			}
+
+			if tt.wantActive {
+				if !slices.Equal(got.Permissions, got.RolePermissions) || got.Reason != "" {
+					t.Errorf("permissions = %v (%q), want the role's %v", got.Permissions, got.Reason, got.RolePermissions)
+				}
+				return
+			}
+			if len(got.Permissions) != 0 || got.Reason == "" {
+				t.Errorf("permissions = %v (%q), want none with a reason", got.Permissions, got.Reason)
+			}
+		})
+	}
+}
+
+func TestGetUserPermissionsUnknownUser(t *testing.T) {
+	ts := newTestAuthService(AuthConfig{})
+	if _, err := ts.GetUserPermissions(context.Background(), "missing"); err == nil {
+		t.Error("GetUserPermissions() found a user that does not exist")
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+const roleChangeNotificationTimeout = 30 * time.Second
+
+// roleDescriptions explains to users what each role lets them do
+var roleDescriptions = map[model.UserRole]string{
+	model.RoleAdmin:      "You can now manage users, approve registrations and access all administrative features.",
+	model.RoleUser:       "You can view, upload and annotate slides in the projects you belong to.",
+	model.RoleViewer:     "You can view slides and annotations but cannot change them.",
+	model.RoleUnassigned: "Your account currently has no access to slides until an administrator assigns you a role.",
+}
+
+// notifyRoleChange emails user about their new role in the background.
+// It does nothing unless role change notifications are enabled and the role
+// actually changed.
+func (s *AuthService) notifyRoleChange(ctx context.Context, user *model.User, newRole model.UserRole) {
+	if !s.config.NotifyRoleChange || user.Email == "" || user.Role == newRole {
+		return
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", user.DisplayName)
+	fmt.Fprintf(&body, "An administrator changed your role from %s to %s.\n\n", user.Role, newRole)
+	if description, ok := roleDescriptions[newRole]; ok {
+		body.WriteString(description + "\n\n")
+	}
+	body.WriteString("If you did not expect this change, please contact support.\n")
+
+	message := &model.EmailMessage{
+		To:      user.Email,
+		Subject: "Your account role has changed",
+		Body:    body.String(),
+	}
+
+	// The email must not hold up or depend on the admin's request
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), roleChangeNotificationTimeout)
+		defer cancel()
+
+		if err := s.emailSender.Send(ctx, message); err != nil {
+			s.logger.Warn("failed to send role change email", "user_id", user.UserID, "error", err)
+		}
+	}()
+}
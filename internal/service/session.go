@@ -4,13 +4,19 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"sort"
 	"time"
 
 	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/domain/repository"
 	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/internal/shared/query"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -18,60 +24,409 @@ const (
 	MaxSessionsPerUser     = 3
 )
 
-type SessionService struct {
-	sessionRepo repository.SessionRepository
-	authService AuthService
-	logger      *slog.Logger
+// Reserved session metadata keys. They are set by the auth service itself and
+// cannot be changed through metadata updates:
+//   - user_agent: User-Agent of the client that created the session, truncated
+//     to MaxUserAgentLength
+//   - ip_address: client IP the session was created from
+//   - device_fingerprint: DeviceFingerprint of the user agent and IP address,
+//     used to detect sign-ins from new devices
+//   - label: display name for the session
+//   - remember_me: true when the user asked to stay signed in, giving the
+//     session the longer RememberMeDuration lifetime
+const (
+	MetadataKeyUserAgent         = "user_agent"
+	MetadataKeyIPAddress         = "ip_address"
+	MetadataKeyDeviceFingerprint = "device_fingerprint"
+	MetadataKeyLabel             = "label"
+	MetadataKeyRememberMe        = "remember_me"
+)
+
+// Default limits on session metadata, counting reserved keys. The size is
+// measured on the JSON encoding of the whole metadata map.
+const (
+	DefaultMaxSessionMetadataKeys  = 32
+	DefaultMaxSessionMetadataBytes = 4096
+)
+
+// MaxUserAgentLength caps the stored user agent so a long header cannot
+// exhaust the metadata size limit
+const MaxUserAgentLength = 512
+
+var reservedMetadataKeys = map[string]bool{
+	MetadataKeyUserAgent:         true,
+	MetadataKeyIPAddress:         true,
+	MetadataKeyDeviceFingerprint: true,
+	MetadataKeyLabel:             true,
+	MetadataKeyRememberMe:        true,
+}
+
+// Session scopes narrow what a session is intended for
+const (
+	ScopeDefault    = model.DefaultSessionScope
+	ScopeImageServe = "image-serve"
+	ScopeAdminOps   = "admin-ops"
+)
+
+// ScopeConfig defines lifetime and access rules for a session scope
+type ScopeConfig struct {
+	Expiration         time.Duration
+	MaxSessionsPerUser int
+	// AllowedRoles restricts who may create sessions in the scope; empty allows every role
+	AllowedRoles []model.UserRole
+	// ExtendThreshold extends a session on use once its remaining lifetime
+	// drops below this fraction of Expiration; zero uses DefaultExtendThreshold.
+	// Lower values mean fewer store writes for chatty clients.
+	ExtendThreshold float64
+	// ExtendInterval also extends a session once this long has passed since
+	// it was created or last extended; zero relies on ExtendThreshold alone
+	ExtendInterval time.Duration
+	// IdleTimeout ends a session that has not been used for this long; zero disables it
+	IdleTimeout time.Duration
 }
 
-func NewSessionService(sessionRepo repository.SessionRepository, authService AuthService, logger *slog.Logger) *SessionService {
-	return &SessionService{
-		sessionRepo: sessionRepo,
-		authService: authService,
-		logger:      logger,
+// SessionLimitPolicy is what happens when a new session would exceed the
+// cross-scope session cap
+type SessionLimitPolicy string
+
+const (
+	// SessionLimitEvict deletes the user's least recently used sessions
+	SessionLimitEvict SessionLimitPolicy = "evict"
+	// SessionLimitReject refuses to create the new session
+	SessionLimitReject SessionLimitPolicy = "reject"
+)
+
+// DefaultExtendThreshold extends sessions once half their lifetime is used
+const DefaultExtendThreshold = 0.5
+
+// DefaultScopeConfigs returns the built-in session scopes
+func DefaultScopeConfigs() map[string]ScopeConfig {
+	return map[string]ScopeConfig{
+		ScopeDefault: {
+			Expiration:         DefaultSessionDuration,
+			MaxSessionsPerUser: MaxSessionsPerUser,
+		},
+		ScopeImageServe: {
+			Expiration:         10 * time.Minute,
+			MaxSessionsPerUser: 5,
+		},
+		ScopeAdminOps: {
+			Expiration:         15 * time.Minute,
+			MaxSessionsPerUser: 1,
+			AllowedRoles:       []model.UserRole{model.RoleAdmin},
+		},
 	}
 }
 
-func (s *SessionService) CreateSession(ctx context.Context, userID string) (string, error) {
+// SessionConfig holds lifecycle settings for the session service
+type SessionConfig struct {
+	// ExpiryGracePeriod is how long past ExpiresAt a session is still accepted.
+	// Sessions inside the grace window are never extended.
+	ExpiryGracePeriod time.Duration
+	// ScopeConfigs defines the available session scopes; nil uses DefaultScopeConfigs
+	ScopeConfigs map[string]ScopeConfig
+	// LoginNotification configures emails for sign-ins from new devices
+	LoginNotification LoginNotificationConfig
+	// MaxMetadataKeys and MaxMetadataBytes bound session metadata; zero uses
+	// DefaultMaxSessionMetadataKeys and DefaultMaxSessionMetadataBytes
+	MaxMetadataKeys  int
+	MaxMetadataBytes int
+	// TokenScopeClaim names the ID token claim that identifies the app the
+	// token was minted for. When set, TokenScopes limits the scopes a token
+	// may create sessions in by that claim's value; tokens whose value is
+	// missing or unlisted may only create default-scope sessions.
+	TokenScopeClaim string
+	TokenScopes     map[string][]string
+	// RememberMeDuration replaces the default scope's expiration for sessions
+	// created with RememberMe; zero disables remember me
+	RememberMeDuration time.Duration
+	// MaxLifetime caps how long after creation any session may live,
+	// however often it is extended; zero leaves sessions uncapped
+	MaxLifetime time.Duration
+	// MaxSessionsTotal caps a user's sessions across all scopes on top of
+	// each scope's MaxSessionsPerUser; zero disables the cap
+	MaxSessionsTotal int
+	// TotalLimitPolicy applies when MaxSessionsTotal is reached; empty
+	// means SessionLimitEvict
+	TotalLimitPolicy SessionLimitPolicy
+}
+
+// CreateSessionOptions carries optional parameters for session creation
+type CreateSessionOptions struct {
+	// Scope selects the session scope; empty means ScopeDefault
+	Scope string
+	// UserAgent and IPAddress describe the client creating the session
+	UserAgent string
+	IPAddress string
+	// TokenClaims are the claims of the ID token the session is created
+	// from, checked against the token scope policy
+	TokenClaims map[string]interface{}
+	// RememberMe asks for a long-lived default-scope session
+	RememberMe bool
+}
+
+// SessionService manages opaque sessions created from Firebase ID tokens.
+// Handlers, middleware and the proxy depend on this interface rather than
+// on SessionServiceImpl.
+type SessionService interface {
+	// CreateSession stores a new session for user and returns it, so callers
+	// need not read it back
+	CreateSession(ctx context.Context, user *model.User, opts CreateSessionOptions) (*model.Session, error)
+	ValidateSession(ctx context.Context, sessionID string) (*model.Session, error)
+	// ValidateAndExtend validates a session and slides its expiry
+	ValidateAndExtend(ctx context.Context, sessionID string) (*model.Session, error)
+	ExtendSession(ctx context.Context, sessionID string) error
+	// IsInGracePeriod reports whether a session is past expiry but still accepted
+	IsInGracePeriod(session *model.Session) bool
+	MergeSessionMetadata(ctx context.Context, sessionID string, userID string, values map[string]interface{}) (*model.Session, error)
+	// RevokeSession deletes a session and returns it, or a NotFound error
+	// when no such session exists
+	RevokeSession(ctx context.Context, sessionID string) (*model.Session, error)
+	RevokeAllUserSessions(ctx context.Context, userID string) (*model.SessionRevocation, error)
+	// PurgeUserSessions deletes every session of a user, expired ones
+	// included, and returns how many active sessions were revoked
+	PurgeUserSessions(ctx context.Context, userID string) (int, error)
+	GetUserSessionStats(ctx context.Context, userID string) (*model.SessionStats, error)
+	ListUserSessions(ctx context.Context, userID string, pagination *query.Pagination) (*model.SessionStats, error)
+	GetUserSessionsByScope(ctx context.Context, userID string) (*model.ScopedSessionStats, error)
+	GetActiveSessionCount(ctx context.Context, userID string) (int, error)
+}
+
+type SessionServiceImpl struct {
+	sessionRepo   repository.SessionRepository
+	authService   AuthService
+	loginNotifier *LoginNotifier
+	// activity receives session lifecycle events for analytics
+	activity repository.EventPublisher
+	// enricher adds deployment-specific metadata to new sessions
+	enricher SessionEnricher
+	// sessionReads shares one store read between concurrent validations of
+	// the same session
+	sessionReads *singleflight.Group
+	config       SessionConfig
+	logger       *slog.Logger
+}
+
+func NewSessionService(sessionRepo repository.SessionRepository, emailSender repository.EmailSender, activity repository.EventPublisher, authService AuthService, config SessionConfig, logger *slog.Logger) *SessionServiceImpl {
+	if config.ScopeConfigs == nil {
+		config.ScopeConfigs = DefaultScopeConfigs()
+	}
+	if config.MaxMetadataKeys <= 0 {
+		config.MaxMetadataKeys = DefaultMaxSessionMetadataKeys
+	}
+	if config.MaxMetadataBytes <= 0 {
+		config.MaxMetadataBytes = DefaultMaxSessionMetadataBytes
+	}
+	if config.TotalLimitPolicy == "" {
+		config.TotalLimitPolicy = SessionLimitEvict
+	}
+
+	return &SessionServiceImpl{
+		sessionRepo:   sessionRepo,
+		authService:   authService,
+		loginNotifier: NewLoginNotifier(emailSender, config.LoginNotification, logger),
+		activity:      activity,
+		enricher:      NoopSessionEnricher{},
+		sessionReads:  &singleflight.Group{},
+		config:        config,
+		logger:        logger,
+	}
+}
+
+func (s *SessionServiceImpl) CreateSession(ctx context.Context, user *model.User, opts CreateSessionOptions) (*model.Session, error) {
+	scope := opts.Scope
+	if scope == "" {
+		scope = ScopeDefault
+	}
+
+	scopeCfg, err := s.authorizeScope(scope, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTokenScope(scope, opts.TokenClaims); err != nil {
+		return nil, err
+	}
+	if opts.RememberMe && scope != ScopeDefault {
+		return nil, errors.NewValidationError("remember me only applies to default-scope sessions", map[string]interface{}{
+			"scope": scope,
+		})
+	}
 
 	sessionID, err := s.generateSessionID(32)
 	if err != nil {
-		return "", errors.NewInternalError("failed to generate session ID", err)
+		return nil, errors.NewInternalError("failed to generate session ID", err)
 	}
 
 	now := time.Now()
 	session := &model.Session{
 		SessionID:    sessionID,
-		UserID:       userID,
+		UserID:       user.UserID,
+		Scope:        scope,
 		CreatedAt:    now,
-		ExpiresAt:    now.Add(DefaultSessionDuration),
 		LastUsedAt:   now,
 		RequestCount: 0,
 		Metadata:     make(map[string]interface{}),
 	}
+	if opts.RememberMe && s.config.RememberMeDuration > 0 {
+		session.Metadata[MetadataKeyRememberMe] = true
+	}
+	session.ExpiresAt = s.expiryFrom(session, scopeCfg, now)
+	if opts.UserAgent != "" {
+		userAgent := opts.UserAgent
+		if len(userAgent) > MaxUserAgentLength {
+			userAgent = userAgent[:MaxUserAgentLength]
+		}
+		session.Metadata[MetadataKeyUserAgent] = userAgent
+	}
+	if opts.IPAddress != "" {
+		session.Metadata[MetadataKeyIPAddress] = opts.IPAddress
+	}
+	if fingerprint := DeviceFingerprint(opts.UserAgent, opts.IPAddress); fingerprint != "" {
+		session.Metadata[MetadataKeyDeviceFingerprint] = fingerprint
+	}
+	if err := s.validateMetadataSize(session.Metadata); err != nil {
+		return nil, err
+	}
+	s.enrichMetadata(ctx, user, session)
 
-	if err := s.enforceMaxSessions(ctx, userID, MaxSessionsPerUser); err != nil {
-		return "", err
+	// Capture the device history before older sessions are evicted
+	history, err := s.sessionRepo.ListByUser(ctx, user.UserID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list user sessions", err)
+	}
+
+	if err := s.enforceMaxSessions(ctx, user.UserID, scope, scopeCfg.MaxSessionsPerUser); err != nil {
+		return nil, err
+	}
+	if err := s.enforceTotalSessions(ctx, user.UserID); err != nil {
+		return nil, err
 	}
 
 	createdID, err := s.sessionRepo.Create(ctx, session)
 	if err != nil {
-		return "", fmt.Errorf("failed to create session: %w", err)
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	session.SessionID = createdID
+
+	s.loginNotifier.NotifyIfNewDevice(ctx, user, session, history)
+
+	remembered, _ := session.Metadata[MetadataKeyRememberMe].(bool)
+	publishActivity(ctx, s.activity, s.logger, model.EventSessionCreated, map[string]interface{}{
+		"user_id":     user.UserID,
+		"scope":       scope,
+		"expires_at":  session.ExpiresAt,
+		"remember_me": remembered,
+	})
 
-	return createdID, nil
+	return session, nil
 }
 
-func (s *SessionService) ValidateSession(ctx context.Context, sessionID string) (*model.Session, error) {
-	session, err := s.sessionRepo.Get(ctx, sessionID)
+// authorizeScope returns the configuration of a scope after checking that
+// the scope exists and the role may create sessions in it
+func (s *SessionServiceImpl) authorizeScope(scope string, role model.UserRole) (ScopeConfig, error) {
+	scopeCfg, ok := s.config.ScopeConfigs[scope]
+	if !ok {
+		return ScopeConfig{}, errors.NewValidationError("unknown session scope", map[string]interface{}{
+			"scope": scope,
+		})
+	}
+
+	if len(scopeCfg.AllowedRoles) == 0 {
+		return scopeCfg, nil
+	}
+	for _, allowed := range scopeCfg.AllowedRoles {
+		if allowed == role {
+			return scopeCfg, nil
+		}
+	}
+
+	err := errors.NewForbiddenError("role is not allowed to create sessions in this scope")
+	err.Details = map[string]interface{}{
+		"scope":          scope,
+		"role":           role,
+		"required_roles": scopeCfg.AllowedRoles,
+	}
+	return ScopeConfig{}, err
+}
+
+// authorizeTokenScope checks that the token a session is created from was
+// minted for an app allowed to use the scope, so a session never carries
+// more privilege than the authentication it came from
+func (s *SessionServiceImpl) authorizeTokenScope(scope string, claims map[string]interface{}) error {
+	if s.config.TokenScopeClaim == "" || scope == ScopeDefault {
+		return nil
+	}
+
+	tokenApp, _ := claims[s.config.TokenScopeClaim].(string)
+	allowed := s.config.TokenScopes[tokenApp]
+	for _, allowedScope := range allowed {
+		if allowedScope == scope {
+			return nil
+		}
+	}
+
+	err := errors.NewForbiddenError("token is not allowed to create sessions in this scope")
+	err.Details = map[string]interface{}{
+		"scope":          scope,
+		"token_app":      tokenApp,
+		"allowed_scopes": append([]string{ScopeDefault}, allowed...),
+	}
+	return err
+}
+
+// scopeConfigFor returns the configuration for the scope a session was created in
+func (s *SessionServiceImpl) scopeConfigFor(session *model.Session) (ScopeConfig, bool) {
+	scopeCfg, ok := s.config.ScopeConfigs[session.ScopeOrDefault()]
+	return scopeCfg, ok
+}
+
+// readSession reads a session for validation. Concurrent validations of the
+// same session (e.g. a burst of tile requests carrying one cookie) share a
+// single store read, detached from the first caller's cancellation. Each
+// caller gets its own copy to update.
+func (s *SessionServiceImpl) readSession(ctx context.Context, sessionID string) (*model.Session, error) {
+	result, err, _ := s.sessionReads.Do(sessionID, func() (interface{}, error) {
+		return s.sessionRepo.Get(context.WithoutCancel(ctx), sessionID)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if time.Now().After(session.ExpiresAt) {
-		_ = s.sessionRepo.Delete(ctx, sessionID)
+	session := *result.(*model.Session)
+	session.Metadata = maps.Clone(session.Metadata)
+	return &session, nil
+}
+
+// deleteSession deletes a session. Validations starting afterwards do not
+// join a read already in flight, which may still find the session.
+func (s *SessionServiceImpl) deleteSession(ctx context.Context, sessionID string) error {
+	err := s.sessionRepo.Delete(ctx, sessionID)
+	s.sessionReads.Forget(sessionID)
+	return err
+}
+
+func (s *SessionServiceImpl) ValidateSession(ctx context.Context, sessionID string) (*model.Session, error) {
+	session, err := s.readSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(session.ExpiresAt.Add(s.config.ExpiryGracePeriod)) {
+		_ = s.deleteSession(ctx, sessionID)
 		return nil, errors.NewNotFoundError("session_expired")
 	}
+
+	// Sessions of a scope that is no longer configured are not honored
+	scopeCfg, ok := s.scopeConfigFor(session)
+	if !ok {
+		_ = s.deleteSession(ctx, sessionID)
+		return nil, errors.NewNotFoundError("session_scope_invalid")
+	}
+
+	if scopeCfg.IdleTimeout > 0 && !session.LastUsedAt.IsZero() && time.Since(session.LastUsedAt) > scopeCfg.IdleTimeout {
+		_ = s.deleteSession(ctx, sessionID)
+		return nil, errors.NewNotFoundError("session_idle")
+	}
 	session.LastUsedAt = time.Now()
 	session.RequestCount++
 
@@ -82,62 +437,319 @@ func (s *SessionService) ValidateSession(ctx context.Context, sessionID string)
 	return session, nil
 }
 
-func (s *SessionService) ExtendSession(ctx context.Context, sessionID string) error {
+func (s *SessionServiceImpl) ExtendSession(ctx context.Context, sessionID string) error {
 	session, err := s.sessionRepo.Get(ctx, sessionID)
 	if err != nil {
 		return err
 	}
 
-	session.ExpiresAt = time.Now().Add(DefaultSessionDuration)
+	if session.IsExpired(time.Now()) {
+		return errors.NewNotFoundError("session_expired")
+	}
 
-	if err := s.sessionRepo.Update(ctx, sessionID, session); err != nil {
+	scopeCfg, ok := s.scopeConfigFor(session)
+	if !ok {
+		return errors.NewNotFoundError("session_scope_invalid")
+	}
+
+	// A session at its lifetime cap has nothing left to extend
+	expiresAt := s.expiryFrom(session, scopeCfg, time.Now())
+	if !expiresAt.After(session.ExpiresAt) {
+		return nil
+	}
+	// The repository may return the session it stores, which concurrent
+	// validations copy, so changes are made to a copy of it
+	updated := *session
+	updated.ExpiresAt = expiresAt
+
+	if err := s.sessionRepo.Update(ctx, sessionID, &updated); err != nil {
 		return errors.NewInternalError("failed to extend session", err)
 	}
 
 	return nil
 }
 
-func (s *SessionService) RevokeSession(ctx context.Context, sessionID string) error {
-	if err := s.sessionRepo.Delete(ctx, sessionID); err != nil {
-		return errors.NewInternalError("failed to revoke session", err)
+// lifetime returns how long a session lives between extensions
+func (s *SessionServiceImpl) lifetime(session *model.Session, scopeCfg ScopeConfig) time.Duration {
+	if remembered, _ := session.Metadata[MetadataKeyRememberMe].(bool); remembered && s.config.RememberMeDuration > 0 {
+		return s.config.RememberMeDuration
+	}
+	return scopeCfg.Expiration
+}
+
+// expiryFrom returns the expiry of a session created or extended at now,
+// capped at MaxLifetime after its creation
+func (s *SessionServiceImpl) expiryFrom(session *model.Session, scopeCfg ScopeConfig, now time.Time) time.Time {
+	expiresAt := now.Add(s.lifetime(session, scopeCfg))
+	if s.config.MaxLifetime > 0 {
+		if limit := session.CreatedAt.Add(s.config.MaxLifetime); expiresAt.After(limit) {
+			return limit
+		}
+	}
+	return expiresAt
+}
+
+// MergeSessionMetadata merges values into the metadata of a session owned by
+// userID. A nil value removes the key. Reserved keys cannot be modified and
+// the merged metadata must stay within the size limits.
+func (s *SessionServiceImpl) MergeSessionMetadata(ctx context.Context, sessionID string, userID string, values map[string]interface{}) (*model.Session, error) {
+	session, err := s.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(session.ExpiresAt.Add(s.config.ExpiryGracePeriod)) {
+		return nil, errors.NewNotFoundError("session_expired")
+	}
+
+	if session.UserID != userID {
+		return nil, errors.NewForbiddenError("session does not belong to the specified user")
+	}
+
+	reserved := make([]string, 0)
+	for key := range values {
+		if reservedMetadataKeys[key] {
+			reserved = append(reserved, key)
+		}
+	}
+	if len(reserved) > 0 {
+		return nil, errors.NewValidationError("metadata contains reserved keys", map[string]interface{}{
+			"reserved_keys": reserved,
+		})
+	}
+
+	merged := make(map[string]interface{}, len(session.Metadata)+len(values))
+	for key, value := range session.Metadata {
+		merged[key] = value
+	}
+	for key, value := range values {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+
+	if err := s.validateMetadataSize(merged); err != nil {
+		return nil, err
+	}
+
+	updated := *session
+	updated.Metadata = merged
+	if err := s.sessionRepo.Update(ctx, sessionID, &updated); err != nil {
+		return nil, errors.NewInternalError("failed to update session metadata", err)
+	}
+
+	return &updated, nil
+}
+
+// validateMetadataSize checks metadata against the configured key count and
+// serialized size limits
+func (s *SessionServiceImpl) validateMetadataSize(metadata map[string]interface{}) error {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.NewValidationError("metadata values must be JSON serializable", nil)
+	}
+	if len(metadata) > s.config.MaxMetadataKeys || len(encoded) > s.config.MaxMetadataBytes {
+		return errors.NewValidationError("session metadata exceeds size limit", map[string]interface{}{
+			"keys":      len(metadata),
+			"bytes":     len(encoded),
+			"max_keys":  s.config.MaxMetadataKeys,
+			"max_bytes": s.config.MaxMetadataBytes,
+		})
 	}
 	return nil
 }
 
-func (s *SessionService) RevokeAllUserSessions(ctx context.Context, userID string) error {
+func (s *SessionServiceImpl) RevokeSession(ctx context.Context, sessionID string) (*model.Session, error) {
+	// Read first so the caller and the revocation event can name the owner
+	session, err := s.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			return nil, errors.NewNotFoundError("session_not_found")
+		}
+		return nil, errors.NewInternalError("failed to get session", err)
+	}
+
+	if err := s.deleteSession(ctx, sessionID); err != nil {
+		// Deleted concurrently, e.g. by expiry cleanup
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			return nil, errors.NewNotFoundError("session_not_found")
+		}
+		return nil, errors.NewInternalError("failed to revoke session", err)
+	}
+
+	s.publishRevoked(ctx, session, "revoked")
+	return session, nil
+}
+
+func (s *SessionServiceImpl) publishRevoked(ctx context.Context, session *model.Session, reason string) {
+	publishActivity(ctx, s.activity, s.logger, model.EventSessionRevoked, map[string]interface{}{
+		"user_id": session.UserID,
+		"scope":   session.ScopeOrDefault(),
+		"reason":  reason,
+	})
+}
+
+// RevokeAllUserSessions deletes every session of a user one by one so that
+// partial failures are visible, and returns the outcome per session. A
+// failed delete does not stop the others.
+func (s *SessionServiceImpl) RevokeAllUserSessions(ctx context.Context, userID string) (*model.SessionRevocation, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list user sessions", err)
+	}
+
+	result := &model.SessionRevocation{}
+	for _, session := range sessions {
+		if err := s.deleteSession(ctx, session.SessionID); err != nil {
+			if errors.IsType(err, errors.ErrorTypeNotFound) {
+				continue
+			}
+			s.logger.Error("Failed to revoke session", "session_id", session.SessionID, "user_id", userID, "error", err)
+			result.Failed = append(result.Failed, model.SessionRevocationFailure{
+				SessionID: session.SessionID,
+				Reason:    revocationFailureReason(err),
+			})
+			continue
+		}
+		result.Revoked++
+		s.publishRevoked(ctx, session, "revoke_all")
+	}
+
+	return result, nil
+}
+
+// revocationFailureReason describes a failed session delete without
+// exposing internal error details
+func revocationFailureReason(err error) string {
+	switch {
+	case errors.IsType(err, errors.ErrorTypeTimeout) || stderrors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.IsType(err, errors.ErrorTypeRateLimited):
+		return "store is rate limited"
+	default:
+		return "store error"
+	}
+}
+
+// PurgeUserSessions removes a user's sessions in one store operation, for
+// users that no longer exist. Unlike RevokeAllUserSessions it also removes
+// expired sessions not yet swept, leaving nothing of the user in the store.
+func (s *SessionServiceImpl) PurgeUserSessions(ctx context.Context, userID string) (int, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, errors.NewInternalError("failed to list user sessions", err)
+	}
+
 	if err := s.sessionRepo.DeleteByUser(ctx, userID); err != nil {
-		return errors.NewInternalError("failed to revoke user sessions", err)
+		return 0, errors.NewInternalError("failed to delete user sessions", err)
 	}
-	return nil
+
+	for _, session := range sessions {
+		s.sessionReads.Forget(session.SessionID)
+		s.publishRevoked(ctx, session, "user_deleted")
+	}
+	return len(sessions), nil
 }
 
-func (s *SessionService) GetUserSessionStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+func (s *SessionServiceImpl) GetUserSessionStats(ctx context.Context, userID string) (*model.SessionStats, error) {
 	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
 	if err != nil {
 		return nil, errors.NewInternalError("failed to list user sessions", err)
 	}
 
-	stats := map[string]interface{}{
-		"active_sessions": len(sessions),
-		"sessions":        make([]map[string]interface{}, 0, len(sessions)),
+	stats := &model.SessionStats{
+		ActiveSessions: len(sessions),
+		Sessions:       make([]model.SessionInfo, 0, len(sessions)),
 	}
 
-	sessionList := stats["sessions"].([]map[string]interface{})
 	for _, session := range sessions {
-		sessionList = append(sessionList, map[string]interface{}{
-			"session_id":    session.SessionID,
-			"created_at":    session.CreatedAt,
-			"expires_at":    session.ExpiresAt,
-			"last_used":     session.LastUsedAt,
-			"request_count": session.RequestCount,
+		stats.Sessions = append(stats.Sessions, toSessionInfo(session))
+	}
+
+	return stats, nil
+}
+
+// ListUserSessions returns one page of a user's sessions, newest first.
+// ActiveSessions in the result counts all of the user's sessions, not just
+// those on the page.
+func (s *SessionServiceImpl) ListUserSessions(ctx context.Context, userID string, pagination *query.Pagination) (*model.SessionStats, error) {
+	sessions, total, err := s.sessionRepo.ListByUserPaged(ctx, userID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list user sessions", err)
+	}
+
+	stats := &model.SessionStats{
+		ActiveSessions: total,
+		Sessions:       make([]model.SessionInfo, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		stats.Sessions = append(stats.Sessions, toSessionInfo(session))
+	}
+
+	return stats, nil
+}
+
+func toSessionInfo(session *model.Session) model.SessionInfo {
+	return model.SessionInfo{
+		SessionID:    session.SessionID,
+		Scope:        session.ScopeOrDefault(),
+		CreatedAt:    session.CreatedAt,
+		ExpiresAt:    session.ExpiresAt,
+		LastUsedAt:   session.LastUsedAt,
+		RequestCount: session.RequestCount,
+		Metadata:     session.Metadata,
+	}
+}
+
+// GetUserSessionsByScope groups a user's sessions by scope. Every configured
+// scope is listed, including those without sessions, ordered by scope name.
+// Sessions in scopes that are no longer configured are omitted since they
+// are rejected on validation.
+func (s *SessionServiceImpl) GetUserSessionsByScope(ctx context.Context, userID string) (*model.ScopedSessionStats, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list user sessions", err)
+	}
+
+	byScope := make(map[string][]model.SessionInfo, len(s.config.ScopeConfigs))
+	for _, session := range sessions {
+		scope := session.ScopeOrDefault()
+		byScope[scope] = append(byScope[scope], toSessionInfo(session))
+	}
+
+	scopes := make([]string, 0, len(s.config.ScopeConfigs))
+	for scope := range s.config.ScopeConfigs {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	stats := &model.ScopedSessionStats{
+		MaxSessions: s.config.MaxSessionsTotal,
+		Scopes:      make([]model.ScopeSessionStats, 0, len(scopes)),
+	}
+	for _, scope := range scopes {
+		scopeCfg := s.config.ScopeConfigs[scope]
+		scopeSessions := byScope[scope]
+		if scopeSessions == nil {
+			scopeSessions = []model.SessionInfo{}
+		}
+
+		stats.ActiveSessions += len(scopeSessions)
+		stats.Scopes = append(stats.Scopes, model.ScopeSessionStats{
+			Scope:              scope,
+			Expiration:         scopeCfg.Expiration,
+			MaxSessionsPerUser: scopeCfg.MaxSessionsPerUser,
+			ActiveSessions:     len(scopeSessions),
+			Sessions:           scopeSessions,
 		})
 	}
-	stats["sessions"] = sessionList
 
 	return stats, nil
 }
 
-func (s *SessionService) GetActiveSessionCount(ctx context.Context, userID string) (int, error) {
+func (s *SessionServiceImpl) GetActiveSessionCount(ctx context.Context, userID string) (int, error) {
 	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
 	if err != nil {
 		return 0, err
@@ -145,7 +757,7 @@ func (s *SessionService) GetActiveSessionCount(ctx context.Context, userID strin
 	return len(sessions), nil
 }
 
-func (s *SessionService) generateSessionID(length int) (string, error) {
+func (s *SessionServiceImpl) generateSessionID(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
@@ -153,11 +765,18 @@ func (s *SessionService) generateSessionID(length int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func (s *SessionService) enforceMaxSessions(ctx context.Context, userID string, maxSessions int) error {
-	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+func (s *SessionServiceImpl) enforceMaxSessions(ctx context.Context, userID string, scope string, maxSessions int) error {
+	userSessions, err := s.sessionRepo.ListByUser(ctx, userID)
 	if err != nil {
 		return err
 	}
+
+	sessions := make([]*model.Session, 0, len(userSessions))
+	for _, session := range userSessions {
+		if session.ScopeOrDefault() == scope {
+			sessions = append(sessions, session)
+		}
+	}
 	if len(sessions) < maxSessions {
 		return nil
 	}
@@ -165,15 +784,52 @@ func (s *SessionService) enforceMaxSessions(ctx context.Context, userID string,
 	toDelete := len(sessions) - maxSessions + 1
 	oldestSessions := s.findOldestSessions(sessions, toDelete)
 	for _, session := range oldestSessions {
-		if err := s.sessionRepo.Delete(ctx, session.SessionID); err != nil {
+		if err := s.deleteSession(ctx, session.SessionID); err != nil {
+			s.logger.Warn("failed to delete old session", "sessionID", session.SessionID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// enforceTotalSessions makes room for a new session under MaxSessionsTotal.
+// It runs after per-scope eviction, so a session created in a full scope
+// replaces that scope's oldest session and is only rejected when the total
+// was already over the cap, e.g. after the cap was lowered.
+func (s *SessionServiceImpl) enforceTotalSessions(ctx context.Context, userID string) error {
+	if s.config.MaxSessionsTotal <= 0 {
+		return nil
+	}
+
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	excess := len(sessions) - s.config.MaxSessionsTotal + 1
+	if excess <= 0 {
+		return nil
+	}
+
+	if s.config.TotalLimitPolicy == SessionLimitReject {
+		return errors.NewConflictError("maximum number of active sessions reached; sign out of another session first", map[string]interface{}{
+			"active_sessions": len(sessions),
+			"max_sessions":    s.config.MaxSessionsTotal,
+		})
+	}
+
+	for _, session := range s.findOldestSessions(sessions, excess) {
+		if err := s.deleteSession(ctx, session.SessionID); err != nil {
 			s.logger.Warn("failed to delete old session", "sessionID", session.SessionID, "error", err)
+			continue
 		}
+		s.publishRevoked(ctx, session, "session_limit")
 	}
 
 	return nil
 }
 
-func (s *SessionService) findOldestSessions(sessions []*model.Session, count int) []*model.Session {
+func (s *SessionServiceImpl) findOldestSessions(sessions []*model.Session, count int) []*model.Session {
 	if len(sessions) <= count {
 		return sessions
 	}
@@ -202,14 +858,20 @@ func (s *SessionService) findOldestSessions(sessions []*model.Session, count int
 	return sorted[:count]
 }
 
-func (s *SessionService) ValidateAndExtend(ctx context.Context, sessionID string) (*model.Session, error) {
+func (s *SessionServiceImpl) ValidateAndExtend(ctx context.Context, sessionID string) (*model.Session, error) {
 	session, err := s.ValidateSession(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	timeLeft := time.Until(session.ExpiresAt)
-	if timeLeft < DefaultSessionDuration/2 {
+	// Sessions inside the expiry grace window are left to expire so the
+	// client refreshes its authentication instead.
+	if s.IsInGracePeriod(session) {
+		return session, nil
+	}
+
+	scopeCfg, _ := s.scopeConfigFor(session)
+	if shouldExtend(session, scopeCfg, s.lifetime(session, scopeCfg)) {
 		if err := s.ExtendSession(ctx, sessionID); err != nil {
 			s.logger.Warn("failed to auto-extend session", "sessionID", sessionID, "error", err)
 		}
@@ -217,3 +879,27 @@ func (s *SessionService) ValidateAndExtend(ctx context.Context, sessionID string
 
 	return session, nil
 }
+
+// shouldExtend applies the scope's extension granularity so that sessions
+// validated on every request are not rewritten on every request
+func shouldExtend(session *model.Session, scopeCfg ScopeConfig, lifetime time.Duration) bool {
+	threshold := scopeCfg.ExtendThreshold
+	if threshold <= 0 {
+		threshold = DefaultExtendThreshold
+	}
+
+	timeLeft := time.Until(session.ExpiresAt)
+	if timeLeft < time.Duration(float64(lifetime)*threshold) {
+		return true
+	}
+
+	// ExpiresAt was set to the full lifetime at the last extension
+	sinceExtended := lifetime - timeLeft
+	return scopeCfg.ExtendInterval > 0 && sinceExtended >= scopeCfg.ExtendInterval
+}
+
+// IsInGracePeriod reports whether a validated session is past its expiry but
+// still accepted because of the configured grace period.
+func (s *SessionServiceImpl) IsInGracePeriod(session *model.Session) bool {
+	return session.IsExpired(time.Now())
+}
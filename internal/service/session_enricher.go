@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// SessionEnricher adds deployment-specific metadata, such as a department
+// or tenant looked up in an external directory, to sessions as they are
+// created. Returned keys are merged into the session metadata; reserved
+// keys and nil values are ignored.
+type SessionEnricher interface {
+	EnrichSession(ctx context.Context, user *model.User, scope string) (map[string]interface{}, error)
+}
+
+// NoopSessionEnricher adds no metadata. It is the default enricher.
+type NoopSessionEnricher struct{}
+
+func (NoopSessionEnricher) EnrichSession(ctx context.Context, user *model.User, scope string) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// SetSessionEnricher installs the enricher CreateSession consults; nil
+// restores NoopSessionEnricher
+func (s *SessionServiceImpl) SetSessionEnricher(enricher SessionEnricher) {
+	if enricher == nil {
+		enricher = NoopSessionEnricher{}
+	}
+	s.enricher = enricher
+}
+
+// enrichMetadata merges the enricher's metadata into a new session's. A
+// failing directory must not lock users out, so errors and metadata over
+// the size limits are logged and the session is created without the extra
+// keys.
+func (s *SessionServiceImpl) enrichMetadata(ctx context.Context, user *model.User, session *model.Session) {
+	values, err := s.enricher.EnrichSession(ctx, user, session.Scope)
+	if err != nil {
+		s.logger.Warn("Session enrichment failed; creating session without it",
+			"user_id", user.UserID,
+			"scope", session.Scope,
+			"error", err,
+		)
+		return
+	}
+
+	if len(values) == 0 {
+		return
+	}
+
+	enriched := make(map[string]interface{}, len(session.Metadata)+len(values))
+	for key, value := range session.Metadata {
+		enriched[key] = value
+	}
+	for key, value := range values {
+		if reservedMetadataKeys[key] {
+			s.logger.Warn("Session enricher returned a reserved metadata key", "key", key)
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		enriched[key] = value
+	}
+
+	if err := s.validateMetadataSize(enriched); err != nil {
+		s.logger.Warn("Session enrichment exceeds metadata limits; creating session without it",
+			"user_id", user.UserID,
+			"scope", session.Scope,
+			"error", err,
+		)
+		return
+	}
+	session.Metadata = enriched
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/repository"
+	"github.com/histopathai/auth-service/internal/shared/lru"
+)
+
+// SessionLocator describes where sessions were created from, by resolving
+// the IP address stored in their metadata. Resolutions, including failed
+// ones, are cached so listing sessions does not repeat lookups.
+type SessionLocator struct {
+	resolver repository.GeoResolver
+	// cache holds resolved locations by IP address
+	cache  *lru.Cache[string, string]
+	logger *slog.Logger
+}
+
+func NewSessionLocator(resolver repository.GeoResolver, cacheTTL time.Duration, cacheSize int, logger *slog.Logger) *SessionLocator {
+	return &SessionLocator{
+		resolver: resolver,
+		cache:    lru.New[string, string](cacheTTL, max(cacheSize, 1)),
+		logger:   logger,
+	}
+}
+
+// SetSessionLocator makes sign-in emails include the approximate location
+// of the new session's IP address
+func (s *SessionServiceImpl) SetSessionLocator(locator *SessionLocator) {
+	s.loginNotifier.locator = locator
+}
+
+// Locate returns the approximate location of a session, e.g. "Istanbul, TR",
+// or an empty string when it has no IP address or the address is unknown
+func (l *SessionLocator) Locate(ctx context.Context, metadata map[string]interface{}) string {
+	ip, _ := metadata[MetadataKeyIPAddress].(string)
+	if ip == "" {
+		return ""
+	}
+
+	if location, ok := l.cache.Get(ip); ok {
+		return location
+	}
+
+	var location string
+	resolved, err := l.resolver.Resolve(ctx, ip)
+	if err != nil {
+		// Cached as unknown too, so a failing resolver is not retried for
+		// every session of every listing
+		l.logger.Warn("Failed to resolve session location", "error", err)
+	} else if resolved != nil {
+		location = resolved.String()
+	}
+
+	l.cache.Set(ip, location)
+	return location
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/domain/repository"
+	"github.com/histopathai/auth-service/internal/infrastructure/storage/memory"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// newTestSessionService returns a session service over the in-memory
+// repository. The repository's own per-user cap is set high so that the
+// service's eviction is what is under test.
+func newTestSessionService(t *testing.T, config SessionConfig) (*SessionServiceImpl, repository.SessionRepository) {
+	t.Helper()
+	repo := memory.NewInMemorySessionRepository(100, time.Hour, 0)
+	t.Cleanup(func() { repo.Close() })
+
+	return NewSessionService(repo, &fakeEmailSender{}, &fakePublisher{}, AuthService{}, config, discardLogger()), repo
+}
+
+func TestCreateSessionEvictsLeastRecentlyUsed(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{
+		ScopeConfigs: map[string]ScopeConfig{
+			ScopeDefault: {Expiration: time.Hour, MaxSessionsPerUser: 2},
+		},
+	})
+	ctx := context.Background()
+	user := &model.User{UserID: "user-1", Role: model.RoleUser}
+
+	first, err := sessions.CreateSession(ctx, user, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	first.LastUsedAt = time.Now().Add(-2 * time.Minute)
+	second, err := sessions.CreateSession(ctx, user, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	second.LastUsedAt = time.Now().Add(-time.Minute)
+
+	// Using the first session makes the second the least recently used
+	if _, err := sessions.ValidateSession(ctx, first.SessionID); err != nil {
+		t.Fatalf("ValidateSession() error = %v", err)
+	}
+	third, err := sessions.CreateSession(ctx, user, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if _, err := repo.Get(ctx, second.SessionID); !errors.IsType(err, errors.ErrorTypeNotFound) {
+		t.Errorf("least recently used session was not evicted, Get() error = %v", err)
+	}
+	for _, kept := range []*model.Session{first, third} {
+		if _, err := repo.Get(ctx, kept.SessionID); err != nil {
+			t.Errorf("session %s was evicted: %v", kept.SessionID, err)
+		}
+	}
+}
+
+func TestCreateSessionEvictsOnlyWithinScope(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{
+		ScopeConfigs: map[string]ScopeConfig{
+			ScopeDefault:    {Expiration: time.Hour, MaxSessionsPerUser: 1},
+			ScopeImageServe: {Expiration: time.Hour, MaxSessionsPerUser: 1},
+		},
+	})
+	ctx := context.Background()
+	user := &model.User{UserID: "user-1", Role: model.RoleUser}
+
+	defaultSession, err := sessions.CreateSession(ctx, user, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if _, err := sessions.CreateSession(ctx, user, CreateSessionOptions{Scope: ScopeImageServe}); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if _, err := repo.Get(ctx, defaultSession.SessionID); err != nil {
+		t.Errorf("session of another scope was evicted: %v", err)
+	}
+}
+
+func TestValidateSessionRemovesExpiredSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiredBy time.Duration
+		grace     time.Duration
+		wantValid bool
+	}{
+		{name: "expired", expiredBy: time.Minute, wantValid: false},
+		{name: "expired within the grace period", expiredBy: time.Minute, grace: 5 * time.Minute, wantValid: true},
+		{name: "expired past the grace period", expiredBy: 10 * time.Minute, grace: 5 * time.Minute, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, repo := newTestSessionService(t, SessionConfig{ExpiryGracePeriod: tt.grace})
+			ctx := context.Background()
+
+			session, err := sessions.CreateSession(ctx, &model.User{UserID: "user-1"}, CreateSessionOptions{})
+			if err != nil {
+				t.Fatalf("CreateSession() error = %v", err)
+			}
+			session.ExpiresAt = time.Now().Add(-tt.expiredBy)
+
+			_, err = sessions.ValidateSession(ctx, session.SessionID)
+			if tt.wantValid {
+				if err != nil {
+					t.Fatalf("ValidateSession() error = %v, want the session accepted", err)
+				}
+				if !sessions.IsInGracePeriod(session) {
+					t.Error("IsInGracePeriod() = false for an expired session that was accepted")
+				}
+				return
+			}
+
+			if !errors.IsType(err, errors.ErrorTypeNotFound) {
+				t.Fatalf("ValidateSession() error = %v, want NotFound", err)
+			}
+			if _, err := repo.Get(ctx, session.SessionID); !errors.IsType(err, errors.ErrorTypeNotFound) {
+				t.Errorf("expired session was not deleted, Get() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestExtendSessionPushesExpiryForward(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{})
+	ctx := context.Background()
+
+	session, err := sessions.CreateSession(ctx, &model.User{UserID: "user-1"}, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	session.ExpiresAt = time.Now().Add(time.Minute)
+
+	before := time.Now()
+	if err := sessions.ExtendSession(ctx, session.SessionID); err != nil {
+		t.Fatalf("ExtendSession() error = %v", err)
+	}
+
+	stored, err := repo.Get(ctx, session.SessionID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want := before.Add(DefaultSessionDuration); stored.ExpiresAt.Before(want) {
+		t.Errorf("ExpiresAt = %v, want at least %v", stored.ExpiresAt, want)
+	}
+}
+
+func TestExtendSessionRespectsMaxLifetime(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{MaxLifetime: time.Hour})
+	ctx := context.Background()
+
+	session, err := sessions.CreateSession(ctx, &model.User{UserID: "user-1"}, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	session.CreatedAt = time.Now().Add(-50 * time.Minute)
+	session.ExpiresAt = time.Now().Add(time.Minute)
+
+	if err := sessions.ExtendSession(ctx, session.SessionID); err != nil {
+		t.Fatalf("ExtendSession() error = %v", err)
+	}
+
+	stored, err := repo.Get(ctx, session.SessionID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if limit := session.CreatedAt.Add(time.Hour); !stored.ExpiresAt.Equal(limit) {
+		t.Errorf("ExpiresAt = %v, want the lifetime cap %v", stored.ExpiresAt, limit)
+	}
+}
+
+func TestRevokeAllUserSessionsClearsUser(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{})
+	ctx := context.Background()
+	user := &model.User{UserID: "user-1"}
+	other := &model.User{UserID: "user-2"}
+
+	for i := 0; i < MaxSessionsPerUser; i++ {
+		if _, err := sessions.CreateSession(ctx, user, CreateSessionOptions{}); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+	}
+	otherSession, err := sessions.CreateSession(ctx, other, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	result, err := sessions.RevokeAllUserSessions(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("RevokeAllUserSessions() error = %v", err)
+	}
+	if result.Revoked != MaxSessionsPerUser || len(result.Failed) != 0 {
+		t.Errorf("RevokeAllUserSessions() = %d revoked, %d failed, want %d revoked", result.Revoked, len(result.Failed), MaxSessionsPerUser)
+	}
+
+	if remaining, _ := repo.ListByUser(ctx, user.UserID); len(remaining) != 0 {
+		t.Errorf("ListByUser() = %d sessions after revoking all", len(remaining))
+	}
+	if stats := repo.GetStats(); stats["total_users"] != 1 {
+		t.Errorf("total_users = %v, want only the other user left", stats["total_users"])
+	}
+	if _, err := repo.Get(ctx, otherSession.SessionID); err != nil {
+		t.Errorf("another user's session was revoked: %v", err)
+	}
+}
+
+// slowSessionRepo counts session reads and delays their results
+type slowSessionRepo struct {
+	repository.SessionRepository
+	reads   atomic.Int64
+	latency time.Duration
+}
+
+func (r *slowSessionRepo) Get(ctx context.Context, sessionID string) (*model.Session, error) {
+	r.reads.Add(1)
+	session, err := r.SessionRepository.Get(ctx, sessionID)
+	time.Sleep(r.latency)
+	return session, err
+}
+
+func TestValidateSessionSharesConcurrentReads(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{})
+	slow := &slowSessionRepo{SessionRepository: repo, latency: 20 * time.Millisecond}
+	sessions.sessionRepo = slow
+	ctx := context.Background()
+
+	session, err := sessions.CreateSession(ctx, &model.User{UserID: "user-1"}, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sessions.ValidateSession(ctx, session.SessionID); err != nil {
+				t.Errorf("ValidateSession() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reads := slow.reads.Load(); reads >= 20 {
+		t.Errorf("store reads = %d for 20 concurrent validations, want them shared", reads)
+	}
+}
+
+func TestValidateSessionAfterPurgeDoesNotJoinEarlierRead(t *testing.T) {
+	sessions, repo := newTestSessionService(t, SessionConfig{})
+	sessions.sessionRepo = &slowSessionRepo{SessionRepository: repo, latency: 50 * time.Millisecond}
+	ctx := context.Background()
+
+	session, err := sessions.CreateSession(ctx, &model.User{UserID: "user-1"}, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	// Start a read that finds the session, then purge it while the read is in flight
+	go func() { _, _ = sessions.ValidateSession(ctx, session.SessionID) }()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := sessions.PurgeUserSessions(ctx, session.UserID); err != nil {
+		t.Fatalf("PurgeUserSessions() error = %v", err)
+	}
+
+	if _, err := sessions.ValidateSession(ctx, session.SessionID); !errors.IsType(err, errors.ErrorTypeNotFound) {
+		t.Errorf("ValidateSession() error = %v after the purge, want NotFound", err)
+	}
+}
+
+// BenchmarkValidateSessionConcurrent measures validations of one session by
+// many goroutines, as for a burst of tile requests carrying one cookie. The
+// reads/op metric shows how many store reads are shared.
+func BenchmarkValidateSessionConcurrent(b *testing.B) {
+	repo := memory.NewInMemorySessionRepository(100, time.Hour, 0)
+	defer repo.Close()
+	slow := &slowSessionRepo{SessionRepository: repo, latency: time.Millisecond}
+	sessions := NewSessionService(slow, &fakeEmailSender{}, &fakePublisher{}, AuthService{}, SessionConfig{}, discardLogger())
+
+	session, err := sessions.CreateSession(context.Background(), &model.User{UserID: "user-1"}, CreateSessionOptions{})
+	if err != nil {
+		b.Fatalf("CreateSession() error = %v", err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sessions.ValidateSession(context.Background(), session.SessionID); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+	b.ReportMetric(float64(slow.reads.Load())/float64(b.N), "reads/op")
+}
+
+func TestCreateSessionEnforcesScopeRoles(t *testing.T) {
+	scopes := DefaultScopeConfigs()
+	imageServe := scopes[ScopeImageServe]
+	imageServe.AllowedRoles = []model.UserRole{model.RoleUser, model.RoleAdmin}
+	scopes[ScopeImageServe] = imageServe
+
+	tests := []struct {
+		scope   string
+		role    model.UserRole
+		allowed bool
+	}{
+		{ScopeDefault, model.RoleViewer, true},
+		{ScopeImageServe, model.RoleUser, true},
+		{ScopeImageServe, model.RoleViewer, false},
+		{ScopeAdminOps, model.RoleAdmin, true},
+		{ScopeAdminOps, model.RoleUser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope+"/"+string(tt.role), func(t *testing.T) {
+			sessions, _ := newTestSessionService(t, SessionConfig{ScopeConfigs: scopes})
+
+			_, err := sessions.CreateSession(context.Background(), &model.User{UserID: "user-1", Role: tt.role}, CreateSessionOptions{Scope: tt.scope})
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("CreateSession() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.IsType(err, errors.ErrorTypeForbidden) {
+				t.Fatalf("CreateSession() error = %v, want Forbidden", err)
+			}
+			details := err.(*errors.Err).Details
+			if details["scope"] != tt.scope {
+				t.Errorf("details scope = %v, want %s", details["scope"], tt.scope)
+			}
+			if _, ok := details["required_roles"].([]model.UserRole); !ok {
+				t.Errorf("details required_roles = %v, want the scope's roles", details["required_roles"])
+			}
+		})
+	}
+}
+
+// TestConcurrentSessionUpdates is meant for -race: validation, extension
+// and metadata updates of one session must not write the repository's copy
+func TestConcurrentSessionUpdates(t *testing.T) {
+	sessions, _ := newTestSessionService(t, SessionConfig{})
+	ctx := context.Background()
+	user := &model.User{UserID: "user-1"}
+	session, err := sessions.CreateSession(ctx, user, CreateSessionOptions{})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	operations := []func(i int) error{
+		func(i int) error { _, err := sessions.ValidateSession(ctx, session.SessionID); return err },
+		func(i int) error { return sessions.ExtendSession(ctx, session.SessionID) },
+		func(i int) error {
+			_, err := sessions.MergeSessionMetadata(ctx, session.SessionID, user.UserID, map[string]interface{}{"slide": i})
+			return err
+		},
+	}
+
+	var wg sync.WaitGroup
+	for _, operation := range operations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if err := operation(i); err != nil {
+					t.Errorf("operation error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/lru"
+)
+
+// userCache is a size-bounded LRU cache of user profiles with a fixed TTL.
+// It is shared by copies of AuthService, so it is always used by pointer.
+type userCache struct {
+	users *lru.Cache[string, model.User]
+
+	mu sync.Mutex
+	// epoch counts invalidations. A read that started before one may have
+	// returned the old profile, so it is not cached.
+	epoch uint64
+}
+
+func newUserCache(ttl time.Duration, maxSize int) *userCache {
+	return &userCache{users: lru.New[string, model.User](ttl, maxSize)}
+}
+
+// get returns a copy of the cached user so callers cannot mutate the cache
+func (c *userCache) get(userID string) (*model.User, bool) {
+	user, ok := c.users.Get(userID)
+	if !ok {
+		return nil, false
+	}
+	return &user, true
+}
+
+// currentEpoch is taken before reading a user so the result can be cached
+// with setIfCurrent
+func (c *userCache) currentEpoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch
+}
+
+// setIfCurrent caches user unless an invalidation happened since epoch
+func (c *userCache) setIfCurrent(user *model.User, epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.epoch != epoch {
+		return
+	}
+	c.users.Set(user.UserID, *user)
+}
+
+func (c *userCache) invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.users.Delete(userID)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+// gatedUserRepo reads users from the embedded repository and then waits for
+// release, so a test can change a user while a read of the old profile is in
+// flight. Reads are counted and optionally delayed.
+type gatedUserRepo struct {
+	*fakeUserRepo
+	reads   atomic.Int64
+	latency time.Duration
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (r *gatedUserRepo) GetByUserID(ctx context.Context, userID string) (*model.User, error) {
+	r.reads.Add(1)
+	user, err := r.fakeUserRepo.GetByUserID(ctx, userID)
+	if r.read != nil {
+		r.read <- struct{}{}
+		<-r.release
+	}
+	time.Sleep(r.latency)
+	return user, err
+}
+
+func TestGetUserByUserIDDoesNotCacheReadRacingInvalidation(t *testing.T) {
+	user := &model.User{UserID: "user-1", Status: model.StatusActive, Role: model.RoleUser}
+	ts := newTestAuthService(AuthConfig{UserCacheTTL: time.Minute, UserCacheSize: 10}, user)
+	repo := &gatedUserRepo{fakeUserRepo: ts.userRepo, read: make(chan struct{}), release: make(chan struct{})}
+	ts.AuthService.userRepo = repo
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = ts.GetUserByUserID(ctx, user.UserID)
+	}()
+
+	// The lookup has read the active profile; suspend the user before it returns
+	<-repo.read
+	suspended := model.StatusSuspended
+	_ = ts.userRepo.Update(ctx, user.UserID, &model.UpdateUser{Status: &suspended})
+	ts.InvalidateUser(user.UserID)
+	close(repo.release)
+	<-done
+
+	repo.read = nil
+	got, err := ts.GetUserByUserID(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("GetUserByUserID() error = %v", err)
+	}
+	if got.Status != model.StatusSuspended {
+		t.Errorf("GetUserByUserID() status = %s, want the suspension to be visible", got.Status)
+	}
+}
+
+func TestGetUserByUserIDSharesConcurrentReads(t *testing.T) {
+	user := &model.User{UserID: "user-1", Status: model.StatusActive, Role: model.RoleUser}
+	ts := newTestAuthService(AuthConfig{}, user)
+	repo := &gatedUserRepo{fakeUserRepo: ts.userRepo, latency: 20 * time.Millisecond}
+	ts.AuthService.userRepo = repo
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ts.GetUserByUserID(context.Background(), user.UserID); err != nil {
+				t.Errorf("GetUserByUserID() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reads := repo.reads.Load(); reads >= 20 {
+		t.Errorf("repository reads = %d for 20 concurrent lookups, want them shared", reads)
+	}
+}
+
+// BenchmarkGetUserByUserIDConcurrent measures lookups of one user by many
+// goroutines with the cache disabled, as for a burst of tile requests. The
+// reads/op metric shows how many repository reads singleflight saves.
+func BenchmarkGetUserByUserIDConcurrent(b *testing.B) {
+	user := &model.User{UserID: "user-1", Status: model.StatusActive, Role: model.RoleUser}
+	ts := newTestAuthService(AuthConfig{}, user)
+	repo := &gatedUserRepo{fakeUserRepo: ts.userRepo, latency: time.Millisecond}
+	ts.AuthService.userRepo = repo
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := ts.GetUserByUserID(context.Background(), user.UserID); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+	b.ReportMetric(float64(repo.reads.Load())/float64(b.N), "reads/op")
+}
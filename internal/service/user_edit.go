@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// EditUser applies an admin's changes to a user and returns the updated
+// user. A non-zero updatedAt makes the edit conditional on the user not
+// having changed since it was read, so concurrent edits by two admins fail
+// with a conflict instead of overwriting each other. Admin is granted only
+// through PromoteUserToAdmin.
+func (s *AuthService) EditUser(ctx context.Context, userID string, edit *model.UserEdit, updatedAt time.Time, adminID string) (*model.User, error) {
+	updates := &model.UpdateUser{}
+	details := map[string]interface{}{}
+
+	if edit.DisplayName != nil {
+		displayName := strings.TrimSpace(*edit.DisplayName)
+		if displayName == "" {
+			return nil, errors.NewValidationError("display name must not be empty", nil)
+		}
+		updates.DisplayName = &displayName
+		details["displayName"] = displayName
+	}
+	if edit.Role != nil {
+		if !edit.Role.IsValid() {
+			return nil, errors.NewValidationError("role must be one of: admin, user, viewer, unassigned", nil)
+		}
+		if *edit.Role == model.RoleAdmin {
+			return nil, errors.NewValidationError("admin role is granted through promotion", nil)
+		}
+		updates.Role = edit.Role
+		details["role"] = *edit.Role
+	}
+	if updates.DisplayName == nil && updates.Role == nil {
+		return nil, errors.NewValidationError("no fields to update", nil)
+	}
+
+	previous, err := s.userRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedAt.IsZero() {
+		err = s.userRepo.Update(ctx, userID, updates)
+	} else {
+		err = s.userRepo.UpdateIfUnmodified(ctx, userID, updates, updatedAt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateUser(ctx, userID)
+
+	if updates.Role != nil && *updates.Role != previous.Role {
+		if err := s.syncClaims(ctx, userID, *updates.Role, previous.Status); err != nil {
+			s.logger.Warn("Failed to sync custom claims; rolling back user edit",
+				"user_id", userID,
+				"role", *updates.Role,
+				"error", err,
+			)
+			if rollbackErr := s.updateUser(ctx, userID, &model.UpdateUser{Role: &previous.Role}); rollbackErr != nil {
+				s.logger.Error("User profile and custom claims are out of sync", "user_id", userID, "error", rollbackErr)
+				return nil, errors.NewOutOfSyncError("user was updated but token claims could not be synced; retry the operation", map[string]interface{}{
+					"userID": userID,
+				}, err)
+			}
+			return nil, errors.NewInternalError("failed to sync user claims; the change was rolled back", err)
+		}
+		s.notifyRoleChange(ctx, previous, *updates.Role)
+	}
+
+	s.logger.Info("User edited by admin", "user_id", userID, "admin_id", adminID, "changes", details)
+
+	return s.GetUserByUserID(ctx, userID)
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+)
+
+// MaxUserImportRows caps the rows accepted in a single import
+const MaxUserImportRows = 1000
+
+// ImportUsers creates a Firebase user and profile for each row, for
+// migrating users from another system. Invalid rows fail and rows whose
+// email is already registered are skipped; neither stops the import. A dry
+// run validates the rows and checks for existing emails without creating
+// anything. Imported users set their password through a password reset.
+func (s *AuthService) ImportUsers(ctx context.Context, rows []*model.UserImportRow, dryRun bool, adminID string) ([]*model.UserImportResult, error) {
+	if len(rows) == 0 {
+		return nil, errors.NewValidationError("import contains no rows", nil)
+	}
+	if len(rows) > MaxUserImportRows {
+		return nil, errors.NewValidationError("import contains too many rows", map[string]interface{}{
+			"rows":    len(rows),
+			"maxRows": MaxUserImportRows,
+		})
+	}
+
+	results := make([]*model.UserImportResult, len(rows))
+	pending := make([]int, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+	for i, row := range rows {
+		results[i] = &model.UserImportResult{Line: row.Line, Email: row.Email}
+
+		if reason := s.validateImportRow(row); reason != "" {
+			results[i].Outcome = model.UserImportFailed
+			results[i].Error = reason
+			continue
+		}
+
+		email := strings.ToLower(row.Email)
+		if line, ok := seen[email]; ok {
+			results[i].Outcome = model.UserImportSkipped
+			results[i].Error = "email appears earlier in the import on line " + strconv.Itoa(line)
+			continue
+		}
+		seen[email] = row.Line
+		pending = append(pending, i)
+	}
+
+	runBulk(ctx, pending, s.config.BulkWriteConcurrency, func(ctx context.Context, i int) error {
+		s.importUser(ctx, rows[i], results[i], dryRun, adminID)
+		return nil
+	})
+
+	counts := make(map[model.UserImportOutcome]int)
+	for _, result := range results {
+		counts[result.Outcome]++
+	}
+	s.logger.Info("User import finished",
+		"admin_id", adminID,
+		"dry_run", dryRun,
+		"rows", len(rows),
+		"created", counts[model.UserImportCreated],
+		"valid", counts[model.UserImportValid],
+		"skipped", counts[model.UserImportSkipped],
+		"failed", counts[model.UserImportFailed],
+		"concurrency", s.config.BulkWriteConcurrency,
+	)
+
+	return results, nil
+}
+
+// validateImportRow returns why row cannot be imported, or "" if it can.
+// Admins are only made through PromoteUserToAdmin, which a second admin
+// confirms, and active rows are approvals, so their role must be one an
+// approval may assign.
+func (s *AuthService) validateImportRow(row *model.UserImportRow) string {
+	if row.Email == "" {
+		return "email is required"
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return "email is not a valid email address"
+	}
+	if !row.Role.IsValid() {
+		return "role must be one of: admin, user, viewer, unassigned"
+	}
+	if row.Role == model.RoleAdmin {
+		return "admin role cannot be imported; promote the user after import"
+	}
+	if !row.Status.IsValid() {
+		return "status must be one of: pending, active, suspended, rejected"
+	}
+	if row.Status == model.StatusActive && !slices.Contains(s.config.ApprovalRoles, row.Role) {
+		roles := make([]string, len(s.config.ApprovalRoles))
+		for i, role := range s.config.ApprovalRoles {
+			roles[i] = string(role)
+		}
+		return "active users must have a role an approval may assign: " + strings.Join(roles, ", ")
+	}
+	return ""
+}
+
+// importUser creates the user for a validated row and records the outcome
+// in result. A profile or claims failure rolls back the Firebase user so the
+// row can be retried.
+func (s *AuthService) importUser(ctx context.Context, row *model.UserImportRow, result *model.UserImportResult, dryRun bool, adminID string) {
+	registered, err := s.emailRegistered(ctx, row.Email)
+	if err != nil {
+		s.failImport(result, "failed to check for an existing user", err)
+		return
+	}
+	if registered {
+		result.Outcome = model.UserImportSkipped
+		result.Error = "email is already registered"
+		return
+	}
+
+	if dryRun {
+		result.Outcome = model.UserImportValid
+		return
+	}
+
+	userID, err := s.authRepo.CreateUser(ctx, row.Email, row.DisplayName)
+	if err != nil {
+		if errors.IsType(err, errors.ErrorTypeConflict) {
+			result.Outcome = model.UserImportSkipped
+			result.Error = "email is already registered"
+			return
+		}
+		s.failImport(result, "failed to create user", err)
+		return
+	}
+
+	now := time.Now()
+	user := &model.User{
+		UserID:      userID,
+		Email:       row.Email,
+		DisplayName: row.DisplayName,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Status:      row.Status,
+		Role:        row.Role,
+	}
+	if row.Status == model.StatusActive {
+		user.AdminApproved = true
+		user.ApprovalDate = now
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		s.rollbackImport(ctx, userID, false)
+		s.failImport(result, "failed to create user profile", err)
+		return
+	}
+
+	if err := s.syncClaims(ctx, userID, row.Role, row.Status); err != nil {
+		s.rollbackImport(ctx, userID, true)
+		s.failImport(result, "failed to set user claims", err)
+		return
+	}
+
+	s.recordAudit(ctx, &model.AuditEntry{
+		Action:       model.AuditActionUserImported,
+		ActorID:      adminID,
+		TargetUserID: userID,
+		Details: map[string]interface{}{
+			"email":  row.Email,
+			"role":   row.Role,
+			"status": row.Status,
+		},
+	})
+
+	result.Outcome = model.UserImportCreated
+	result.UserID = userID
+}
+
+// rollbackImport deletes what importUser created for a failed row. Failures
+// are logged as errors: they leave an orphaned Firebase user or profile
+// that blocks re-importing the email and must be removed by hand.
+func (s *AuthService) rollbackImport(ctx context.Context, userID string, deleteProfile bool) {
+	if deleteProfile {
+		if err := s.userRepo.Delete(ctx, userID); err != nil {
+			s.logger.Error("Failed to roll back imported user profile; profile is orphaned", "user_id", userID, "error", err)
+		}
+	}
+	if err := s.authRepo.Delete(ctx, userID); err != nil {
+		s.logger.Error("Failed to roll back imported Firebase user; account is orphaned", "user_id", userID, "error", err)
+	}
+}
+
+// emailRegistered reports whether a profile or a Firebase user already has
+// the email
+func (s *AuthService) emailRegistered(ctx context.Context, email string) (bool, error) {
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return true, nil
+	}
+	return s.authRepo.EmailExists(ctx, email)
+}
+
+// failImport marks result as failed. Internal causes are logged rather than
+// returned to the caller.
+func (s *AuthService) failImport(result *model.UserImportResult, reason string, err error) {
+	result.Outcome = model.UserImportFailed
+	result.Error = reason
+
+	var customErr *errors.Err
+	if stderrors.As(err, &customErr) && customErr.Type != errors.ErrorTypeInternal {
+		result.Error = reason + ": " + customErr.Message
+	}
+	s.logger.Error("Failed to import user", "line", result.Line, "email", result.Email, "reason", reason, "error", err)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+)
+
+func TestImportUsersValidatesRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		row         model.UserImportRow
+		wantOutcome model.UserImportOutcome
+	}{
+		{"active admin is rejected", model.UserImportRow{Email: "a@example.com", Role: model.RoleAdmin, Status: model.StatusActive}, model.UserImportFailed},
+		{"pending admin is rejected", model.UserImportRow{Email: "b@example.com", Role: model.RoleAdmin, Status: model.StatusPending}, model.UserImportFailed},
+		{"active role outside the approval allowlist is rejected", model.UserImportRow{Email: "c@example.com", Role: model.RoleUnassigned, Status: model.StatusActive}, model.UserImportFailed},
+		{"active user is created", model.UserImportRow{Email: "d@example.com", Role: model.RoleUser, Status: model.StatusActive}, model.UserImportCreated},
+		{"active viewer is created", model.UserImportRow{Email: "e@example.com", Role: model.RoleViewer, Status: model.StatusActive}, model.UserImportCreated},
+		{"pending unassigned is created", model.UserImportRow{Email: "f@example.com", Role: model.RoleUnassigned, Status: model.StatusPending}, model.UserImportCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestAuthService(AuthConfig{})
+			row := tt.row
+			row.Line = 2
+
+			results, err := ts.ImportUsers(context.Background(), []*model.UserImportRow{&row}, false, "admin-1")
+			if err != nil {
+				t.Fatalf("ImportUsers() error = %v", err)
+			}
+			if got := results[0].Outcome; got != tt.wantOutcome {
+				t.Fatalf("outcome = %s (%s), want %s", got, results[0].Error, tt.wantOutcome)
+			}
+			if hasAdmin, _ := ts.userRepo.HasAdmin(context.Background()); hasAdmin {
+				t.Fatal("import created an admin")
+			}
+		})
+	}
+}
+
+func TestImportUsersRollsBackOnClaimsFailure(t *testing.T) {
+	ts := newTestAuthService(AuthConfig{})
+	ts.authRepo.claimsErr = stderrors.New("claims unavailable")
+
+	row := &model.UserImportRow{Line: 2, Email: "a@example.com", Role: model.RoleUser, Status: model.StatusActive}
+	results, err := ts.ImportUsers(context.Background(), []*model.UserImportRow{row}, false, "admin-1")
+	if err != nil {
+		t.Fatalf("ImportUsers() error = %v", err)
+	}
+	if results[0].Outcome != model.UserImportFailed {
+		t.Fatalf("outcome = %s, want %s", results[0].Outcome, model.UserImportFailed)
+	}
+	if len(ts.authRepo.deleted) != 1 {
+		t.Errorf("Firebase users deleted = %v, want the created user", ts.authRepo.deleted)
+	}
+	if existing, _ := ts.userRepo.GetByEmail(context.Background(), row.Email); existing != nil {
+		t.Error("profile of the failed row was not rolled back")
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// newUserWindow is the period counted as new registrations in user stats
+const newUserWindow = 7 * 24 * time.Hour
+
+var (
+	statsStatuses = []model.UserStatus{model.StatusPending, model.StatusActive, model.StatusSuspended, model.StatusRejected}
+	statsRoles    = []model.UserRole{model.RoleAdmin, model.RoleUser, model.RoleViewer, model.RoleUnassigned}
+)
+
+// userStatsCache holds the last computed user stats. Like userCache it is
+// shared by copies of AuthService, so it is always used by pointer.
+type userStatsCache struct {
+	mu        sync.Mutex
+	stats     *model.UserStats
+	expiresAt time.Time
+}
+
+// GetUserStats returns aggregate user counts. Results are cached for
+// UserStatsCacheTTL since the dashboard polls them frequently.
+func (s *AuthService) GetUserStats(ctx context.Context) (*model.UserStats, error) {
+	cache := s.userStatsCache
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+	if cache.stats != nil && now.Before(cache.expiresAt) {
+		return cache.stats, nil
+	}
+
+	stats, err := s.countUsers(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.stats = stats
+	cache.expiresAt = now.Add(s.config.UserStatsCacheTTL)
+	return stats, nil
+}
+
+// countUsers runs the count aggregations concurrently
+func (s *AuthService) countUsers(ctx context.Context, now time.Time) (*model.UserStats, error) {
+	var (
+		total       int64
+		newThisWeek int64
+		byStatus    = make([]int64, len(statsStatuses))
+		byRole      = make([]int64, len(statsRoles))
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	count := func(dst *int64, filter model.UserCountFilter) {
+		g.Go(func() error {
+			n, err := s.userRepo.Count(gctx, filter)
+			if err != nil {
+				return err
+			}
+			*dst = n
+			return nil
+		})
+	}
+
+	count(&total, model.UserCountFilter{})
+	count(&newThisWeek, model.UserCountFilter{CreatedAfter: now.Add(-newUserWindow)})
+	for i, status := range statsStatuses {
+		count(&byStatus[i], model.UserCountFilter{Status: status})
+	}
+	for i, role := range statsRoles {
+		count(&byRole[i], model.UserCountFilter{Role: role})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, errors.NewInternalError("failed to count users", err)
+	}
+
+	stats := &model.UserStats{
+		Total:       total,
+		ByStatus:    make(map[model.UserStatus]int64, len(statsStatuses)),
+		ByRole:      make(map[model.UserRole]int64, len(statsRoles)),
+		NewThisWeek: newThisWeek,
+		GeneratedAt: now,
+	}
+	for i, status := range statsStatuses {
+		stats.ByStatus[status] = byStatus[i]
+	}
+	for i, role := range statsRoles {
+		stats.ByRole[role] = byRole[i]
+	}
+
+	return stats, nil
+}
@@ -10,4 +10,7 @@ const (
 	FieldUserRole          = "Role"
 	FieldUserAdminApproved = "AdminApproved"
 	FieldUserApprovalDate  = "ApprovalDate"
+
+	FieldUserPendingPromotionBy = "PendingPromotionBy"
+	FieldUserPendingPromotionAt = "PendingPromotionAt"
 )
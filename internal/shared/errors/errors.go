@@ -1,7 +1,9 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"time"
 )
 
 type ErrorType string
@@ -13,6 +15,26 @@ const (
 	ErrorTypeInternal     ErrorType = "INTERNAL_ERROR"
 	ErrorTypeConflict     ErrorType = "CONFLICT_ERROR"
 	ErrorTypeForbidden    ErrorType = "FORBIDDEN_ERROR"
+	// ErrorTypeOutOfSync means a change was applied to one backing system but
+	// not another; retrying the operation brings them back in line
+	ErrorTypeOutOfSync ErrorType = "OUT_OF_SYNC_ERROR"
+	// ErrorTypeTimeout means the request deadline passed before a backing
+	// service answered
+	ErrorTypeTimeout ErrorType = "TIMEOUT_ERROR"
+	// ErrorTypeRateLimited means a backing service refused the call for
+	// quota reasons; clients should retry after the advertised delay
+	ErrorTypeRateLimited ErrorType = "RATE_LIMITED_ERROR"
+	// Account status errors tell a signed-in user why their account cannot
+	// be used, so clients can show a specific message
+	ErrorTypeAccountPendingApproval ErrorType = "ACCOUNT_PENDING_APPROVAL"
+	ErrorTypeAccountSuspended       ErrorType = "ACCOUNT_SUSPENDED"
+	ErrorTypeAccountRejected        ErrorType = "ACCOUNT_REJECTED"
+	// ErrorTypeUserProfileNotFound means the token is valid but registration
+	// was never completed, so there is no profile to sign in to
+	ErrorTypeUserProfileNotFound ErrorType = "USER_PROFILE_NOT_FOUND"
+	// ErrorTypeEmailNotVerified means the grace period for verifying a new
+	// account's email has passed
+	ErrorTypeEmailNotVerified ErrorType = "EMAIL_NOT_VERIFIED"
 )
 
 type Err struct {
@@ -33,6 +55,12 @@ func (e *Err) Unwrap() error {
 	return e.Err
 }
 
+// IsType reports whether err is an *Err of the given type
+func IsType(err error, errType ErrorType) bool {
+	var customErr *Err
+	return stderrors.As(err, &customErr) && customErr.Type == errType
+}
+
 // Helper fonksiyonlar
 func NewValidationError(message string, details map[string]interface{}) *Err {
 	return &Err{
@@ -78,3 +106,57 @@ func NewForbiddenError(message string) *Err {
 		Message: message,
 	}
 }
+
+func NewTimeoutError(message string, err error) *Err {
+	return &Err{
+		Type:    ErrorTypeTimeout,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// NewRateLimitedError reports a refused call; retryAfter is sent to the
+// client as Retry-After through the retry_after_seconds detail
+func NewRateLimitedError(message string, retryAfter time.Duration, err error) *Err {
+	return &Err{
+		Type:    ErrorTypeRateLimited,
+		Message: message,
+		Details: map[string]interface{}{
+			"retry_after_seconds": int(retryAfter.Seconds()),
+		},
+		Err: err,
+	}
+}
+
+func NewOutOfSyncError(message string, details map[string]interface{}, err error) *Err {
+	return &Err{
+		Type:    ErrorTypeOutOfSync,
+		Message: message,
+		Details: details,
+		Err:     err,
+	}
+}
+
+func NewAccountStatusError(errType ErrorType, message string, details map[string]interface{}) *Err {
+	return &Err{
+		Type:    errType,
+		Message: message,
+		Details: details,
+	}
+}
+
+func NewUserProfileNotFoundError(message string, err error) *Err {
+	return &Err{
+		Type:    ErrorTypeUserProfileNotFound,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func NewEmailNotVerifiedError(message string, details map[string]interface{}) *Err {
+	return &Err{
+		Type:    ErrorTypeEmailNotVerified,
+		Message: message,
+		Details: details,
+	}
+}
@@ -0,0 +1,26 @@
+// Package jsonutil decodes JSON of unknown shape without losing precision
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var errTrailingData = errors.New("invalid character after top-level value")
+
+// Unmarshal is json.Unmarshal except that numbers decoded into interface
+// values become json.Number instead of float64, so integers beyond 2^53,
+// such as large counters or nanosecond timestamps, re-encode unchanged
+func Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errTrailingData
+	}
+	return nil
+}
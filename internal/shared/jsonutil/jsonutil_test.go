@@ -0,0 +1,40 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUnmarshalKeepsLargeIntegers(t *testing.T) {
+	// 2^53 + 1 is the smallest integer a float64 cannot hold. Keys are in
+	// the order json.Marshal writes them.
+	const body = `{"metadata":{"last_seen_ns":1760522400123456789},"ratio":0.5,"request_count":9007199254740993}`
+
+	var decoded map[string]interface{}
+	if err := Unmarshal([]byte(body), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	count, ok := decoded["request_count"].(json.Number)
+	if !ok {
+		t.Fatalf("request_count decoded as %T, want json.Number", decoded["request_count"])
+	}
+	if n, err := count.Int64(); err != nil || n != 9007199254740993 {
+		t.Errorf("request_count = %v, %v, want 9007199254740993", n, err)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(encoded) != body {
+		t.Errorf("round trip = %s, want %s", encoded, body)
+	}
+}
+
+func TestUnmarshalRejectsTrailingData(t *testing.T) {
+	var decoded interface{}
+	if err := Unmarshal([]byte(`{"a":1} {"b":2}`), &decoded); err == nil {
+		t.Error("Unmarshal() accepted two top-level values")
+	}
+}
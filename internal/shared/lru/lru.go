@@ -0,0 +1,184 @@
+// Package lru provides a size-bounded least recently used cache whose
+// entries expire after a TTL
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is safe for concurrent use. Entries expire ttl after they were set,
+// or, for caches created with NewSliding, after they were last used.
+type Cache[K comparable, V any] struct {
+	ttl     time.Duration
+	maxSize int
+	sliding bool
+	// onRemove is called with mu held for every entry leaving the cache
+	onRemove func(key K, value V)
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	// order holds entries from most to least recently used
+	order *list.List
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// New returns a cache whose entries expire ttl after they were set. Once
+// more than maxSize entries are cached the least recently used is evicted;
+// a maxSize of zero leaves the cache unbounded.
+func New[K comparable, V any](ttl time.Duration, maxSize int) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// NewSliding returns a cache whose entries expire once they have not been
+// used for ttl. Least recently used is then also soonest to expire, so
+// RemoveExpired stops at the first live entry.
+func NewSliding[K comparable, V any](ttl time.Duration, maxSize int) *Cache[K, V] {
+	c := New[K, V](ttl, maxSize)
+	c.sliding = true
+	return c
+}
+
+// OnRemove registers fn to be called for every entry that is evicted,
+// expires or is deleted. It must be set before the cache is used and must
+// not call back into the cache.
+func (c *Cache[K, V]) OnRemove(fn func(key K, value V)) {
+	c.onRemove = fn
+}
+
+// Get returns the value cached for key and marks it as recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok || c.expireLocked(element, time.Now()) {
+		var zero V
+		return zero, false
+	}
+
+	c.touchLocked(element)
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// GetOrAdd returns the value cached for key, or caches and returns the
+// result of create if there is none. The bool reports whether it was cached.
+func (c *Cache[K, V]) GetOrAdd(key K, create func() V) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok && !c.expireLocked(element, time.Now()) {
+		c.touchLocked(element)
+		return element.Value.(*entry[K, V]).value, true
+	}
+
+	value := create()
+	c.setLocked(key, value)
+	return value, false
+}
+
+// Set caches value for key, replacing any previous value
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, value)
+}
+
+// Delete removes key from the cache
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.removeLocked(element)
+	}
+}
+
+// RemoveExpired removes every expired entry and returns how many there were.
+// Expired entries are otherwise only removed when looked up or evicted.
+func (c *Cache[K, V]) RemoveExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for element := c.order.Back(); element != nil; {
+		previous := element.Prev()
+		if c.expireLocked(element, now) {
+			removed++
+		} else if c.sliding {
+			break
+		}
+		element = previous
+	}
+	return removed
+}
+
+// Len returns the number of cached entries, including expired ones not yet
+// removed
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V) {
+	if element, ok := c.entries[key]; ok {
+		c.removeLocked(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{
+		key:       key,
+		value:     value,
+		expiresAt: c.expiry(time.Now()),
+	})
+
+	for c.maxSize > 0 && c.order.Len() > c.maxSize {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *Cache[K, V]) touchLocked(element *list.Element) {
+	c.order.MoveToFront(element)
+	if c.sliding {
+		element.Value.(*entry[K, V]).expiresAt = c.expiry(time.Now())
+	}
+}
+
+// expireLocked removes element if it has expired and reports whether it did
+func (c *Cache[K, V]) expireLocked(element *list.Element, now time.Time) bool {
+	expiresAt := element.Value.(*entry[K, V]).expiresAt
+	if expiresAt.IsZero() || !now.After(expiresAt) {
+		return false
+	}
+	c.removeLocked(element)
+	return true
+}
+
+func (c *Cache[K, V]) removeLocked(element *list.Element) {
+	e := element.Value.(*entry[K, V])
+	c.order.Remove(element)
+	delete(c.entries, e.key)
+	if c.onRemove != nil {
+		c.onRemove(e.key, e.value)
+	}
+}
+
+// expiry returns when an entry used at now expires; zero never expires
+func (c *Cache[K, V]) expiry(now time.Time) time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(c.ttl)
+}
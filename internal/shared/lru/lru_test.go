@@ -0,0 +1,91 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := New[string, int](time.Hour, 2)
+	var removed []string
+	cache.OnRemove(func(key string, value int) { removed = append(removed, key) })
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("entry %q was evicted", key)
+		}
+	}
+	if len(removed) != 1 || removed[0] != "b" {
+		t.Errorf("OnRemove() saw %v, want [b]", removed)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		cache     *Cache[string, int]
+		wantAlive bool
+	}{
+		// Both entries are read halfway through their TTL and checked after it
+		{name: "fixed TTL expires after set", cache: New[string, int](40*time.Millisecond, 0), wantAlive: false},
+		{name: "sliding TTL is extended by use", cache: NewSliding[string, int](40*time.Millisecond, 0), wantAlive: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cache.Set("a", 1)
+			time.Sleep(25 * time.Millisecond)
+			if _, ok := tt.cache.Get("a"); !ok {
+				t.Fatal("entry expired before its TTL")
+			}
+			time.Sleep(25 * time.Millisecond)
+
+			if _, ok := tt.cache.Get("a"); ok != tt.wantAlive {
+				t.Errorf("Get() found = %v, want %v", ok, tt.wantAlive)
+			}
+		})
+	}
+}
+
+func TestCacheRemoveExpired(t *testing.T) {
+	cache := NewSliding[string, int](20*time.Millisecond, 0)
+	cache.Set("idle", 1)
+	time.Sleep(30 * time.Millisecond)
+	cache.Set("active", 2)
+
+	if removed := cache.RemoveExpired(); removed != 1 {
+		t.Errorf("RemoveExpired() = %d, want 1", removed)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+}
+
+func TestCacheGetOrAdd(t *testing.T) {
+	cache := New[string, int](0, 0)
+	created := 0
+	create := func() int {
+		created++
+		return created
+	}
+
+	first, cached := cache.GetOrAdd("a", create)
+	if cached || first != 1 {
+		t.Fatalf("GetOrAdd() = %d, %v, want a new value", first, cached)
+	}
+	second, cached := cache.GetOrAdd("a", create)
+	if !cached || second != first {
+		t.Errorf("GetOrAdd() = %d, %v, want the cached %d", second, cached, first)
+	}
+	if created != 1 {
+		t.Errorf("create was called %d times, want 1", created)
+	}
+}
@@ -0,0 +1,49 @@
+// Package metrics exposes process-wide counters through expvar
+package metrics
+
+import (
+	"expvar"
+	"fmt"
+	"time"
+)
+
+var (
+	httpRequests        = expvar.NewMap("http_requests_total")
+	httpRequestDuration = expvar.NewMap("http_request_duration_ms_total")
+	proxyClientCanceled = expvar.NewInt("proxy_client_canceled_total")
+	sessionsReaped      = expvar.NewInt("sessions_reaped_total")
+	sessionsReapedLast  = expvar.NewInt("sessions_reaped_last_sweep")
+	rateLimitVisitors   = expvar.NewMap("rate_limit_visitors")
+)
+
+// RecordHTTPRequest counts a completed request. route should be the route
+// template rather than the raw path to keep the number of keys bounded.
+func RecordHTTPRequest(method string, route string, statusCode int, latency time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	key := fmt.Sprintf("%s %s %d", method, route, statusCode)
+	httpRequests.Add(key, 1)
+	httpRequestDuration.Add(key, latency.Milliseconds())
+}
+
+// RecordProxyClientCanceled counts a proxied request abandoned because the
+// client went away before the upstream finished
+func RecordProxyClientCanceled() {
+	proxyClientCanceled.Add(1)
+}
+
+// RecordSessionSweep records how many expired sessions a cleanup sweep deleted
+func RecordSessionSweep(reaped int) {
+	sessionsReaped.Add(int64(reaped))
+	sessionsReapedLast.Set(int64(reaped))
+}
+
+// AddRateLimitVisitors adjusts the number of clients tracked by the named
+// in-memory rate limiter
+func AddRateLimitVisitors(limiter string, delta int) {
+	rateLimitVisitors.Add(limiter, int64(delta))
+}
+
+// Handler serves all registered expvar metrics as JSON
+var Handler = expvar.Handler
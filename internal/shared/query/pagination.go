@@ -6,3 +6,16 @@ type Pagination struct {
 	SortBy    *string
 	SortOrder *string
 }
+
+// MaxLimit caps how many records a single list call may return, whatever
+// the caller asked for
+const MaxLimit = 100
+
+// EffectiveLimit returns the page size to read: the requested limit capped
+// at MaxLimit, with zero (no limit requested) meaning MaxLimit
+func (p *Pagination) EffectiveLimit() int {
+	if p.Limit <= 0 || p.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return p.Limit
+}
@@ -0,0 +1,21 @@
+// Package requestid carries the ID of the HTTP request being served through
+// contexts, so logs and events emitted while serving it can be correlated
+package requestid
+
+import "context"
+
+// Header carries the request ID to and from clients and upstream services
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" when there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
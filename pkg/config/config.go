@@ -10,6 +10,24 @@ import (
 type LoggingConfig struct {
 	Level  string
 	Format string
+	// ProxySampleRate logs every Nth successful proxy request; errors and
+	// slow requests are always logged. 1 logs every request.
+	ProxySampleRate int
+	// SlowRequestThreshold marks requests as slow so they bypass sampling
+	SlowRequestThreshold int // in milliseconds
+	// AccessLogFormat selects how requests are logged: "structured" through
+	// the application logger, "combined" as Apache Combined Log Format lines
+	// on stdout, or "both". Combined lines are never sampled.
+	AccessLogFormat string
+	// BodyLogPaths are path prefixes whose request and response bodies are
+	// logged at debug level, redacted, for diagnosing integration issues.
+	// Empty disables body logging.
+	BodyLogPaths []string
+	// BodyLogSampleRate logs the bodies of every Nth matching request
+	BodyLogSampleRate int
+	// BodyLogMaxBytes caps the size of a logged body; larger bodies are
+	// logged by size only
+	BodyLogMaxBytes int
 }
 
 // ServerConfig holds settings for the HTTP server
@@ -21,6 +39,31 @@ type ServerConfig struct {
 	WriteTimeout int
 	IdleTimeout  int
 	GINMode      string
+	// StartupWaitTimeout keeps retrying unreachable dependencies at startup
+	// for up to this long instead of exiting; zero fails on the first error
+	StartupWaitTimeout int // in seconds
+	// StartupRetryInterval is the first retry delay; it doubles up to 30s
+	StartupRetryInterval int // in seconds
+	// RateLimitSoftThreshold is the fraction of the per-client burst after
+	// which responses carry X-RateLimit-Warning; zero disables the warning
+	RateLimitSoftThreshold float64
+	// RouteRateLimits overrides the per-client limit of sensitive route
+	// groups; see RouteRateLimitOverrides
+	RouteRateLimits map[string][]string
+	// RateLimitVisitorIdleTimeout forgets rate-limited clients idle this
+	// long, and RateLimitMaxVisitors caps the clients each in-memory limiter
+	// tracks, evicting the least recently seen; zero leaves it unbounded
+	RateLimitVisitorIdleTimeout int // in seconds
+	RateLimitMaxVisitors        int
+	// RequireJSONContentType answers write requests whose body is not
+	// declared as JSON with 415, except for routes taking other content
+	RequireJSONContentType bool
+	// Request deadlines propagated to Firestore and Firebase calls. Admin
+	// reads get a shorter deadline and proxied streams a longer one; zero
+	// disables the deadline.
+	RequestTimeout      int // in seconds
+	AdminRequestTimeout int // in seconds
+	ProxyRequestTimeout int // in seconds
 }
 
 // CookieConfig holds settings for session cookies
@@ -31,16 +74,283 @@ type CookieConfig struct {
 	SameSite string
 	HTTPOnly bool
 	MaxAge   int // in seconds
+	// Partitioned adds the CHIPS Partitioned attribute, which browsers
+	// require for cookies set while embedded in third-party sites
+	Partitioned bool
+}
+
+// UseHTTPS sets the attributes a cross-site frontend needs once the service
+// is served over HTTPS, and reports whether any setting changed
+func (c *CookieConfig) UseHTTPS() bool {
+	changed := !c.Secure || c.SameSite != "None"
+	c.Secure = true
+	c.SameSite = "None"
+	return changed
+}
+
+// CORSConfig holds cross-origin resource sharing settings shared by the
+// global CORS middleware and the main service proxy
+type CORSConfig struct {
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         int // in seconds
+	// PublicOrigins may read public proxy paths with requests that carry no
+	// credentials; "*" allows any origin. Such responses omit
+	// Allow-Credentials so they can be cached and shared across origins,
+	// while credentialed requests stay limited to AllowedOrigins. Empty
+	// applies AllowedOrigins to every request.
+	PublicOrigins []string
+}
+
+// AllowsPublicOrigin reports whether origin may make uncredentialed
+// requests to public proxy paths
+func (c *CORSConfig) AllowsPublicOrigin(origin string) bool {
+	for _, allowed := range c.PublicOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionConfig holds settings for session lifecycle
+type SessionConfig struct {
+	// ExpiryGracePeriod keeps a session valid for a short window past its
+	// expiry to tolerate clock skew and in-flight requests. Zero disables it.
+	ExpiryGracePeriod int // in seconds
+	// MetadataMaxKeys and MetadataMaxBytes cap session metadata, including
+	// the keys the service sets itself
+	MetadataMaxKeys  int
+	MetadataMaxBytes int
+	// ScopeRoles overrides which roles may create sessions per scope, given
+	// as "scope:role|role" pairs separated by commas
+	ScopeRoles map[string][]string
+	// TokenScopeClaim names the ID token claim identifying the app a token
+	// was minted for; empty lets any token create sessions in any scope
+	TokenScopeClaim string
+	// TokenScopes lists the session scopes each claim value may create, as
+	// "value:scope|scope" pairs separated by commas
+	TokenScopes map[string][]string
+	// ScopeExtension tunes when sessions are extended on use per scope, as
+	// "scope:threshold|interval" pairs separated by commas, where threshold
+	// is the remaining lifetime fraction and interval is in seconds
+	ScopeExtension map[string][]string
+	// Scopes declares additional session scopes; see ScopeDefinitions
+	Scopes map[string][]string
+	// RememberMeDuration is the lifetime of default-scope sessions created
+	// with remember me; zero disables the option
+	RememberMeDuration int // in seconds
+	// MaxLifetime caps how long any session may live after creation, however
+	// often it is extended; zero leaves sessions uncapped
+	MaxLifetime int // in seconds
+	// CleanupInterval is how often expired sessions are swept from the store
+	CleanupInterval int // in seconds
+	// CleanupBatchSize bounds how many sessions a sweep deletes per lock
+	CleanupBatchSize int
+	// MaxTotalPerUser caps a user's active sessions across all scopes; zero
+	// leaves only the per-scope limits
+	MaxTotalPerUser int
+	// TotalLimitPolicy is what happens when a new session would exceed
+	// MaxTotalPerUser: "evict" the least recently used sessions or "reject"
+	// the new one
+	TotalLimitPolicy string
+	// OnUserDelete is what happens to a user's sessions when the user is
+	// deleted: "revoke" deletes them at once, "expire" leaves them to expire,
+	// since requests on them already fail once the profile is gone
+	OnUserDelete string
+	// GeoIPDatabasePath locates the MaxMind GeoLite2 City database (.mmdb)
+	// used to show where sessions were created from, in session listings
+	// and new-device sign-in emails; unset leaves locations out
+	GeoIPDatabasePath string
+}
+
+// CacheConfig holds settings for in-process caches
+type CacheConfig struct {
+	// UserTTL caches user profiles looked up on every authenticated request;
+	// zero disables the cache
+	UserTTL        int // in seconds
+	UserMaxEntries int
+	// UserStatsTTL caches the admin dashboard's aggregate user counts
+	UserStatsTTL int // in seconds
+	// GeoTTL and GeoMaxEntries bound the cache of session locations by IP
+	// address
+	GeoTTL        int // in seconds
+	GeoMaxEntries int
+}
+
+// FirestoreConfig holds settings for Firestore access
+type FirestoreConfig struct {
+	// BulkWriteConcurrency bounds concurrent mutations in bulk operations so
+	// bursts do not run into contention or write quotas
+	BulkWriteConcurrency int
+}
+
+// RegistrationConfig holds settings for self-service registration
+type RegistrationConfig struct {
+	// EmailAvailabilityCheck exposes GET /auth/email-available. The endpoint
+	// lets anyone learn whether an address has an account, so it is off by
+	// default; enable it only where account existence is not private.
+	EmailAvailabilityCheck bool
+	// EmailAvailabilityRatePerMinute caps checks per client IP
+	EmailAvailabilityRatePerMinute int
+	// EmailAvailabilityMinResponse pads every response to a fixed minimum
+	// duration so timing does not reveal whether the address exists.
+	EmailAvailabilityMinResponse int // in milliseconds
+	// AllowedDomains restricts self-registration to these email domains.
+	// Entries like "*.hospital.org" also match subdomains. Empty allows all.
+	AllowedDomains []string
+	// RequireEmailVerification asks users with unverified emails to verify before continuing
+	RequireEmailVerification bool
+	// EmailVerificationGraceHours lets users with unverified emails sign in
+	// for this long after registering, after which they are rejected until
+	// they verify; zero never rejects them
+	EmailVerificationGraceHours int
+	// PendingApprovalTTL expires registrations left pending longer than this; zero disables it
+	PendingApprovalTTL int // in hours
+	// PendingApprovalAction is "reject" to mark expired users rejected or "delete" to remove them
+	PendingApprovalAction string
+	// PendingApprovalNotify emails users when their registration expires
+	PendingApprovalNotify bool
+	// PendingApprovalCheckInterval is how often expired registrations are processed
+	PendingApprovalCheckInterval int // in seconds
+	// RateLimitPerHour caps registrations per client IP across all replicas;
+	// zero disables the limit
+	RateLimitPerHour int
+	// BootstrapAdminEmail is promoted to admin on registration or login while
+	// no admin exists. It has no effect once any admin exists.
+	BootstrapAdminEmail string
+}
+
+// EmailConfig holds SMTP settings for outgoing email. When Host is empty,
+// emails are written to the log instead of being delivered.
+type EmailConfig struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	// ActionLinkAllowedOrigins are the origins email action links (email
+	// verification, password reset) may redirect to after the action.
+	// Defaults to the CORS allowed origins.
+	ActionLinkAllowedOrigins []string
+}
+
+// NotificationConfig holds settings for user-facing notifications
+type NotificationConfig struct {
+	// NewDeviceLogin emails users when they sign in from an unrecognized device
+	NewDeviceLogin bool
+	// NewDeviceDebounce suppresses repeat emails for the same device
+	NewDeviceDebounce int // in seconds
+	// SessionManagementURL is linked from notifications so users can revoke sessions
+	SessionManagementURL string
+	// RoleChange emails users when an admin changes their role
+	RoleChange bool
+}
+
+// ProxyConfig holds settings for the main service proxy
+type ProxyConfig struct {
+	// Enabled serves the main service proxy under /api/v1/proxy. Disable it
+	// when the main service sits behind a separate gateway and this service
+	// only handles identity and sessions.
+	Enabled bool
+	// StripHeaders are removed from client requests before forwarding so
+	// clients cannot spoof identity headers the proxy injects itself
+	StripHeaders []string
+	// SigningSecret enables HMAC request signing for upstream calls when set.
+	// Intended for deployments that do not rely on Cloud Run IAM.
+	SigningSecret string
+	// MaxSignedBodyBytes caps the request bodies buffered to sign them;
+	// larger bodies are rejected with 413 when signing is enabled
+	MaxSignedBodyBytes int64 // in bytes
+	// NormalizeUpstreamErrors rewrites JSON error bodies from the upstream
+	// into the standard error shape; by default they pass through as is
+	NormalizeUpstreamErrors bool
+	// RetryAfter is the Retry-After sent with 503s when the upstream is
+	// unreachable. It doubles with each consecutive failure up to RetryAfterMax.
+	RetryAfter    int // in seconds
+	RetryAfterMax int // in seconds
+	// PublicPathPrefixes are proxy paths, relative to /api/v1/proxy, that
+	// are forwarded without authentication, e.g. "/public/"
+	PublicPathPrefixes []string
+	// PreserveHost forwards the client's Host header instead of the
+	// upstream's. Upstreams routed by host, such as Cloud Run services,
+	// need it off; X-Forwarded-Host carries the original host either way.
+	PreserveHost bool
+	// AuthContextSecret, when set, signs the X-Auth-Context JWT carrying the
+	// user's identity so the upstream can detect forged identity headers
+	AuthContextSecret string
+	// AuthContextTTL is how long an X-Auth-Context token is valid
+	AuthContextTTL int // in seconds
+	// AuthContextMetadata are session metadata keys, such as those added by
+	// a session enricher, copied into the X-Auth-Context token
+	AuthContextMetadata []string
+	// PlainIdentityHeaders injects the unsigned X-User-ID and X-User-Role
+	// headers, for upstreams that do not verify X-Auth-Context yet
+	PlainIdentityHeaders bool
+	// MaxURLLength rejects proxied requests whose path and query together
+	// are longer, with 414; zero disables the check
+	MaxURLLength int // in bytes
+}
+
+// FeatureFlagsConfig holds the source of feature flag rules
+type FeatureFlagsConfig struct {
+	// File is a JSON file of flag rules, reloaded when it changes. Takes
+	// precedence over Flags.
+	File string
+	// ReloadInterval is how often File is checked for changes
+	ReloadInterval int // in seconds
+	// Flags holds flag rules as inline JSON for deployments without a file
+	Flags string
+}
+
+// EventsConfig holds settings for delivering domain events to other services
+type EventsConfig struct {
+	// WebhookURL receives events as JSON POSTs; empty logs events instead
+	WebhookURL string
+	// WebhookSecret signs webhook requests with HMAC when set
+	WebhookSecret string
+	// PubSubTopic receives session and login activity events for analytics,
+	// as a topic in PROJECT_ID or a full topic name; empty disables them
+	PubSubTopic string
+	// PubSubBufferSize bounds the events waiting to be published; further
+	// events are dropped
+	PubSubBufferSize int
+}
+
+// InternalAPIConfig holds settings for service-to-service endpoints
+type InternalAPIConfig struct {
+	// Audience is the expected audience of service ID tokens
+	Audience string
+	// AllowedServiceAccounts may call internal endpoints; empty disables them
+	AllowedServiceAccounts []string
 }
 
 // SecurityConfig holds security-related settings
 type SecurityConfig struct {
 	TrustedProxies []string
+	// RequireDualControlForAdmin requires a second admin to confirm every
+	// admin promotion before the role change is applied.
+	RequireDualControlForAdmin bool
+	// ApprovalRoles are the roles an admin may assign when approving a user;
+	// admin is excluded so it is only granted through promotion
+	ApprovalRoles []string
+	// Messages shown to signed-in users whose account cannot be used yet or
+	// anymore; empty keeps the built-in wording
+	AccountPendingMessage   string
+	AccountSuspendedMessage string
+	AccountRejectedMessage  string
 }
 
 type TLSConfig struct {
 	CertPath string
 	KeyPath  string
+	// MinVersion is the lowest TLS version accepted, "1.2" or "1.3"
+	MinVersion string
+	// CipherSuites restricts TLS 1.2 cipher suites by their standard names;
+	// empty uses Go's secure defaults. TLS 1.3 suites are not configurable.
+	CipherSuites []string
 }
 
 type Config struct {
@@ -48,9 +358,19 @@ type Config struct {
 	Region         string
 	ProjectNumber  string
 	MainServiceURL string
-	AllowedOrigins []string
 	Server         ServerConfig
 	Cookie         CookieConfig
+	CORS           CORSConfig
+	Session        SessionConfig
+	Cache          CacheConfig
+	Firestore      FirestoreConfig
+	Registration   RegistrationConfig
+	Email          EmailConfig
+	Notification   NotificationConfig
+	Proxy          ProxyConfig
+	InternalAPI    InternalAPIConfig
+	Events         EventsConfig
+	FeatureFlags   FeatureFlagsConfig
 	Security       SecurityConfig
 	TLS            TLSConfig
 	Logging        LoggingConfig
@@ -58,18 +378,11 @@ type Config struct {
 
 func LoadConfig() *Config {
 	env := getEnv("ENVIRONMENT", "dev")
-	allowedOriginsEnv := getEnv("ALLOWED_ORIGINS", "https://localhost:5173,https://histopathai.com")
-	allowedOrigins := strings.Split(allowedOriginsEnv, ",")
-	for i := range allowedOrigins {
-		allowedOrigins[i] = strings.TrimSpace(allowedOrigins[i])
-	}
-
 	cfg := &Config{
 		ProjectID:      getEnv("PROJECT_ID", ""),
 		Region:         getEnv("REGION", ""),
 		ProjectNumber:  getEnv("PROJECT_NUMBER", ""),
 		MainServiceURL: getEnv("MAIN_SERVICE_URL", "https://localhost:8081"),
-		AllowedOrigins: allowedOrigins,
 		Server: ServerConfig{
 			Port:         getEnv("PORT", "8081"),
 			Environment:  env,
@@ -77,14 +390,150 @@ func LoadConfig() *Config {
 			ReadTimeout:  getEnvInt("READ_TIMEOUT", 15),
 			WriteTimeout: getEnvInt("WRITE_TIMEOUT", 15),
 			IdleTimeout:  getEnvInt("IDLE_TIMEOUT", 60),
-			GINMode:      "debug",
+
+			StartupWaitTimeout:   getEnvInt("STARTUP_WAIT_TIMEOUT", 0),
+			StartupRetryInterval: getEnvInt("STARTUP_RETRY_INTERVAL", 2),
+
+			RateLimitSoftThreshold: getEnvFloat("RATE_LIMIT_SOFT_THRESHOLD", 0.8),
+			RouteRateLimits:        parseListMap(getEnvList("RATE_LIMIT_ROUTES", "register:10|5,password:5|5")),
+
+			RateLimitVisitorIdleTimeout: getEnvInt("RATE_LIMIT_VISITOR_IDLE_TIMEOUT", 600),
+			RateLimitMaxVisitors:        getEnvInt("RATE_LIMIT_MAX_VISITORS", 100000),
+
+			RequireJSONContentType: getEnvBool("REQUIRE_JSON_CONTENT_TYPE", true),
+
+			RequestTimeout:      getEnvInt("REQUEST_TIMEOUT", 30),
+			AdminRequestTimeout: getEnvInt("ADMIN_REQUEST_TIMEOUT", 10),
+			ProxyRequestTimeout: getEnvInt("PROXY_REQUEST_TIMEOUT", 300),
+			GINMode:             "debug",
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "debug"),
-			Format: getEnv("LOG_FORMAT", "text"),
+			Level:                getEnv("LOG_LEVEL", "debug"),
+			Format:               getEnv("LOG_FORMAT", "text"),
+			ProxySampleRate:      getEnvInt("LOG_PROXY_SAMPLE_RATE", 1),
+			SlowRequestThreshold: getEnvInt("LOG_SLOW_REQUEST_THRESHOLD_MS", 2000),
+			AccessLogFormat:      getEnv("LOG_ACCESS_FORMAT", "structured"),
+			BodyLogPaths:         getEnvList("LOG_BODY_PATHS", ""),
+			BodyLogSampleRate:    getEnvInt("LOG_BODY_SAMPLE_RATE", 1),
+			BodyLogMaxBytes:      getEnvInt("LOG_BODY_MAX_BYTES", 4096),
 		},
 	}
 
+	cfg.CORS = CORSConfig{
+		AllowedOrigins: getEnvList("ALLOWED_ORIGINS", "https://localhost:5173,https://histopathai.com"),
+		AllowedMethods: getEnvList("CORS_ALLOWED_METHODS", "GET,HEAD,POST,PUT,DELETE,OPTIONS,PATCH"),
+		AllowedHeaders: getEnvList("CORS_ALLOWED_HEADERS", "Authorization,Content-Type,Cookie,X-Session-ID,X-Request-ID,If-Match"),
+		ExposedHeaders: getEnvList("CORS_EXPOSED_HEADERS", "Set-Cookie,X-Session-Expiring,X-Session-Expires-At,Retry-After,Location,X-RateLimit-Warning,X-Request-ID,ETag,X-Email-Verification-Due"),
+		MaxAge:         getEnvInt("CORS_MAX_AGE", 3600),
+		PublicOrigins:  getEnvList("CORS_PUBLIC_ORIGINS", ""),
+	}
+
+	cfg.Session = SessionConfig{
+		ExpiryGracePeriod:  getEnvInt("SESSION_EXPIRY_GRACE_PERIOD", 0),
+		MetadataMaxKeys:    getEnvInt("SESSION_METADATA_MAX_KEYS", 32),
+		MetadataMaxBytes:   getEnvInt("SESSION_METADATA_MAX_BYTES", 4096),
+		ScopeRoles:         parseListMap(getEnvList("SESSION_SCOPE_ROLES", "")),
+		TokenScopeClaim:    getEnv("SESSION_TOKEN_SCOPE_CLAIM", ""),
+		TokenScopes:        parseListMap(getEnvList("SESSION_TOKEN_SCOPES", "")),
+		ScopeExtension:     parseListMap(getEnvList("SESSION_SCOPE_EXTENSION", "")),
+		Scopes:             parseListMap(getEnvList("SESSION_SCOPES", "")),
+		RememberMeDuration: getEnvInt("SESSION_REMEMBER_ME_DURATION", 7*24*60*60),
+		MaxLifetime:        getEnvInt("SESSION_MAX_LIFETIME", 30*24*60*60),
+		CleanupInterval:    getEnvInt("SESSION_CLEANUP_INTERVAL", 300),
+		CleanupBatchSize:   getEnvInt("SESSION_CLEANUP_BATCH_SIZE", 1000),
+		MaxTotalPerUser:    getEnvInt("SESSION_MAX_TOTAL_PER_USER", 0),
+		TotalLimitPolicy:   getEnv("SESSION_TOTAL_LIMIT_POLICY", "evict"),
+		OnUserDelete:       getEnv("SESSION_ON_USER_DELETE", "revoke"),
+		GeoIPDatabasePath:  getEnv("GEOIP_DATABASE_PATH", ""),
+	}
+
+	cfg.Cache = CacheConfig{
+		UserTTL:        getEnvInt("USER_CACHE_TTL", 30),
+		UserMaxEntries: getEnvInt("USER_CACHE_MAX_ENTRIES", 10000),
+		UserStatsTTL:   getEnvInt("USER_STATS_CACHE_TTL", 60),
+		GeoTTL:         getEnvInt("GEO_CACHE_TTL", 24*60*60),
+		GeoMaxEntries:  getEnvInt("GEO_CACHE_MAX_ENTRIES", 10000),
+	}
+
+	cfg.Firestore = FirestoreConfig{
+		BulkWriteConcurrency: getEnvInt("FIRESTORE_BULK_WRITE_CONCURRENCY", 4),
+	}
+
+	cfg.Registration = RegistrationConfig{
+		EmailAvailabilityCheck:         getEnvBool("EMAIL_AVAILABILITY_CHECK", false),
+		EmailAvailabilityRatePerMinute: getEnvInt("EMAIL_AVAILABILITY_RATE_PER_MINUTE", 5),
+		EmailAvailabilityMinResponse:   getEnvInt("EMAIL_AVAILABILITY_MIN_RESPONSE_MS", 400),
+		AllowedDomains:                 getEnvList("ALLOWED_REGISTRATION_DOMAINS", ""),
+		RequireEmailVerification:       getEnvBool("REQUIRE_EMAIL_VERIFICATION", false),
+		EmailVerificationGraceHours:    getEnvInt("EMAIL_VERIFICATION_GRACE_HOURS", 0),
+		PendingApprovalTTL:             getEnvInt("PENDING_APPROVAL_TTL_HOURS", 0),
+		PendingApprovalAction:          getEnv("PENDING_APPROVAL_ACTION", "reject"),
+		PendingApprovalNotify:          getEnvBool("PENDING_APPROVAL_NOTIFY", false),
+		PendingApprovalCheckInterval:   getEnvInt("PENDING_APPROVAL_CHECK_INTERVAL", 3600),
+		RateLimitPerHour:               getEnvInt("REGISTRATION_RATE_LIMIT_PER_HOUR", 5),
+		BootstrapAdminEmail:            getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
+	}
+
+	cfg.Email = EmailConfig{
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		From:         getEnv("EMAIL_FROM", "no-reply@histopathai.com"),
+
+		ActionLinkAllowedOrigins: getEnvList("EMAIL_ACTION_LINK_ALLOWED_ORIGINS", strings.Join(cfg.CORS.AllowedOrigins, ",")),
+	}
+
+	cfg.Notification = NotificationConfig{
+		NewDeviceLogin:       getEnvBool("NEW_DEVICE_LOGIN_NOTIFICATION", false),
+		NewDeviceDebounce:    getEnvInt("NEW_DEVICE_LOGIN_DEBOUNCE", 3600),
+		SessionManagementURL: getEnv("SESSION_MANAGEMENT_URL", "https://histopathai.com/account/sessions"),
+		RoleChange:           getEnvBool("ROLE_CHANGE_NOTIFICATION", false),
+	}
+
+	cfg.Proxy = ProxyConfig{
+		Enabled:                 getEnvBool("PROXY_ENABLED", true),
+		StripHeaders:            getEnvList("PROXY_STRIP_HEADERS", "X-User-ID,X-User-Role,X-Session-ID,X-Forwarded-For,X-Forwarded-Host,X-Forwarded-Proto,X-Real-IP,X-Auth-Context"),
+		SigningSecret:           getEnv("PROXY_SIGNING_SECRET", ""),
+		MaxSignedBodyBytes:      int64(getEnvInt("PROXY_MAX_SIGNED_BODY_BYTES", 10<<20)),
+		NormalizeUpstreamErrors: getEnvBool("PROXY_NORMALIZE_UPSTREAM_ERRORS", false),
+		RetryAfter:              getEnvInt("PROXY_RETRY_AFTER_SECONDS", 5),
+		RetryAfterMax:           getEnvInt("PROXY_RETRY_AFTER_MAX_SECONDS", 60),
+		PublicPathPrefixes:      getEnvList("PROXY_PUBLIC_PATH_PREFIXES", ""),
+		PreserveHost:            getEnvBool("PROXY_PRESERVE_HOST", false),
+		AuthContextSecret:       getEnv("PROXY_AUTH_CONTEXT_SECRET", ""),
+		AuthContextTTL:          getEnvInt("PROXY_AUTH_CONTEXT_TTL_SECONDS", 60),
+		AuthContextMetadata:     getEnvList("PROXY_AUTH_CONTEXT_METADATA", ""),
+		PlainIdentityHeaders:    getEnvBool("PROXY_PLAIN_IDENTITY_HEADERS", true),
+		MaxURLLength:            getEnvInt("PROXY_MAX_URL_LENGTH", 8192),
+	}
+
+	cfg.InternalAPI = InternalAPIConfig{
+		Audience:               getEnv("INTERNAL_API_AUDIENCE", cfg.Server.BaseURL),
+		AllowedServiceAccounts: getEnvList("INTERNAL_ALLOWED_SERVICE_ACCOUNTS", ""),
+	}
+
+	cfg.FeatureFlags = FeatureFlagsConfig{
+		File:           getEnv("FEATURE_FLAGS_FILE", ""),
+		ReloadInterval: getEnvInt("FEATURE_FLAGS_RELOAD_INTERVAL", 30),
+		Flags:          getEnv("FEATURE_FLAGS", ""),
+	}
+
+	cfg.Events = EventsConfig{
+		WebhookURL:       getEnv("EVENTS_WEBHOOK_URL", ""),
+		WebhookSecret:    getEnv("EVENTS_WEBHOOK_SECRET", ""),
+		PubSubTopic:      getEnv("EVENTS_PUBSUB_TOPIC", ""),
+		PubSubBufferSize: getEnvInt("EVENTS_PUBSUB_BUFFER_SIZE", 1000),
+	}
+
+	cfg.Security = SecurityConfig{
+		RequireDualControlForAdmin: getEnvBool("REQUIRE_DUAL_CONTROL_FOR_ADMIN", false),
+		ApprovalRoles:              getEnvList("APPROVAL_ROLES", "user,viewer"),
+		AccountPendingMessage:      getEnv("ACCOUNT_PENDING_MESSAGE", ""),
+		AccountSuspendedMessage:    getEnv("ACCOUNT_SUSPENDED_MESSAGE", ""),
+		AccountRejectedMessage:     getEnv("ACCOUNT_REJECTED_MESSAGE", ""),
+	}
+
 	cfg.Cookie = CookieConfig{
 		Name:     "session_id",
 		Domain:   getEnv("COOKIE_DOMAIN", ""),
@@ -92,6 +541,8 @@ func LoadConfig() *Config {
 		SameSite: "None",
 		HTTPOnly: true,
 		MaxAge:   1800,
+
+		Partitioned: getEnvBool("COOKIE_PARTITIONED", false),
 	}
 
 	// Environment-specific overrides
@@ -100,12 +551,16 @@ func LoadConfig() *Config {
 		cfg.Logging.Level = getEnv("LOG_LEVEL", "info")
 		cfg.Logging.Format = getEnv("LOG_FORMAT", "json")
 	} else {
+		// Local development over plain HTTP may relax the cookie attributes,
+		// since browsers drop Secure cookies from non-localhost HTTP origins
+		cfg.Cookie.Secure = getEnvBool("COOKIE_SECURE", true)
+		cfg.Cookie.SameSite = getEnv("COOKIE_SAMESITE", "None")
 
-		cfg.TLS = TLSConfig{
-			CertPath: getEnv("CERT_PATH", ""),
-			KeyPath:  getEnv("KEY_PATH", ""),
-		}
+		cfg.TLS.CertPath = getEnv("CERT_PATH", "")
+		cfg.TLS.KeyPath = getEnv("KEY_PATH", "")
 	}
+	cfg.TLS.MinVersion = getEnv("TLS_MIN_VERSION", "1.2")
+	cfg.TLS.CipherSuites = getEnvList("TLS_CIPHER_SUITES", "")
 
 	return cfg
 }
@@ -118,6 +573,48 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvBool retrieves an environment variable as a boolean or returns a default
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList retrieves a comma-separated environment variable as a trimmed list
+func getEnvList(key, defaultValue string) []string {
+	parts := strings.Split(getEnv(key, defaultValue), ",")
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
+}
+
+// parseListMap turns "key:value|value" entries into a value list per key.
+// Entries without a colon are ignored.
+func parseListMap(entries []string) map[string][]string {
+	lists := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		key, valueList, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		values := []string{}
+		for _, value := range strings.Split(valueList, "|") {
+			if trimmed := strings.TrimSpace(value); trimmed != "" {
+				values = append(values, trimmed)
+			}
+		}
+		lists[strings.TrimSpace(key)] = values
+	}
+	return lists
+}
+
 // getEnvInt retrieves an environment variable as an integer or returns a default
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -127,3 +624,13 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvFloat retrieves an environment variable as a float or returns a default
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Route groups whose rate limit can be overridden. They cover the endpoints
+// that write to Firebase or send email and so need stricter limits than the
+// global one.
+const (
+	RateLimitGroupRegister = "register"
+	RateLimitGroupPassword = "password"
+)
+
+var rateLimitGroups = []string{RateLimitGroupRegister, RateLimitGroupPassword}
+
+// RouteRateLimit is the per-client limit of a route group
+type RouteRateLimit struct {
+	Rate  int // requests per minute
+	Burst int
+}
+
+// RouteRateLimitOverrides parses the RATE_LIMIT_ROUTES entries, given as
+// "group:rate|burst" with the rate in requests per minute. A group without
+// an entry uses only the global limit.
+func (s ServerConfig) RouteRateLimitOverrides() (map[string]RouteRateLimit, error) {
+	var errs []error
+	limits := make(map[string]RouteRateLimit, len(s.RouteRateLimits))
+	for group, values := range s.RouteRateLimits {
+		if !slices.Contains(rateLimitGroups, group) {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_ROUTES group %q must be one of %v", group, rateLimitGroups))
+			continue
+		}
+		if len(values) != 2 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_ROUTES entry %q must be group:rate|burst", group))
+			continue
+		}
+
+		rate, rateErr := strconv.Atoi(values[0])
+		burst, burstErr := strconv.Atoi(values[1])
+		if rateErr != nil || burstErr != nil || rate <= 0 || burst <= 0 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_ROUTES entry %q needs a positive rate and burst", group))
+			continue
+		}
+
+		limits[group] = RouteRateLimit{Rate: rate, Burst: burst}
+	}
+
+	return limits, errors.Join(errs...)
+}
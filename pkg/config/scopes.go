@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ScopeDefinition declares a session scope on top of the built-in ones
+type ScopeDefinition struct {
+	Name         string
+	Expiration   int // in seconds
+	MaxPerUser   int
+	IdleTimeout  int // in seconds, zero disables it
+	AllowedRoles []string
+}
+
+// scopeNamePattern matches the scope names a client may request
+var scopeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,63}$`)
+
+// ScopeDefinitions parses the SESSION_SCOPES entries, given as
+// "name:expiration|max_per_user|idle_timeout|role|role" with durations in
+// seconds. Omitting the roles lets every role create sessions in the scope.
+// Definitions are returned sorted by name.
+func (s SessionConfig) ScopeDefinitions() ([]ScopeDefinition, error) {
+	var errs []error
+	definitions := make([]ScopeDefinition, 0, len(s.Scopes))
+	for name, values := range s.Scopes {
+		if !scopeNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("SESSION_SCOPES name %q must be lowercase letters, digits and dashes", name))
+			continue
+		}
+		if len(values) < 3 {
+			errs = append(errs, fmt.Errorf("SESSION_SCOPES entry %q must be name:expiration|max_per_user|idle_timeout[|role...]", name))
+			continue
+		}
+
+		numbers := make([]int, 3)
+		for i, value := range values[:3] {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				errs = append(errs, fmt.Errorf("SESSION_SCOPES entry %q has invalid number %q", name, value))
+			}
+			numbers[i] = n
+		}
+		if numbers[0] <= 0 || numbers[1] <= 0 {
+			errs = append(errs, fmt.Errorf("SESSION_SCOPES entry %q needs a positive expiration and max_per_user", name))
+			continue
+		}
+
+		definitions = append(definitions, ScopeDefinition{
+			Name:         name,
+			Expiration:   numbers[0],
+			MaxPerUser:   numbers[1],
+			IdleTimeout:  numbers[2],
+			AllowedRoles: values[3:],
+		})
+	}
+
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions, errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// ServerConfig builds the TLS settings for the HTTPS server from the
+// configured minimum version and cipher suites
+func (t TLSConfig) ServerConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	switch t.MinVersion {
+	case "", "1.2":
+		tlsConfig.MinVersion = tls.VersionTLS12
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("TLS_MIN_VERSION must be 1.2 or 1.3, got %q", t.MinVersion)
+	}
+
+	if len(t.CipherSuites) == 0 {
+		return tlsConfig, nil
+	}
+
+	// Only suites Go considers secure can be selected
+	available := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+	for _, name := range t.CipherSuites {
+		id, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("TLS_CIPHER_SUITES contains unknown or insecure suite %q", name)
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Load reads the configuration from the environment and validates it.
+// Every problem found is reported, not just the first.
+func Load() (*Config, error) {
+	cfg := LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Validate checks required values and the consistency of related settings
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.ProjectID != "", "PROJECT_ID is required")
+
+	if c.Proxy.Enabled {
+		check(isAbsoluteURL(c.MainServiceURL), "MAIN_SERVICE_URL must be an absolute URL, got %q", c.MainServiceURL)
+	}
+	check(isAbsoluteURL(c.Server.BaseURL), "BASE_URL must be an absolute URL, got %q", c.Server.BaseURL)
+	if c.Events.WebhookURL != "" {
+		check(isAbsoluteURL(c.Events.WebhookURL), "EVENTS_WEBHOOK_URL must be an absolute URL, got %q", c.Events.WebhookURL)
+	}
+	if c.Notification.SessionManagementURL != "" {
+		check(isAbsoluteURL(c.Notification.SessionManagementURL), "SESSION_MANAGEMENT_URL must be an absolute URL, got %q", c.Notification.SessionManagementURL)
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "PORT must be a valid port number, got %q", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "READ_TIMEOUT must be positive, got %d", c.Server.ReadTimeout)
+	check(c.Server.WriteTimeout > 0, "WRITE_TIMEOUT must be positive, got %d", c.Server.WriteTimeout)
+	check(c.Server.IdleTimeout > 0, "IDLE_TIMEOUT must be positive, got %d", c.Server.IdleTimeout)
+	check(c.Server.StartupWaitTimeout >= 0, "STARTUP_WAIT_TIMEOUT must not be negative, got %d", c.Server.StartupWaitTimeout)
+	check(c.Server.RequestTimeout >= 0 && c.Server.AdminRequestTimeout >= 0 && c.Server.ProxyRequestTimeout >= 0,
+		"request timeouts must not be negative")
+	check(c.Server.RateLimitSoftThreshold >= 0 && c.Server.RateLimitSoftThreshold < 1,
+		"RATE_LIMIT_SOFT_THRESHOLD must be at least 0 and below 1, got %g", c.Server.RateLimitSoftThreshold)
+	check(c.Server.RateLimitVisitorIdleTimeout > 0,
+		"RATE_LIMIT_VISITOR_IDLE_TIMEOUT must be positive, got %d", c.Server.RateLimitVisitorIdleTimeout)
+	check(c.Server.RateLimitMaxVisitors >= 0,
+		"RATE_LIMIT_MAX_VISITORS must not be negative, got %d", c.Server.RateLimitMaxVisitors)
+
+	switch strings.ToLower(c.Cookie.SameSite) {
+	case "lax", "strict":
+	case "none":
+		check(c.Cookie.Secure, "cookies with SameSite=None must be Secure")
+	default:
+		errs = append(errs, fmt.Errorf("cookie SameSite must be Lax, Strict or None, got %q", c.Cookie.SameSite))
+	}
+	if c.Cookie.Partitioned {
+		check(c.Cookie.Secure, "COOKIE_PARTITIONED requires Secure cookies")
+	}
+	check(c.Cookie.MaxAge > 0, "cookie max age must be positive, got %d", c.Cookie.MaxAge)
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(origin == "*" || isAbsoluteURL(origin), "ALLOWED_ORIGINS entry %q must be an origin such as https://app.example.com", origin)
+	}
+	for _, origin := range c.CORS.PublicOrigins {
+		check(origin == "*" || isAbsoluteURL(origin), "CORS_PUBLIC_ORIGINS entry %q must be * or an origin such as https://viewer.example.com", origin)
+	}
+	if len(c.CORS.PublicOrigins) > 0 {
+		check(len(c.Proxy.PublicPathPrefixes) > 0, "CORS_PUBLIC_ORIGINS requires PROXY_PUBLIC_PATH_PREFIXES")
+	}
+
+	check(c.Logging.Format == "text" || c.Logging.Format == "json", "LOG_FORMAT must be text or json, got %q", c.Logging.Format)
+	switch c.Logging.AccessLogFormat {
+	case "structured", "combined", "both":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_ACCESS_FORMAT must be structured, combined or both, got %q", c.Logging.AccessLogFormat))
+	}
+
+	if len(c.Logging.BodyLogPaths) > 0 {
+		check(strings.EqualFold(c.Logging.Level, "debug"), "LOG_BODY_PATHS requires LOG_LEVEL=debug, got %q", c.Logging.Level)
+		check(c.Logging.BodyLogSampleRate > 0, "LOG_BODY_SAMPLE_RATE must be positive, got %d", c.Logging.BodyLogSampleRate)
+		check(c.Logging.BodyLogMaxBytes > 0, "LOG_BODY_MAX_BYTES must be positive, got %d", c.Logging.BodyLogMaxBytes)
+		for _, prefix := range c.Logging.BodyLogPaths {
+			check(strings.HasPrefix(prefix, "/") && prefix != "/", "LOG_BODY_PATHS entry %q must be a path prefix narrower than /", prefix)
+		}
+	}
+
+	check(c.Registration.EmailVerificationGraceHours >= 0,
+		"EMAIL_VERIFICATION_GRACE_HOURS must not be negative, got %d", c.Registration.EmailVerificationGraceHours)
+	check(c.Registration.PendingApprovalAction == "reject" || c.Registration.PendingApprovalAction == "delete",
+		"PENDING_APPROVAL_ACTION must be reject or delete, got %q", c.Registration.PendingApprovalAction)
+
+	check(len(c.Security.ApprovalRoles) > 0, "APPROVAL_ROLES must name at least one role")
+	for _, role := range c.Security.ApprovalRoles {
+		check(role == "user" || role == "viewer", "APPROVAL_ROLES entry %q must be user or viewer", role)
+	}
+
+	check(c.Firestore.BulkWriteConcurrency > 0,
+		"FIRESTORE_BULK_WRITE_CONCURRENCY must be positive, got %d", c.Firestore.BulkWriteConcurrency)
+
+	if c.Proxy.Enabled {
+		check(c.Proxy.RetryAfter > 0 && c.Proxy.RetryAfter <= c.Proxy.RetryAfterMax,
+			"PROXY_RETRY_AFTER_SECONDS must be positive and at most PROXY_RETRY_AFTER_MAX_SECONDS")
+		check(c.Proxy.PlainIdentityHeaders || c.Proxy.AuthContextSecret != "",
+			"PROXY_PLAIN_IDENTITY_HEADERS may only be disabled when PROXY_AUTH_CONTEXT_SECRET is set")
+		if c.Proxy.AuthContextSecret != "" {
+			check(c.Proxy.AuthContextTTL > 0, "PROXY_AUTH_CONTEXT_TTL_SECONDS must be positive, got %d", c.Proxy.AuthContextTTL)
+		}
+		check(len(c.Proxy.AuthContextMetadata) == 0 || c.Proxy.AuthContextSecret != "",
+			"PROXY_AUTH_CONTEXT_METADATA requires PROXY_AUTH_CONTEXT_SECRET")
+		check(c.Proxy.MaxURLLength >= 0, "PROXY_MAX_URL_LENGTH must not be negative, got %d", c.Proxy.MaxURLLength)
+		for _, prefix := range c.Proxy.PublicPathPrefixes {
+			check(strings.HasPrefix(prefix, "/"), "PROXY_PUBLIC_PATH_PREFIXES entry %q must start with /", prefix)
+		}
+		if c.Proxy.SigningSecret != "" {
+			check(c.Proxy.MaxSignedBodyBytes > 0, "PROXY_MAX_SIGNED_BODY_BYTES must be positive, got %d", c.Proxy.MaxSignedBodyBytes)
+		}
+	}
+
+	check(c.Session.RememberMeDuration >= 0, "SESSION_REMEMBER_ME_DURATION must not be negative, got %d", c.Session.RememberMeDuration)
+	check(c.Session.MaxLifetime >= 0, "SESSION_MAX_LIFETIME must not be negative, got %d", c.Session.MaxLifetime)
+	if c.Session.MaxLifetime > 0 {
+		check(c.Session.RememberMeDuration <= c.Session.MaxLifetime,
+			"SESSION_REMEMBER_ME_DURATION must not exceed SESSION_MAX_LIFETIME")
+	}
+
+	check(c.Session.CleanupInterval > 0, "SESSION_CLEANUP_INTERVAL must be positive, got %d", c.Session.CleanupInterval)
+	check(c.Session.MaxTotalPerUser >= 0, "SESSION_MAX_TOTAL_PER_USER must not be negative, got %d", c.Session.MaxTotalPerUser)
+	check(c.Session.TotalLimitPolicy == "evict" || c.Session.TotalLimitPolicy == "reject",
+		"SESSION_TOTAL_LIMIT_POLICY must be evict or reject, got %q", c.Session.TotalLimitPolicy)
+	check(c.Session.OnUserDelete == "revoke" || c.Session.OnUserDelete == "expire",
+		"SESSION_ON_USER_DELETE must be revoke or expire, got %q", c.Session.OnUserDelete)
+	check(c.Session.CleanupBatchSize > 0, "SESSION_CLEANUP_BATCH_SIZE must be positive, got %d", c.Session.CleanupBatchSize)
+	if c.Session.GeoIPDatabasePath != "" {
+		check(c.Cache.GeoTTL > 0, "GEO_CACHE_TTL must be positive, got %d", c.Cache.GeoTTL)
+		check(c.Cache.GeoMaxEntries > 0, "GEO_CACHE_MAX_ENTRIES must be positive, got %d", c.Cache.GeoMaxEntries)
+	}
+
+	if _, err := c.Server.RouteRateLimitOverrides(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := c.Session.ScopeDefinitions(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := c.TLS.ServerConfig(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func isAbsoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -3,24 +3,37 @@ package container
 import (
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	firebase "firebase.google.com/go"
 	"firebase.google.com/go/auth"
+	"google.golang.org/api/iterator"
 
+	"github.com/histopathai/auth-service/internal/api/http/handler"
 	"github.com/histopathai/auth-service/internal/api/http/router"
+	"github.com/histopathai/auth-service/internal/domain/model"
 	"github.com/histopathai/auth-service/internal/domain/repository"
 	firebaseAuth "github.com/histopathai/auth-service/internal/infrastructure/auth/firebase"
+	"github.com/histopathai/auth-service/internal/infrastructure/email"
+	"github.com/histopathai/auth-service/internal/infrastructure/events"
+	"github.com/histopathai/auth-service/internal/infrastructure/geo"
 	firestoreRepo "github.com/histopathai/auth-service/internal/infrastructure/storage/firestore"
 	memoryRepo "github.com/histopathai/auth-service/internal/infrastructure/storage/memory"
 	"github.com/histopathai/auth-service/internal/service"
 	"github.com/histopathai/auth-service/pkg/config"
+	"github.com/histopathai/auth-service/pkg/featureflag"
 	"github.com/histopathai/auth-service/pkg/logger"
 )
 
 type Container struct {
 	Config *config.Config
 	Logger *logger.Logger
+	// StartedAt is when initialization began, reported as the service uptime
+	StartedAt time.Time
 
 	//Infrastructure
 	FirebaseApp     *firebase.App
@@ -31,10 +44,31 @@ type Container struct {
 	AuthRepository    repository.AuthRepository
 	UserRepository    repository.UserRepository
 	SessionRepository repository.SessionRepository
+	AuditRepository   repository.AuditRepository
+	EmailSender       repository.EmailSender
+	EventPublisher    repository.EventPublisher
+	ActivityPublisher repository.EventPublisher
+	UserInvalidation  repository.UserInvalidationBroadcaster
+	RateLimitStore    repository.RateLimitStore
+	// GeoResolver locates sessions by IP address; nil when not configured
+	GeoResolver repository.GeoResolver
+	// SessionLocator caches GeoResolver lookups for session listings and
+	// sign-in emails; nil when GeoResolver is
+	SessionLocator *service.SessionLocator
+
+	FeatureFlags *featureflag.Evaluator
+
+	stopInvalidationListener context.CancelFunc
+	// sessionScopes is the session scope table, built before the session
+	// repository since it sizes the repository's per-user cap
+	sessionScopes map[string]service.ScopeConfig
 
 	//Services
 	AuthService    *service.AuthService
-	SessionService *service.SessionService
+	SessionService service.SessionService
+
+	//Background jobs
+	PendingApprovalReconciler *service.PendingApprovalReconciler
 
 	//Router
 	Router *router.Router
@@ -42,11 +76,12 @@ type Container struct {
 
 func New(ctx context.Context, cfg *config.Config, logger *logger.Logger) (*Container, error) {
 	c := &Container{
-		Config: cfg,
-		Logger: logger,
+		Config:    cfg,
+		Logger:    logger,
+		StartedAt: time.Now(),
 	}
 
-	if err := c.initInfrastructure(ctx); err != nil {
+	if err := c.waitForInfrastructure(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize infrastructure: %w", err)
 	}
 
@@ -65,50 +100,234 @@ func New(ctx context.Context, cfg *config.Config, logger *logger.Logger) (*Conta
 }
 
 func (c *Container) initInfrastructure(ctx context.Context) error {
+	// Clients may keep the context they are built with for token refreshes,
+	// so only the reachability probe is bound to the startup deadline
+	clientCtx := context.WithoutCancel(ctx)
 
 	fbConfig := &firebase.Config{
 		ProjectID: c.Config.ProjectID, // Config'den gelen proje ID'sini elle veriyoruz
 	}
 
 	// nil yerine fbConfig değişkenini kullanın
-	fbApp, err := firebase.NewApp(ctx, fbConfig)
+	fbApp, err := firebase.NewApp(clientCtx, fbConfig)
 	if err != nil {
 		return fmt.Errorf("failed to initialize Firebase app: %w", err)
 	}
 	c.FirebaseApp = fbApp
 
-	authClient, err := fbApp.Auth(ctx)
+	authClient, err := fbApp.Auth(clientCtx)
 	if err != nil {
 		return fmt.Errorf("failed to initialize Firebase Auth client: %w", err)
 	}
 	c.AuthClient = authClient
 
-	firestoreClient, err := firestore.NewClient(ctx, c.Config.ProjectID)
+	firestoreClient, err := firestore.NewClient(clientCtx, c.Config.ProjectID)
 	if err != nil {
 		return fmt.Errorf("failed to initialize Firestore client: %w", err)
 	}
 	c.FirestoreClient = firestoreClient
 
+	// Client construction does not dial, so probe Firestore to find out
+	// whether it is actually reachable
+	if err := c.pingFirestore(ctx); err != nil {
+		firestoreClient.Close()
+		c.FirestoreClient = nil
+		return fmt.Errorf("failed to reach Firestore: %w", err)
+	}
+
 	c.Logger.Info("Infrastructure initialized")
 	return nil
 }
 
+// pingFirestore reads at most one user to check that Firestore is reachable
+func (c *Container) pingFirestore(ctx context.Context) error {
+	_, err := c.FirestoreClient.Collection("users").Limit(1).Documents(ctx).Next()
+	if err != nil && err != iterator.Done {
+		return err
+	}
+	return nil
+}
+
+// maxStartupRetryInterval caps the backoff between infrastructure attempts
+const maxStartupRetryInterval = 30 * time.Second
+
+// waitForInfrastructure retries initInfrastructure with exponential backoff
+// until it succeeds or the configured startup wait elapses, so transient
+// dependency outages during a deploy do not crash-loop the service
+func (c *Container) waitForInfrastructure(ctx context.Context) error {
+	timeout := time.Duration(c.Config.Server.StartupWaitTimeout) * time.Second
+	if timeout <= 0 {
+		return c.initInfrastructure(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := time.Duration(c.Config.Server.StartupRetryInterval) * time.Second
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.initInfrastructure(ctx)
+		if err == nil {
+			return nil
+		}
+
+		c.Logger.Warn("Dependencies not reachable, retrying",
+			"attempt", attempt,
+			"retry_in", delay.String(),
+			"error", err,
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("dependencies not reachable within %s: %w", timeout, err)
+		}
+
+		delay *= 2
+		if delay > maxStartupRetryInterval {
+			delay = maxStartupRetryInterval
+		}
+	}
+}
+
 func (c *Container) initRepositories(ctx context.Context) error {
 
 	c.AuthRepository = firebaseAuth.NewFirebaseAuthRepository(c.AuthClient)
 	c.UserRepository = firestoreRepo.NewFirestoreUserRepository(c.FirestoreClient, "users")
+	c.AuditRepository = firestoreRepo.NewFirestoreAuditRepository(c.FirestoreClient, "audit_logs")
+	c.UserInvalidation = firestoreRepo.NewFirestoreUserInvalidation(c.FirestoreClient, "user_invalidations")
+	c.RateLimitStore = firestoreRepo.NewFirestoreRateLimitStore(c.FirestoreClient, "rate_limits")
+
+	scopes, err := c.scopeConfigs()
+	if err != nil {
+		return err
+	}
+	c.sessionScopes = scopes
+	c.SessionRepository = memoryRepo.NewInMemorySessionRepository(
+		sessionRepositoryCap(scopes, c.Config.Session.MaxTotalPerUser),
+		time.Duration(c.Config.Session.CleanupInterval)*time.Second,
+		c.Config.Session.CleanupBatchSize,
+	)
+
+	if c.Config.Email.SMTPHost != "" {
+		c.EmailSender = email.NewSMTPEmailSender(
+			c.Config.Email.SMTPHost,
+			c.Config.Email.SMTPPort,
+			c.Config.Email.SMTPUsername,
+			c.Config.Email.SMTPPassword,
+			c.Config.Email.From,
+		)
+	} else {
+		c.EmailSender = email.NewLogEmailSender(c.Logger.Logger)
+	}
+
+	if c.Config.Events.WebhookURL != "" {
+		c.EventPublisher = events.NewWebhookEventPublisher(c.Config.Events.WebhookURL, c.Config.Events.WebhookSecret)
+	} else {
+		c.EventPublisher = events.NewLogEventPublisher(c.Logger.Logger)
+	}
 
-	c.SessionRepository = memoryRepo.NewInMemorySessionRepository(memoryRepo.DefaultMaxSessionsPerUser)
+	if c.Config.Events.PubSubTopic != "" {
+		// The client refreshes credentials with this context long after startup
+		publisher, err := events.NewPubSubEventPublisher(context.WithoutCancel(ctx), c.Config.ProjectID, c.Config.Events.PubSubTopic)
+		if err != nil {
+			return err
+		}
+		c.ActivityPublisher = events.NewAsyncEventPublisher(publisher, c.Config.Events.PubSubBufferSize, c.Logger.Logger)
+	} else {
+		c.ActivityPublisher = events.NewNopEventPublisher()
+	}
+	if c.Config.Session.GeoIPDatabasePath != "" {
+		resolver, err := geo.NewGeoLiteResolver(c.Config.Session.GeoIPDatabasePath)
+		if err != nil {
+			return err
+		}
+		c.GeoResolver = resolver
+	}
 	c.Logger.Info("Repositories initialized")
 	return nil
 }
 
 func (c *Container) initServices(ctx context.Context) error {
+	flags, err := c.loadFeatureFlags()
+	if err != nil {
+		return err
+	}
+	c.FeatureFlags = flags
 
-	c.AuthService = service.NewAuthService(c.AuthRepository, c.UserRepository)
+	authConfig := service.AuthConfig{
+		RequireDualControlForAdmin:   c.Config.Security.RequireDualControlForAdmin,
+		RequireEmailVerification:     c.Config.Registration.RequireEmailVerification,
+		EmailVerificationGracePeriod: time.Duration(c.Config.Registration.EmailVerificationGraceHours) * time.Hour,
+		AllowedRegistrationDomains:   c.Config.Registration.AllowedDomains,
+		PendingApprovalTTL:           time.Duration(c.Config.Registration.PendingApprovalTTL) * time.Hour,
+		PendingApprovalAction:        service.PendingApprovalAction(c.Config.Registration.PendingApprovalAction),
+		NotifyPendingApprovalExpiry:  c.Config.Registration.PendingApprovalNotify,
+		NotifyRoleChange:             c.Config.Notification.RoleChange,
+		BootstrapAdminEmail:          c.Config.Registration.BootstrapAdminEmail,
+		ActionLinkAllowedOrigins:     c.Config.Email.ActionLinkAllowedOrigins,
+		AccountStatusMessages: map[model.UserStatus]string{
+			model.StatusPending:   c.Config.Security.AccountPendingMessage,
+			model.StatusSuspended: c.Config.Security.AccountSuspendedMessage,
+			model.StatusRejected:  c.Config.Security.AccountRejectedMessage,
+		},
+		UserStatsCacheTTL:    time.Duration(c.Config.Cache.UserStatsTTL) * time.Second,
+		FeatureFlags:         c.FeatureFlags,
+		UserCacheTTL:         time.Duration(c.Config.Cache.UserTTL) * time.Second,
+		UserCacheSize:        c.Config.Cache.UserMaxEntries,
+		BulkWriteConcurrency: c.Config.Firestore.BulkWriteConcurrency,
+		ApprovalRoles:        approvalRoles(c.Config.Security.ApprovalRoles),
+		// Transfers are announced through the webhook and acknowledged on
+		// the internal API
+		OwnershipTransfers: c.Config.Events.WebhookURL != "" && len(c.Config.InternalAPI.AllowedServiceAccounts) > 0,
+	}
+	c.AuthService = service.NewAuthService(c.AuthRepository, c.UserRepository, c.AuditRepository, c.EmailSender, c.EventPublisher, c.ActivityPublisher, c.UserInvalidation, authConfig, c.Logger.Logger)
 
-	c.SessionService = service.NewSessionService(c.SessionRepository, *c.AuthService, c.Logger.Logger)
-	c.Logger.Info("Services initialized")
+	invalidationCtx, cancel := context.WithCancel(context.Background())
+	c.stopInvalidationListener = cancel
+	go c.AuthService.ListenForUserInvalidations(invalidationCtx)
+
+	if authConfig.PendingApprovalTTL > 0 {
+		interval := time.Duration(c.Config.Registration.PendingApprovalCheckInterval) * time.Second
+		c.PendingApprovalReconciler = service.NewPendingApprovalReconciler(c.AuthService, interval, c.Logger.Logger)
+		c.PendingApprovalReconciler.Start()
+	}
+
+	sessionConfig := service.SessionConfig{
+		ExpiryGracePeriod: time.Duration(c.Config.Session.ExpiryGracePeriod) * time.Second,
+		LoginNotification: service.LoginNotificationConfig{
+			Enabled:        c.Config.Notification.NewDeviceLogin,
+			DebounceWindow: time.Duration(c.Config.Notification.NewDeviceDebounce) * time.Second,
+			RevokeURL:      c.Config.Notification.SessionManagementURL,
+		},
+		ScopeConfigs:       c.sessionScopes,
+		TokenScopeClaim:    c.Config.Session.TokenScopeClaim,
+		TokenScopes:        c.Config.Session.TokenScopes,
+		MaxMetadataKeys:    c.Config.Session.MetadataMaxKeys,
+		MaxMetadataBytes:   c.Config.Session.MetadataMaxBytes,
+		RememberMeDuration: time.Duration(c.Config.Session.RememberMeDuration) * time.Second,
+		MaxLifetime:        time.Duration(c.Config.Session.MaxLifetime) * time.Second,
+		MaxSessionsTotal:   c.Config.Session.MaxTotalPerUser,
+		TotalLimitPolicy:   service.SessionLimitPolicy(c.Config.Session.TotalLimitPolicy),
+	}
+	sessionService := service.NewSessionService(c.SessionRepository, c.EmailSender, c.ActivityPublisher, *c.AuthService, sessionConfig, c.Logger.Logger)
+	if c.GeoResolver != nil {
+		c.SessionLocator = service.NewSessionLocator(
+			c.GeoResolver,
+			time.Duration(c.Config.Cache.GeoTTL)*time.Second,
+			c.Config.Cache.GeoMaxEntries,
+			c.Logger.Logger,
+		)
+		sessionService.SetSessionLocator(c.SessionLocator)
+	}
+	c.SessionService = sessionService
+	if c.Config.Session.OnUserDelete == "revoke" {
+		c.AuthService.SetUserSessionPurger(c.SessionService)
+	}
+	c.Logger.Info("Services initialized", "bulk_write_concurrency", c.Config.Firestore.BulkWriteConcurrency)
 	return nil
 }
 
@@ -119,6 +338,12 @@ func (c *Container) initHTTPLayer(ctx context.Context) error {
 		Logger:         c.Logger.Logger,
 		MainServiceURL: c.Config.MainServiceURL,
 		Config:         c.Config,
+		RateLimitStore: c.RateLimitStore,
+		HealthChecks: map[string]handler.DependencyCheck{
+			"firestore": c.pingFirestore,
+		},
+		StartedAt:      c.StartedAt,
+		SessionLocator: c.SessionLocator,
 	}
 
 	appRouter, err := router.NewRouter(routerConfig, c.Config)
@@ -134,6 +359,33 @@ func (c *Container) initHTTPLayer(ctx context.Context) error {
 func (c *Container) Close() error {
 	c.Logger.Info("Closing Container resources")
 
+	if c.Router != nil {
+		c.Router.Close()
+	}
+
+	if c.PendingApprovalReconciler != nil {
+		c.PendingApprovalReconciler.Close()
+	}
+
+	c.FeatureFlags.Close()
+
+	if c.stopInvalidationListener != nil {
+		c.stopInvalidationListener()
+	}
+
+	// Flush queued activity events before the process exits
+	if closer, ok := c.ActivityPublisher.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close activity publisher: %w", err)
+		}
+	}
+
+	if closer, ok := c.SessionRepository.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close session repository: %w", err)
+		}
+	}
+
 	if err := c.FirestoreClient.Close(); err != nil {
 		return fmt.Errorf("failed to close Firestore client: %w", err)
 	}
@@ -141,3 +393,135 @@ func (c *Container) Close() error {
 	c.Logger.Info("Container resources closed successfully")
 	return nil
 }
+
+// sessionRepositoryCap returns the session repository's per-user cap. The
+// repository evicts silently once a user reaches it, so it is set above
+// anything the session service allows: the sum of the scope limits, or the
+// total limit when that is higher. The service enforces both limits itself.
+func sessionRepositoryCap(scopes map[string]service.ScopeConfig, maxTotal int) int {
+	perScope := 0
+	for _, scopeCfg := range scopes {
+		perScope += scopeCfg.MaxSessionsPerUser
+	}
+	return max(perScope, maxTotal)
+}
+
+// scopeConfigs returns the built-in session scopes plus those defined in
+// config, with the configured role restrictions and extension settings
+// applied. Overrides for unknown scopes are ignored.
+func (c *Container) scopeConfigs() (map[string]service.ScopeConfig, error) {
+	scopes := service.DefaultScopeConfigs()
+
+	definitions, err := c.Config.Session.ScopeDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range definitions {
+		if _, ok := scopes[def.Name]; ok {
+			return nil, fmt.Errorf("session scope %q is built in and cannot be redefined", def.Name)
+		}
+
+		scopeCfg := service.ScopeConfig{
+			Expiration:         time.Duration(def.Expiration) * time.Second,
+			MaxSessionsPerUser: def.MaxPerUser,
+			IdleTimeout:        time.Duration(def.IdleTimeout) * time.Second,
+		}
+		for _, role := range def.AllowedRoles {
+			if !model.UserRole(role).IsValid() {
+				return nil, fmt.Errorf("session scope %q allows unknown role %q", def.Name, role)
+			}
+			scopeCfg.AllowedRoles = append(scopeCfg.AllowedRoles, model.UserRole(role))
+		}
+		scopes[def.Name] = scopeCfg
+	}
+
+	for scope, roles := range c.Config.Session.ScopeRoles {
+		scopeCfg, ok := scopes[scope]
+		if !ok {
+			c.Logger.Warn("Ignoring role restriction for unknown session scope", "scope", scope)
+			continue
+		}
+
+		scopeCfg.AllowedRoles = make([]model.UserRole, 0, len(roles))
+		for _, role := range roles {
+			if !model.UserRole(role).IsValid() {
+				return nil, fmt.Errorf("session scope %q allows unknown role %q", scope, role)
+			}
+			scopeCfg.AllowedRoles = append(scopeCfg.AllowedRoles, model.UserRole(role))
+		}
+		scopes[scope] = scopeCfg
+	}
+
+	for scope, values := range c.Config.Session.ScopeExtension {
+		scopeCfg, ok := scopes[scope]
+		if !ok {
+			c.Logger.Warn("Ignoring extension settings for unknown session scope", "scope", scope)
+			continue
+		}
+
+		if len(values) > 0 {
+			threshold, err := strconv.ParseFloat(values[0], 64)
+			if err != nil || threshold <= 0 || threshold > 1 {
+				c.Logger.Warn("Ignoring invalid session extension threshold", "scope", scope, "value", values[0])
+			} else {
+				scopeCfg.ExtendThreshold = threshold
+			}
+		}
+		if len(values) > 1 {
+			seconds, err := strconv.Atoi(values[1])
+			if err != nil || seconds < 0 {
+				c.Logger.Warn("Ignoring invalid session extension interval", "scope", scope, "value", values[1])
+			} else {
+				scopeCfg.ExtendInterval = time.Duration(seconds) * time.Second
+			}
+		}
+		scopes[scope] = scopeCfg
+	}
+
+	names := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		names = append(names, scope)
+	}
+	sort.Strings(names)
+	for _, scope := range names {
+		scopeCfg := scopes[scope]
+		c.Logger.Info("Session scope configured",
+			"scope", scope,
+			"expiration", scopeCfg.Expiration,
+			"max_per_user", scopeCfg.MaxSessionsPerUser,
+			"idle_timeout", scopeCfg.IdleTimeout,
+			"allowed_roles", scopeCfg.AllowedRoles,
+		)
+	}
+	return scopes, nil
+}
+
+// loadFeatureFlags builds the feature flag evaluator from a file when one is
+// configured, otherwise from inline JSON
+func (c *Container) loadFeatureFlags() (*featureflag.Evaluator, error) {
+	cfg := c.Config.FeatureFlags
+	if cfg.File != "" {
+		interval := time.Duration(cfg.ReloadInterval) * time.Second
+		flags, err := featureflag.NewFromFile(cfg.File, interval, c.Logger.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load feature flags: %w", err)
+		}
+		c.Logger.Info("Feature flags loaded from file", "path", cfg.File)
+		return flags, nil
+	}
+
+	rules, err := featureflag.Parse([]byte(cfg.Flags))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+	return featureflag.New(rules), nil
+}
+
+// approvalRoles converts the configured approval role names
+func approvalRoles(names []string) []model.UserRole {
+	roles := make([]model.UserRole, len(names))
+	for i, name := range names {
+		roles[i] = model.UserRole(name)
+	}
+	return roles
+}
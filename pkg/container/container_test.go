@@ -0,0 +1,118 @@
+package container
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/histopathai/auth-service/internal/domain/model"
+	"github.com/histopathai/auth-service/internal/infrastructure/events"
+	memoryRepo "github.com/histopathai/auth-service/internal/infrastructure/storage/memory"
+	"github.com/histopathai/auth-service/internal/service"
+	"github.com/histopathai/auth-service/internal/shared/errors"
+	"github.com/histopathai/auth-service/pkg/config"
+	"github.com/histopathai/auth-service/pkg/logger"
+)
+
+func newTestContainer(session config.SessionConfig) *Container {
+	return &Container{
+		Config: &config.Config{Session: session},
+		Logger: &logger.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+	}
+}
+
+// newTestSessionService wires a session service over an in-memory
+// repository sized the way initRepositories sizes it
+func newTestSessionService(t *testing.T, c *Container, policy service.SessionLimitPolicy) *service.SessionServiceImpl {
+	t.Helper()
+	scopes, err := c.scopeConfigs()
+	if err != nil {
+		t.Fatalf("scopeConfigs() error = %v", err)
+	}
+	repo := memoryRepo.NewInMemorySessionRepository(sessionRepositoryCap(scopes, c.Config.Session.MaxTotalPerUser), time.Hour, 0)
+	t.Cleanup(func() { repo.Close() })
+
+	sessions := service.NewSessionService(repo, nil, events.NewNopEventPublisher(), service.AuthService{}, service.SessionConfig{
+		ScopeConfigs:     scopes,
+		MaxSessionsTotal: c.Config.Session.MaxTotalPerUser,
+		TotalLimitPolicy: policy,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return sessions
+}
+
+func TestSessionRepositoryCapCountsConfiguredScopes(t *testing.T) {
+	c := newTestContainer(config.SessionConfig{Scopes: map[string][]string{"viewer-app": {"3600", "4", "0"}}})
+	sessions := newTestSessionService(t, c, service.SessionLimitEvict)
+	ctx := context.Background()
+	user := &model.User{UserID: "admin-1", Role: model.RoleAdmin, Status: model.StatusActive}
+
+	// Fill every scope to its own limit: 3 + 5 + 1 + 4 sessions
+	perScope := map[string]int{service.ScopeDefault: 3, service.ScopeImageServe: 5, service.ScopeAdminOps: 1, "viewer-app": 4}
+	var created []string
+	for scope, count := range perScope {
+		for i := 0; i < count; i++ {
+			session, err := sessions.CreateSession(ctx, user, service.CreateSessionOptions{Scope: scope})
+			if err != nil {
+				t.Fatalf("CreateSession(%s) error = %v", scope, err)
+			}
+			created = append(created, session.SessionID)
+		}
+	}
+
+	for _, sessionID := range created {
+		if _, err := sessions.ValidateSession(ctx, sessionID); err != nil {
+			t.Errorf("session %s was evicted below its scope limit: %v", sessionID, err)
+		}
+	}
+}
+
+func TestTotalSessionLimitAboveBuiltInScopes(t *testing.T) {
+	c := newTestContainer(config.SessionConfig{
+		Scopes:          map[string][]string{"viewer-app": {"3600", "6", "0"}},
+		MaxTotalPerUser: 12,
+	})
+	sessions := newTestSessionService(t, c, service.SessionLimitReject)
+	ctx := context.Background()
+	user := &model.User{UserID: "user-1", Role: model.RoleUser, Status: model.StatusActive}
+
+	perScope := map[string]int{service.ScopeDefault: 3, service.ScopeImageServe: 5, "viewer-app": 4}
+	var created []string
+	for scope, count := range perScope {
+		for i := 0; i < count; i++ {
+			session, err := sessions.CreateSession(ctx, user, service.CreateSessionOptions{Scope: scope})
+			if err != nil {
+				t.Fatalf("CreateSession(%s) error = %v", scope, err)
+			}
+			created = append(created, session.SessionID)
+		}
+	}
+
+	if _, err := sessions.CreateSession(ctx, user, service.CreateSessionOptions{Scope: "viewer-app"}); !errors.IsType(err, errors.ErrorTypeConflict) {
+		t.Fatalf("CreateSession() beyond the total limit error = %v, want a conflict", err)
+	}
+	for _, sessionID := range created {
+		if _, err := sessions.ValidateSession(ctx, sessionID); err != nil {
+			t.Errorf("session %s was evicted under the reject policy: %v", sessionID, err)
+		}
+	}
+}
+
+func TestScopeConfigsRejectUnknownRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		session config.SessionConfig
+	}{
+		{name: "SESSION_SCOPES", session: config.SessionConfig{Scopes: map[string][]string{"viewer-app": {"3600", "4", "0", "admim"}}}},
+		{name: "SESSION_SCOPE_ROLES", session: config.SessionConfig{ScopeRoles: map[string][]string{service.ScopeImageServe: {"user", "admim"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTestContainer(tt.session).scopeConfigs(); err == nil {
+				t.Error("scopeConfigs() accepted the unknown role admim")
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package featureflag evaluates config-driven feature flags used to roll out
+// auth behaviors gradually.
+//
+// Flags are defined as a JSON object keyed by flag name:
+//
+//	{
+//	  "require_email_verification": {"percentage": 25},
+//	  "auto_approve_registration": {"users": ["uid-1", "uid-2"]}
+//	}
+//
+// A flag is on for a user when the user is listed in users, when enabled is
+// true, or when the user falls into the rollout percentage. Percentage
+// buckets are derived from a hash of the flag name and user ID, so a user
+// stays in the same bucket as the percentage grows. Callers supply a
+// fallback that applies to flags that are not defined.
+package featureflag
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Rule decides for whom a flag is on
+type Rule struct {
+	Enabled    bool     `json:"enabled"`
+	Percentage int      `json:"percentage"`
+	Users      []string `json:"users,omitempty"`
+}
+
+// Parse decodes flag rules from JSON. Percentages must be within 0-100.
+func Parse(data []byte) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	if len(data) == 0 {
+		return rules, nil
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid feature flags: %w", err)
+	}
+	for name, rule := range rules {
+		if rule.Percentage < 0 || rule.Percentage > 100 {
+			return nil, fmt.Errorf("invalid feature flag %q: percentage must be between 0 and 100", name)
+		}
+	}
+	return rules, nil
+}
+
+// Evaluator answers flag queries. When created from a file it reloads the
+// file whenever it changes. A nil Evaluator reports every flag as its fallback.
+type Evaluator struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+
+	path    string
+	modTime time.Time
+	logger  *slog.Logger
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// New returns an Evaluator with fixed rules
+func New(rules map[string]Rule) *Evaluator {
+	return &Evaluator{
+		rules: rules,
+		stop:  make(chan struct{}),
+	}
+}
+
+// NewFromFile loads rules from a JSON file and checks it for changes every
+// interval. A reload that fails keeps the previous rules.
+func NewFromFile(path string, interval time.Duration, logger *slog.Logger) (*Evaluator, error) {
+	e := &Evaluator{
+		path:   path,
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+	if _, err := e.reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go e.watch(interval)
+	}
+	return e, nil
+}
+
+// Enabled reports whether the flag is on for userID
+func (e *Evaluator) Enabled(name string, userID string, fallback bool) bool {
+	if e == nil {
+		return fallback
+	}
+
+	e.mu.RLock()
+	rule, ok := e.rules[name]
+	e.mu.RUnlock()
+	if !ok {
+		return fallback
+	}
+
+	if rule.Enabled {
+		return true
+	}
+	for _, user := range rule.Users {
+		if user == userID {
+			return true
+		}
+	}
+	if rule.Percentage > 0 && userID != "" {
+		return bucket(name, userID) < rule.Percentage
+	}
+	return false
+}
+
+// Rules returns a copy of the current rules
+func (e *Evaluator) Rules() map[string]Rule {
+	if e == nil {
+		return map[string]Rule{}
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rules := make(map[string]Rule, len(e.rules))
+	for name, rule := range e.rules {
+		rules[name] = rule
+	}
+	return rules
+}
+
+// Close stops watching the flags file
+func (e *Evaluator) Close() {
+	if e == nil {
+		return
+	}
+	e.closeOnce.Do(func() {
+		close(e.stop)
+	})
+}
+
+func (e *Evaluator) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := e.reload()
+			if err != nil {
+				e.logger.Error("Failed to reload feature flags", "path", e.path, "error", err)
+				continue
+			}
+			if reloaded {
+				e.logger.Info("Feature flags reloaded", "path", e.path)
+			}
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// reload reads the flags file if it changed since the last load
+func (e *Evaluator) reload() (bool, error) {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat feature flags file: %w", err)
+	}
+	if info.ModTime().Equal(e.modTime) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read feature flags file: %w", err)
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.mu.Unlock()
+	e.modTime = info.ModTime()
+	return true, nil
+}
+
+// bucket maps a user to a stable value in [0, 100) for a flag
+func bucket(name string, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+	return int(h.Sum32() % 100)
+}
@@ -28,6 +28,11 @@ func New(cfg *config.LoggingConfig) *Logger {
 
 	logger := slog.New(handler)
 
+	// Route the slog default and the standard library log package, used by
+	// dependencies, through the configured handler so every line shares the
+	// same format
+	slog.SetDefault(logger)
+
 	return &Logger{
 		Logger: logger,
 	}
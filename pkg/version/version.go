@@ -0,0 +1,31 @@
+// Package version holds build information injected at link time:
+//
+//	go build -ldflags "-X github.com/histopathai/auth-service/pkg/version.Version=v1.2.3 \
+//	  -X github.com/histopathai/auth-service/pkg/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/histopathai/auth-service/pkg/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+var (
+	Version   = "dev"
+	Commit    = "dev"
+	BuildTime = "unknown"
+)
+
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}